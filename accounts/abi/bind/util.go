@@ -26,6 +26,17 @@ import (
 	"github.com/ethereum/go-ethereum/log"
 )
 
+// RevertError is returned by WaitMined along with the receipt if the mined
+// transaction failed and the node recorded why it was reverted.
+type RevertError struct {
+	TxHash common.Hash
+	Reason string
+}
+
+func (e *RevertError) Error() string {
+	return fmt.Sprintf("transaction %x reverted: %s", e.TxHash, e.Reason)
+}
+
 // WaitMined waits for tx to be mined on the blockchain.
 // It stops waiting when the context is canceled.
 func WaitMined(ctx context.Context, b DeployBackend, tx *types.Transaction) (*types.Receipt, error) {
@@ -36,6 +47,9 @@ func WaitMined(ctx context.Context, b DeployBackend, tx *types.Transaction) (*ty
 	for {
 		receipt, err := b.TransactionReceipt(ctx, tx.Hash())
 		if receipt != nil {
+			if receipt.Status == types.ReceiptStatusFailed && receipt.RevertReason != "" {
+				return receipt, &RevertError{TxHash: tx.Hash(), Reason: receipt.RevertReason}
+			}
 			return receipt, nil
 		}
 		if err != nil {
@@ -50,10 +50,10 @@ import (
 )
 
 // ExternalAPIVersion -- see extapi_changelog.md
-const ExternalAPIVersion = "4.0.0"
+const ExternalAPIVersion = "4.1.0"
 
 // InternalAPIVersion -- see intapi_changelog.md
-const InternalAPIVersion = "3.0.0"
+const InternalAPIVersion = "3.1.0"
 
 const legalWarning = `
 WARNING! 
@@ -441,7 +441,7 @@ func signer(c *cli.Context) error {
 
 		// start http server
 		httpEndpoint := fmt.Sprintf("%s:%d", c.GlobalString(utils.RPCListenAddrFlag.Name), c.Int(rpcPortFlag.Name))
-		listener, _, err := rpc.StartHTTPEndpoint(httpEndpoint, rpcAPI, []string{"account"}, cors, vhosts, rpc.DefaultHTTPTimeouts)
+		listener, _, err := rpc.StartHTTPEndpoint(httpEndpoint, rpcAPI, []string{"account"}, cors, vhosts, rpc.DefaultHTTPTimeouts, rpc.EndpointOptions{})
 		if err != nil {
 			utils.Fatalf("Could not start RPC api: %v", err)
 		}
@@ -28,6 +28,7 @@ import (
 
 	"github.com/ethereum/go-ethereum/cmd/utils"
 	"github.com/ethereum/go-ethereum/common"
+	istanbulBackend "github.com/ethereum/go-ethereum/consensus/istanbul/backend"
 	"github.com/ethereum/go-ethereum/console"
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/state"
@@ -141,6 +142,20 @@ The export-preimages command export hash preimages to an RLP encoded stream`,
 		Category: "BLOCKCHAIN COMMANDS",
 		Description: `
 The first argument must be the directory containing the blockchain to download from`,
+	}
+	pruneIstanbulCommand = cli.Command{
+		Action:    utils.MigrateFlags(pruneIstanbul),
+		Name:      "pruneistanbul",
+		Usage:     "Prune old Istanbul voting snapshots from the database",
+		ArgsUsage: "[<checkpoints to keep>]",
+		Flags: []cli.Flag{
+			utils.DataDirFlag,
+		},
+		Category: "BLOCKCHAIN COMMANDS",
+		Description: `
+The pruneistanbul command deletes all Istanbul voting snapshots apart from the
+genesis one and the most recent checkpoints (2 by default). Validators at older
+heights remain available through istanbul_getValidatorHistory.`,
 	}
 	removedbCommand = cli.Command{
 		Action:    utils.MigrateFlags(removeDB),
@@ -385,6 +400,28 @@ func exportPreimages(ctx *cli.Context) error {
 	return nil
 }
 
+// pruneIstanbul deletes old Istanbul voting snapshots from the chain database.
+func pruneIstanbul(ctx *cli.Context) error {
+	keep := uint64(2)
+	if len(ctx.Args()) > 0 {
+		var err error
+		if keep, err = strconv.ParseUint(ctx.Args().First(), 10, 64); err != nil {
+			utils.Fatalf("Invalid number of checkpoints to keep: %v", err)
+		}
+	}
+	stack := makeFullNode(ctx)
+	diskdb := utils.MakeChainDatabase(ctx, stack).(*ethdb.LDBDatabase)
+	defer diskdb.Close()
+
+	start := time.Now()
+	pruned, err := istanbulBackend.PruneSnapshots(diskdb, keep)
+	if err != nil {
+		utils.Fatalf("Prune error: %v\n", err)
+	}
+	fmt.Printf("Pruned %d snapshots in %v\n", pruned, time.Since(start))
+	return nil
+}
+
 func copyDb(ctx *cli.Context) error {
 	// Ensure we have a source chain directory to copy
 	if len(ctx.Args()) != 1 {
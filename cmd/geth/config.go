@@ -183,6 +183,14 @@ func makeFullNode(ctx *cli.Context) *node.Node {
 		utils.RegisterShhService(stack, &cfg.Shh)
 	}
 
+	// Add the GraphQL service if requested, it is served by the HTTP-RPC server.
+	if ctx.GlobalBool(utils.GraphQLEnabledFlag.Name) {
+		if cfg.Node.HTTPHost == "" {
+			utils.Fatalf("GraphQL requires the HTTP-RPC server, enable it with --%s", utils.RPCEnabledFlag.Name)
+		}
+		utils.RegisterGraphQLService(stack, ctx.GlobalBool(utils.GraphQLPrivateFlag.Name))
+	}
+
 	// Add the Ethereum Stats daemon if requested.
 	if cfg.Ethstats.URL != "" {
 		utils.RegisterEthStatsService(stack, cfg.Ethstats.URL)
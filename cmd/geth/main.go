@@ -82,6 +82,11 @@ var (
 		utils.TxPoolAccountQueueFlag,
 		utils.TxPoolGlobalQueueFlag,
 		utils.TxPoolLifetimeFlag,
+		utils.TxPoolCheckPrivateFlag,
+		utils.TxPoolAllowedSendersFlag,
+		utils.TxPoolAccountRateFlag,
+		utils.TxPoolAccountGasFlag,
+		utils.TxPoolPeerRateFlag,
 		utils.SyncModeFlag,
 		utils.GCModeFlag,
 		utils.LightServFlag,
@@ -121,6 +126,7 @@ var (
 		utils.RinkebyFlag,
 		utils.OttomanFlag,
 		utils.VMEnableDebugFlag,
+		utils.VMSaveRevertReasonFlag,
 		utils.NetworkIdFlag,
 		utils.RPCCORSDomainFlag,
 		utils.RPCVirtualHostsFlag,
@@ -134,6 +140,13 @@ var (
 		utils.EVMInterpreterFlag,
 		configFileFlag,
 		utils.EnableNodePermissionFlag,
+		utils.PermissionAuditDirFlag,
+		utils.PrivateStateChecksumsFlag,
+		utils.PrivateSimulationFlag,
+		utils.PrivateSimulationTimeoutFlag,
+		utils.PrivateTxManagerCheckIntervalFlag,
+		utils.PrivateTxManagerPauseImportFlag,
+		utils.PrivateTxManagerRejectPrivateFlag,
 		utils.RaftModeFlag,
 		utils.RaftBlockTimeFlag,
 		utils.RaftJoinExistingFlag,
@@ -141,6 +154,10 @@ var (
 		utils.EmitCheckpointsFlag,
 		utils.IstanbulRequestTimeoutFlag,
 		utils.IstanbulBlockPeriodFlag,
+		utils.IstanbulValidatorFlag,
+		utils.IstanbulSignerFlag,
+		utils.IstanbulSignerRateLimitFlag,
+		utils.IstanbulValidatorSetSyncFlag,
 	}
 
 	rpcFlags = []cli.Flag{
@@ -148,11 +165,28 @@ var (
 		utils.RPCListenAddrFlag,
 		utils.RPCPortFlag,
 		utils.RPCApiFlag,
+		utils.RPCTLSCertFlag,
+		utils.RPCTLSKeyFlag,
+		utils.RPCTLSCAFlag,
+		utils.RPCBatchLimitFlag,
+		utils.RPCResponseLimitFlag,
+		utils.RPCConcurrencyFlag,
+		utils.RPCRateLimitFlag,
+		utils.RPCRateBurstFlag,
+		utils.RPCSubscriptionBufferFlag,
+		utils.RPCLogsBlockRangeFlag,
+		utils.RPCAccessLogFlag,
+		utils.HealthMinPeersFlag,
+		utils.HealthMaxBlocksBehindFlag,
+		utils.HealthParticipationBlocksFlag,
+		utils.HealthMinParticipationFlag,
 		utils.WSEnabledFlag,
 		utils.WSListenAddrFlag,
 		utils.WSPortFlag,
 		utils.WSApiFlag,
 		utils.WSAllowedOriginsFlag,
+		utils.GraphQLEnabledFlag,
+		utils.GraphQLPrivateFlag,
 		utils.IPCDisabledFlag,
 		utils.IPCPathFlag,
 	}
@@ -188,6 +222,7 @@ func init() {
 		exportPreimagesCommand,
 		copydbCommand,
 		removedbCommand,
+		pruneIstanbulCommand,
 		dumpCommand,
 		// See monitorcmd.go:
 		monitorCommand,
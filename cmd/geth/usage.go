@@ -126,6 +126,11 @@ var AppHelpFlagGroups = []flagGroup{
 			utils.TxPoolAccountQueueFlag,
 			utils.TxPoolGlobalQueueFlag,
 			utils.TxPoolLifetimeFlag,
+			utils.TxPoolCheckPrivateFlag,
+			utils.TxPoolAllowedSendersFlag,
+			utils.TxPoolAccountRateFlag,
+			utils.TxPoolAccountGasFlag,
+			utils.TxPoolPeerRateFlag,
 		},
 	},
 	{
@@ -152,6 +157,13 @@ var AppHelpFlagGroups = []flagGroup{
 		Name: "QUORUM",
 		Flags: []cli.Flag{
 			utils.EnableNodePermissionFlag,
+			utils.PermissionAuditDirFlag,
+			utils.PrivateStateChecksumsFlag,
+			utils.PrivateSimulationFlag,
+			utils.PrivateSimulationTimeoutFlag,
+			utils.PrivateTxManagerCheckIntervalFlag,
+			utils.PrivateTxManagerPauseImportFlag,
+			utils.PrivateTxManagerRejectPrivateFlag,
 		},
 	},
 	{
@@ -182,10 +194,27 @@ var AppHelpFlagGroups = []flagGroup{
 			utils.WSPortFlag,
 			utils.WSApiFlag,
 			utils.WSAllowedOriginsFlag,
+			utils.GraphQLEnabledFlag,
+			utils.GraphQLPrivateFlag,
 			utils.IPCDisabledFlag,
 			utils.IPCPathFlag,
 			utils.RPCCORSDomainFlag,
 			utils.RPCVirtualHostsFlag,
+			utils.RPCTLSCertFlag,
+			utils.RPCTLSKeyFlag,
+			utils.RPCTLSCAFlag,
+			utils.RPCBatchLimitFlag,
+			utils.RPCResponseLimitFlag,
+			utils.RPCConcurrencyFlag,
+			utils.RPCRateLimitFlag,
+			utils.RPCRateBurstFlag,
+			utils.RPCSubscriptionBufferFlag,
+			utils.RPCLogsBlockRangeFlag,
+			utils.RPCAccessLogFlag,
+			utils.HealthMinPeersFlag,
+			utils.HealthMaxBlocksBehindFlag,
+			utils.HealthParticipationBlocksFlag,
+			utils.HealthMinParticipationFlag,
 			utils.JSpathFlag,
 			utils.ExecFlag,
 			utils.PreloadJSFlag,
@@ -234,6 +263,7 @@ var AppHelpFlagGroups = []flagGroup{
 		Name: "VIRTUAL MACHINE",
 		Flags: []cli.Flag{
 			utils.VMEnableDebugFlag,
+			utils.VMSaveRevertReasonFlag,
 			utils.EVMInterpreterFlag,
 			utils.EWASMInterpreterFlag,
 		},
@@ -278,6 +308,10 @@ var AppHelpFlagGroups = []flagGroup{
 		Flags: []cli.Flag{
 			utils.IstanbulRequestTimeoutFlag,
 			utils.IstanbulBlockPeriodFlag,
+			utils.IstanbulValidatorFlag,
+			utils.IstanbulSignerFlag,
+			utils.IstanbulSignerRateLimitFlag,
+			utils.IstanbulValidatorSetSyncFlag,
 		},
 	},
 }
@@ -44,6 +44,7 @@ import (
 	"github.com/ethereum/go-ethereum/eth/gasprice"
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/ethstats"
+	"github.com/ethereum/go-ethereum/graphql"
 	"github.com/ethereum/go-ethereum/les"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/metrics"
@@ -290,6 +291,26 @@ var (
 		Usage: "Maximum amount of time non-executable transaction are queued",
 		Value: eth.DefaultConfig.TxPool.Lifetime,
 	}
+	TxPoolCheckPrivateFlag = cli.BoolFlag{
+		Name:  "txpool.checkprivate",
+		Usage: "Reject local private transactions whose payload isn't held by the private transaction manager",
+	}
+	TxPoolAllowedSendersFlag = cli.StringFlag{
+		Name:  "txpool.allowedsenders",
+		Usage: "Comma separated accounts to exclusively accept transactions from",
+	}
+	TxPoolAccountRateFlag = cli.Uint64Flag{
+		Name:  "txpool.accountrate",
+		Usage: "Maximum number of remote transactions accepted per account and minute (0 = unlimited)",
+	}
+	TxPoolAccountGasFlag = cli.Uint64Flag{
+		Name:  "txpool.accountgas",
+		Usage: "Maximum gas of remote transactions accepted per account and minute (0 = unlimited)",
+	}
+	TxPoolPeerRateFlag = cli.Uint64Flag{
+		Name:  "txpool.peerrate",
+		Usage: "Maximum number of transactions accepted per peer and minute (0 = unlimited)",
+	}
 	// Performance tuning settings
 	CacheFlag = cli.IntFlag{
 		Name:  "cache",
@@ -398,6 +419,10 @@ var (
 		Name:  "vmdebug",
 		Usage: "Record information useful for VM and contract debugging",
 	}
+	VMSaveRevertReasonFlag = cli.BoolFlag{
+		Name:  "revertreason",
+		Usage: "Store the revert reasons of failed transactions in their receipts",
+	}
 	// Logging and debug settings
 	EthStatsURLFlag = cli.StringFlag{
 		Name:  "ethstats",
@@ -436,6 +461,71 @@ var (
 		Usage: "Comma separated list of virtual hostnames from which to accept requests (server enforced). Accepts '*' wildcard.",
 		Value: strings.Join(node.DefaultConfig.HTTPVirtualHosts, ","),
 	}
+	RPCTLSCertFlag = cli.StringFlag{
+		Name:  "rpc.tls.cert",
+		Usage: "PEM certificate to serve the HTTP-RPC and WS-RPC servers over TLS with (reloaded on SIGHUP)",
+	}
+	RPCTLSKeyFlag = cli.StringFlag{
+		Name:  "rpc.tls.key",
+		Usage: "PEM private key of the HTTP-RPC and WS-RPC TLS certificate",
+	}
+	RPCTLSCAFlag = cli.StringFlag{
+		Name:  "rpc.tls.ca",
+		Usage: "PEM CA certificates to require and verify HTTP-RPC and WS-RPC client certificates against",
+	}
+	RPCBatchLimitFlag = cli.IntFlag{
+		Name:  "rpc.batchlimit",
+		Usage: "Maximum number of requests in an HTTP-RPC or WS-RPC batch (0 = unlimited)",
+	}
+	RPCResponseLimitFlag = cli.IntFlag{
+		Name:  "rpc.responselimit",
+		Usage: "Maximum size of an HTTP-RPC or WS-RPC response in bytes (0 = unlimited)",
+	}
+	RPCConcurrencyFlag = cli.StringFlag{
+		Name:  "rpc.concurrency",
+		Usage: "Comma separated maximum numbers of concurrent calls of methods (e.g. eth_getLogs=4,debug_traceBlock=1)",
+	}
+	RPCRateLimitFlag = cli.Float64Flag{
+		Name:  "rpc.ratelimit",
+		Usage: "Maximum HTTP-RPC and WS-RPC requests per second of every client IP or certificate (0 = unlimited)",
+	}
+	RPCRateBurstFlag = cli.IntFlag{
+		Name:  "rpc.rateburst",
+		Usage: "Maximum number of requests a client may burst above the rate limit",
+		Value: 1,
+	}
+	RPCSubscriptionBufferFlag = cli.IntFlag{
+		Name:  "rpc.subscriptionbuffer",
+		Usage: "Maximum number of notifications queued for a subscription before it is dropped as lagged (0 = default)",
+	}
+	RPCLogsBlockRangeFlag = cli.Uint64Flag{
+		Name:  "rpc.logsblockrange",
+		Usage: "Maximum number of blocks an eth_getLogs query or a resumed subscription may span (0 = unlimited)",
+	}
+	RPCAccessLogFlag = DirectoryFlag{
+		Name:  "rpc.accesslog",
+		Usage: "Directory for the access log of HTTP-RPC and WS-RPC requests (relative to the data directory)",
+	}
+	HealthMinPeersFlag = cli.IntFlag{
+		Name:  "health.minpeers",
+		Usage: "Minimum number of peers for the node to report ready on /ready",
+		Value: node.DefaultConfig.Health.MinPeers,
+	}
+	HealthMaxBlocksBehindFlag = cli.Uint64Flag{
+		Name:  "health.maxblocksbehind",
+		Usage: "Maximum number of blocks behind the best known peer for the node to report ready on /ready",
+		Value: node.DefaultConfig.Health.MaxBlocksBehind,
+	}
+	HealthParticipationBlocksFlag = cli.Uint64Flag{
+		Name:  "health.participationblocks",
+		Usage: "Number of recent blocks inspected for Istanbul validator participation",
+		Value: node.DefaultConfig.Health.ParticipationBlocks,
+	}
+	HealthMinParticipationFlag = cli.Float64Flag{
+		Name:  "health.minparticipation",
+		Usage: "Minimum fraction of Istanbul validators committing recent blocks for the node to report ready on /ready",
+		Value: node.DefaultConfig.Health.MinParticipation,
+	}
 	RPCApiFlag = cli.StringFlag{
 		Name:  "rpcapi",
 		Usage: "API's offered over the HTTP-RPC interface",
@@ -473,6 +563,14 @@ var (
 		Usage: "Origins from which to accept websockets requests",
 		Value: "",
 	}
+	GraphQLEnabledFlag = cli.BoolFlag{
+		Name:  "graphql",
+		Usage: "Enable GraphQL on the HTTP-RPC server, at the /graphql path",
+	}
+	GraphQLPrivateFlag = cli.BoolFlag{
+		Name:  "graphql.private",
+		Usage: "Serve the private state and the private transaction payloads of the node over GraphQL",
+	}
 	ExecFlag = cli.StringFlag{
 		Name:  "exec",
 		Usage: "Execute JavaScript statement",
@@ -606,6 +704,36 @@ var (
 		Name:  "permissioned",
 		Usage: "If enabled, the node will allow only a defined list of nodes to connect",
 	}
+	PermissionAuditDirFlag = DirectoryFlag{
+		Name:  "permissioned.auditdir",
+		Usage: "Directory for the audit log of permissioning decisions (relative to the data directory)",
+	}
+	PrivateStateChecksumsFlag = cli.BoolFlag{
+		Name:  "privatestate.checksums",
+		Usage: "Exchange private state checksums with peers to detect private state divergence",
+	}
+	PrivateSimulationFlag = cli.BoolFlag{
+		Name:  "privatestate.simulate",
+		Usage: "Simulate private transactions sent through the node's accounts against the private state before distributing them",
+	}
+	PrivateSimulationTimeoutFlag = cli.DurationFlag{
+		Name:  "privatestate.simulatetimeout",
+		Usage: "Execution time limit of a private transaction simulation",
+		Value: eth.DefaultConfig.PrivateSimulationTimeout,
+	}
+	PrivateTxManagerCheckIntervalFlag = cli.DurationFlag{
+		Name:  "ptm.checkinterval",
+		Usage: "Time interval to check whether the private transaction manager is up (0 = disabled)",
+		Value: eth.DefaultConfig.PrivateTxManagerCheckInterval,
+	}
+	PrivateTxManagerPauseImportFlag = cli.BoolFlag{
+		Name:  "ptm.pauseimport",
+		Usage: "Hold back importing blocks with private transactions while the private transaction manager is down",
+	}
+	PrivateTxManagerRejectPrivateFlag = cli.BoolFlag{
+		Name:  "ptm.rejectprivate",
+		Usage: "Refuse submitting private transactions while the private transaction manager is down",
+	}
 
 	// Istanbul settings
 	IstanbulRequestTimeoutFlag = cli.Uint64Flag{
@@ -618,6 +746,22 @@ var (
 		Usage: "Default minimum difference between two consecutive block's timestamps in seconds",
 		Value: eth.DefaultConfig.Istanbul.BlockPeriod,
 	}
+	IstanbulValidatorFlag = cli.StringFlag{
+		Name:  "istanbul.validator",
+		Usage: "Istanbul validator address signing through an account wallet or external signer instead of the node key",
+	}
+	IstanbulSignerFlag = cli.StringFlag{
+		Name:  "istanbul.signer",
+		Usage: "IPC endpoint of an external signer holding the Istanbul validator key, called as account_signHash(address, hash) returning a 65 byte [R || S || V] signature over the unprefixed 32 byte hash (clef approves it only through an ApproveSignHash rule)",
+	}
+	IstanbulSignerRateLimitFlag = cli.Uint64Flag{
+		Name:  "istanbul.signer.ratelimit",
+		Usage: "Maximum number of consensus signatures per second by the Istanbul validator key (0 = unlimited)",
+	}
+	IstanbulValidatorSetSyncFlag = cli.BoolFlag{
+		Name:  "istanbul.validatorsetsync",
+		Usage: "Verify synced Istanbul headers by validator set transitions and epoch seals instead of replaying votes",
+	}
 
 	// Metrics flags
 	MetricsEnabledFlag = cli.BoolFlag{
@@ -835,6 +979,68 @@ func setHTTP(ctx *cli.Context, cfg *node.Config) {
 	}
 }
 
+// setRPCLimits configures the limits imposed on the requests of HTTP and
+// WebSocket RPC clients.
+func setRPCLimits(ctx *cli.Context, cfg *node.Config) {
+	if ctx.GlobalIsSet(RPCBatchLimitFlag.Name) {
+		cfg.RPCLimits.MaxBatchLength = ctx.GlobalInt(RPCBatchLimitFlag.Name)
+	}
+	if ctx.GlobalIsSet(RPCResponseLimitFlag.Name) {
+		cfg.RPCLimits.MaxResponseSize = ctx.GlobalInt(RPCResponseLimitFlag.Name)
+	}
+	if ctx.GlobalIsSet(RPCConcurrencyFlag.Name) {
+		cfg.RPCLimits.MethodConcurrency = make(map[string]int)
+		for _, entry := range splitAndTrim(ctx.GlobalString(RPCConcurrencyFlag.Name)) {
+			parts := strings.SplitN(entry, "=", 2)
+			if len(parts) != 2 {
+				Fatalf("Invalid entry in --%s: %s", RPCConcurrencyFlag.Name, entry)
+			}
+			limit, err := strconv.Atoi(parts[1])
+			if err != nil || limit < 0 {
+				Fatalf("Invalid concurrency limit in --%s: %s", RPCConcurrencyFlag.Name, entry)
+			}
+			cfg.RPCLimits.MethodConcurrency[parts[0]] = limit
+		}
+	}
+	if ctx.GlobalIsSet(RPCRateLimitFlag.Name) {
+		cfg.RPCLimits.RateLimit = ctx.GlobalFloat64(RPCRateLimitFlag.Name)
+		cfg.RPCLimits.RateBurst = ctx.GlobalInt(RPCRateBurstFlag.Name)
+	}
+	if ctx.GlobalIsSet(RPCSubscriptionBufferFlag.Name) {
+		cfg.RPCLimits.SubscriptionBuffer = ctx.GlobalInt(RPCSubscriptionBufferFlag.Name)
+	}
+}
+
+// setHealth configures the thresholds of the health and readiness checks.
+func setHealth(ctx *cli.Context, cfg *node.Config) {
+	if ctx.GlobalIsSet(HealthMinPeersFlag.Name) {
+		cfg.Health.MinPeers = ctx.GlobalInt(HealthMinPeersFlag.Name)
+	}
+	if ctx.GlobalIsSet(HealthMaxBlocksBehindFlag.Name) {
+		cfg.Health.MaxBlocksBehind = ctx.GlobalUint64(HealthMaxBlocksBehindFlag.Name)
+	}
+	if ctx.GlobalIsSet(HealthParticipationBlocksFlag.Name) {
+		cfg.Health.ParticipationBlocks = ctx.GlobalUint64(HealthParticipationBlocksFlag.Name)
+	}
+	if ctx.GlobalIsSet(HealthMinParticipationFlag.Name) {
+		cfg.Health.MinParticipation = ctx.GlobalFloat64(HealthMinParticipationFlag.Name)
+	}
+}
+
+// setRPCTLS configures the certificates the HTTP and WebSocket RPC servers are
+// served over TLS with.
+func setRPCTLS(ctx *cli.Context, cfg *node.Config) {
+	if ctx.GlobalIsSet(RPCTLSCertFlag.Name) {
+		cfg.RPCTLS.CertFile = ctx.GlobalString(RPCTLSCertFlag.Name)
+	}
+	if ctx.GlobalIsSet(RPCTLSKeyFlag.Name) {
+		cfg.RPCTLS.KeyFile = ctx.GlobalString(RPCTLSKeyFlag.Name)
+	}
+	if ctx.GlobalIsSet(RPCTLSCAFlag.Name) {
+		cfg.RPCTLS.CAFile = ctx.GlobalString(RPCTLSCAFlag.Name)
+	}
+}
+
 // setWS creates the WebSocket RPC listener interface string from the set
 // command line flags, returning empty if the HTTP endpoint is disabled.
 func setWS(ctx *cli.Context, cfg *node.Config) {
@@ -1018,9 +1224,18 @@ func SetNodeConfig(ctx *cli.Context, cfg *node.Config) {
 	setIPC(ctx, cfg)
 	setHTTP(ctx, cfg)
 	setWS(ctx, cfg)
+	setRPCTLS(ctx, cfg)
+	setRPCLimits(ctx, cfg)
+	if ctx.GlobalIsSet(RPCAccessLogFlag.Name) {
+		cfg.RPCAccessLogDir = ctx.GlobalString(RPCAccessLogFlag.Name)
+	}
+	setHealth(ctx, cfg)
 	setNodeUserIdent(ctx, cfg)
 
 	cfg.EnableNodePermission = ctx.GlobalBool(EnableNodePermissionFlag.Name)
+	if ctx.GlobalIsSet(PermissionAuditDirFlag.Name) {
+		cfg.PermissionAuditDir = ctx.GlobalString(PermissionAuditDirFlag.Name)
+	}
 
 	switch {
 	case ctx.GlobalIsSet(DataDirFlag.Name):
@@ -1096,6 +1311,28 @@ func setTxPool(ctx *cli.Context, cfg *core.TxPoolConfig) {
 	if ctx.GlobalIsSet(TxPoolLifetimeFlag.Name) {
 		cfg.Lifetime = ctx.GlobalDuration(TxPoolLifetimeFlag.Name)
 	}
+	if ctx.GlobalIsSet(TxPoolCheckPrivateFlag.Name) {
+		cfg.CheckPrivatePayloads = ctx.GlobalBool(TxPoolCheckPrivateFlag.Name)
+	}
+	if ctx.GlobalIsSet(TxPoolAllowedSendersFlag.Name) {
+		senders := strings.Split(ctx.GlobalString(TxPoolAllowedSendersFlag.Name), ",")
+		for _, account := range senders {
+			if trimmed := strings.TrimSpace(account); !common.IsHexAddress(trimmed) {
+				Fatalf("Invalid account in --txpool.allowedsenders: %s", trimmed)
+			} else {
+				cfg.AllowedSenders = append(cfg.AllowedSenders, common.HexToAddress(trimmed))
+			}
+		}
+	}
+	if ctx.GlobalIsSet(TxPoolAccountRateFlag.Name) {
+		cfg.AccountTxRate = ctx.GlobalUint64(TxPoolAccountRateFlag.Name)
+	}
+	if ctx.GlobalIsSet(TxPoolAccountGasFlag.Name) {
+		cfg.AccountGasRate = ctx.GlobalUint64(TxPoolAccountGasFlag.Name)
+	}
+	if ctx.GlobalIsSet(TxPoolPeerRateFlag.Name) {
+		cfg.PeerTxRate = ctx.GlobalUint64(TxPoolPeerRateFlag.Name)
+	}
 }
 
 func setEthash(ctx *cli.Context, cfg *eth.Config) {
@@ -1126,6 +1363,22 @@ func setIstanbul(ctx *cli.Context, cfg *eth.Config) {
 	if ctx.GlobalIsSet(IstanbulBlockPeriodFlag.Name) {
 		cfg.Istanbul.BlockPeriod = ctx.GlobalUint64(IstanbulBlockPeriodFlag.Name)
 	}
+	if ctx.GlobalIsSet(IstanbulValidatorFlag.Name) {
+		validator := ctx.GlobalString(IstanbulValidatorFlag.Name)
+		if !common.IsHexAddress(validator) {
+			Fatalf("Invalid Istanbul validator address: %s", validator)
+		}
+		cfg.Istanbul.Validator = common.HexToAddress(validator)
+	}
+	if ctx.GlobalIsSet(IstanbulSignerFlag.Name) {
+		cfg.Istanbul.SignerEndpoint = ctx.GlobalString(IstanbulSignerFlag.Name)
+	}
+	if ctx.GlobalIsSet(IstanbulSignerRateLimitFlag.Name) {
+		cfg.Istanbul.SignerRateLimit = ctx.GlobalUint64(IstanbulSignerRateLimitFlag.Name)
+	}
+	if ctx.GlobalIsSet(IstanbulValidatorSetSyncFlag.Name) {
+		cfg.Istanbul.ValidatorSetSync = ctx.GlobalBool(IstanbulValidatorSetSyncFlag.Name)
+	}
 }
 
 // checkExclusive verifies that only a single instance of the provided flags was
@@ -1258,6 +1511,30 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *eth.Config) {
 		// TODO(fjl): force-enable this in --dev mode
 		cfg.EnablePreimageRecording = ctx.GlobalBool(VMEnableDebugFlag.Name)
 	}
+	if ctx.GlobalIsSet(VMSaveRevertReasonFlag.Name) {
+		cfg.SaveRevertReason = ctx.GlobalBool(VMSaveRevertReasonFlag.Name)
+	}
+	if ctx.GlobalIsSet(RPCLogsBlockRangeFlag.Name) {
+		cfg.LogsBlockRange = ctx.GlobalUint64(RPCLogsBlockRangeFlag.Name)
+	}
+	if ctx.GlobalIsSet(PrivateStateChecksumsFlag.Name) {
+		cfg.PrivateStateChecksums = ctx.GlobalBool(PrivateStateChecksumsFlag.Name)
+	}
+	if ctx.GlobalIsSet(PrivateSimulationFlag.Name) {
+		cfg.PrivateSimulation = ctx.GlobalBool(PrivateSimulationFlag.Name)
+	}
+	if ctx.GlobalIsSet(PrivateSimulationTimeoutFlag.Name) {
+		cfg.PrivateSimulationTimeout = ctx.GlobalDuration(PrivateSimulationTimeoutFlag.Name)
+	}
+	if ctx.GlobalIsSet(PrivateTxManagerCheckIntervalFlag.Name) {
+		cfg.PrivateTxManagerCheckInterval = ctx.GlobalDuration(PrivateTxManagerCheckIntervalFlag.Name)
+	}
+	if ctx.GlobalIsSet(PrivateTxManagerPauseImportFlag.Name) {
+		cfg.PrivateTxManagerPauseImport = ctx.GlobalBool(PrivateTxManagerPauseImportFlag.Name)
+	}
+	if ctx.GlobalIsSet(PrivateTxManagerRejectPrivateFlag.Name) {
+		cfg.PrivateTxManagerRejectPrivate = ctx.GlobalBool(PrivateTxManagerRejectPrivateFlag.Name)
+	}
 
 	if ctx.GlobalIsSet(EWASMInterpreterFlag.Name) {
 		cfg.EWASMInterpreter = ctx.GlobalString(EWASMInterpreterFlag.Name)
@@ -1366,6 +1643,25 @@ func RegisterShhService(stack *node.Node, cfg *whisper.Config) {
 	}
 }
 
+// RegisterGraphQLService configures the GraphQL API service and adds it to
+// the given node, serving the data of the full or light Ethereum service on
+// the HTTP RPC endpoint.
+func RegisterGraphQLService(stack *node.Node, private bool) {
+	if err := stack.Register(func(ctx *node.ServiceContext) (node.Service, error) {
+		var ethServ *eth.Ethereum
+		if err := ctx.Service(&ethServ); err == nil {
+			return graphql.New(ethServ.APIBackend, private)
+		}
+		var lesServ *les.LightEthereum
+		if err := ctx.Service(&lesServ); err != nil {
+			return nil, err
+		}
+		return graphql.New(lesServ.ApiBackend, private)
+	}); err != nil {
+		Fatalf("Failed to register the GraphQL service: %v", err)
+	}
+}
+
 // RegisterEthStatsService configures the Ethereum Stats daemon and adds it to
 // the given node.
 func RegisterEthStatsService(stack *node.Node, url string) {
@@ -123,6 +123,33 @@ type Handler interface {
 	SetBroadcaster(Broadcaster)
 }
 
+// HeaderChainVerifier is a consensus engine able to verify pure header chains,
+// as downloaded during fast and light sync, more cheaply than full blocks.
+type HeaderChainVerifier interface {
+	// VerifyHeaderChain is similar to VerifyHeaders, but only ever invoked on
+	// headers inserted without their block bodies.
+	VerifyHeaderChain(chain ChainReader, headers []*types.Header, seals []bool) (chan<- struct{}, <-chan error)
+}
+
+// Transport is a consensus engine running its own devp2p sub-protocols next to
+// the eth protocol.
+type Transport interface {
+	// Protocols returns the devp2p sub-protocols run by the engine
+	Protocols() []p2p.Protocol
+
+	// SetServer sets the p2p server used to connect to other consensus participants
+	SetServer(*p2p.Server)
+}
+
+// PrivateTxGated is a consensus engine refusing to vote for blocks with private
+// transactions it can't process, e.g. while the private transaction manager is
+// down.
+type PrivateTxGated interface {
+	// SetPrivateTxGate installs a function returning an error while blocks with
+	// private transactions can't be processed
+	SetPrivateTxGate(gate func() error)
+}
+
 // PoW is a consensus engine based on proof-of-work.
 type PoW interface {
 	Engine
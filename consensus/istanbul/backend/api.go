@@ -67,6 +67,9 @@ func (api *API) GetValidators(number *rpc.BlockNumber) ([]common.Address, error)
 	if header == nil {
 		return nil, errUnknownBlock
 	}
+	if validators, ok := api.istanbul.valIndex.lookup(header.Number.Uint64()); ok {
+		return validators, nil
+	}
 	snap, err := api.istanbul.snapshot(api.chain, header.Number.Uint64(), header.Hash(), nil)
 	if err != nil {
 		return nil, err
@@ -74,6 +77,11 @@ func (api *API) GetValidators(number *rpc.BlockNumber) ([]common.Address, error)
 	return snap.validators(), nil
 }
 
+// GetValidatorHistory retrieves every change of the validator set indexed so far.
+func (api *API) GetValidatorHistory() *ValidatorHistory {
+	return api.istanbul.valIndex.export()
+}
+
 // GetValidatorsAtHash retrieves the state snapshot at a given block.
 func (api *API) GetValidatorsAtHash(hash common.Hash) ([]common.Address, error) {
 	header := api.chain.GetHeaderByHash(hash)
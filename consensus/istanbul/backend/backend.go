@@ -43,15 +43,31 @@ const (
 
 // New creates an Ethereum backend for Istanbul core engine.
 func New(config *istanbul.Config, privateKey *ecdsa.PrivateKey, db ethdb.Database) consensus.Istanbul {
+	backend := newIstanbulBackend(config, crypto.PubkeyToAddress(privateKey.PublicKey), db)
+	backend.privateKey = privateKey
+	backend.core = istanbulCore.New(backend, backend.config)
+	return backend
+}
+
+// NewWithSigner creates an Ethereum backend for Istanbul core engine, signing
+// consensus messages and block seals for the given validator address through
+// signFn instead of a locally held private key.
+func NewWithSigner(config *istanbul.Config, address common.Address, signFn SignerFn, db ethdb.Database) consensus.Istanbul {
+	backend := newIstanbulBackend(config, address, db)
+	backend.signer = newSignerPolicy(address, signFn, config.SignerRateLimit)
+	backend.core = istanbulCore.New(backend, backend.config)
+	return backend
+}
+
+func newIstanbulBackend(config *istanbul.Config, address common.Address, db ethdb.Database) *backend {
 	// Allocate the snapshot caches and create the engine
 	recents, _ := lru.NewARC(inmemorySnapshots)
 	recentMessages, _ := lru.NewARC(inmemoryPeers)
 	knownMessages, _ := lru.NewARC(inmemoryMessages)
-	backend := &backend{
+	return &backend{
 		config:           config,
 		istanbulEventMux: new(event.TypeMux),
-		privateKey:       privateKey,
-		address:          crypto.PubkeyToAddress(privateKey.PublicKey),
+		address:          address,
 		logger:           log.New(),
 		db:               db,
 		commitCh:         make(chan *types.Block, 1),
@@ -60,9 +76,9 @@ func New(config *istanbul.Config, privateKey *ecdsa.PrivateKey, db ethdb.Databas
 		coreStarted:      false,
 		recentMessages:   recentMessages,
 		knownMessages:    knownMessages,
+		valIndex:         newValidatorIndex(db),
+		transport:        newTransport(),
 	}
-	backend.core = istanbulCore.New(backend, backend.config)
-	return backend
 }
 
 // ----------------------------------------------------------------------------
@@ -71,6 +87,7 @@ type backend struct {
 	config           *istanbul.Config
 	istanbulEventMux *event.TypeMux
 	privateKey       *ecdsa.PrivateKey
+	signer           *signerPolicy // External signer used instead of privateKey, if set
 	address          common.Address
 	core             istanbulCore.Engine
 	logger           log.Logger
@@ -98,6 +115,15 @@ type backend struct {
 
 	recentMessages *lru.ARCCache // the cache of peer's messages
 	knownMessages  *lru.ARCCache // the cache of self messages
+
+	valIndex *validatorIndex // history of validator set changes for fast lookups
+
+	transport    *transport    // dedicated consensus sub-protocol peers and enode announcements
+	announceQuit chan struct{} // quit channel of the enode announcement loop
+
+	privateTxGate func() error // refuses proposals with private transactions while failing, if set
+
+	participation participationCache // validator participation at the last inspected chain head
 }
 
 // zekun: HACK
@@ -139,8 +165,15 @@ func (sb *backend) Gossip(valSet istanbul.ValidatorSet, payload []byte) error {
 		}
 	}
 
-	if sb.broadcaster != nil && len(targets) > 0 {
-		ps := sb.broadcaster.FindPeers(targets)
+	if len(targets) > 0 {
+		ps := make(map[common.Address]consensus.Peer)
+		if sb.broadcaster != nil {
+			ps = sb.broadcaster.FindPeers(targets)
+		}
+		// Prefer the dedicated consensus lane wherever the peer speaks it
+		for addr, p := range sb.transport.findPeers(targets) {
+			ps[addr] = p
+		}
 		for addr, p := range ps {
 			ms, ok := sb.recentMessages.Get(addr)
 			var m *lru.ARCCache
@@ -231,6 +264,13 @@ func (sb *backend) Verify(proposal istanbul.Proposal) (time.Duration, error) {
 		return 0, errInvalidUncleHash
 	}
 
+	// refuse proposals this node couldn't process the private transactions of
+	if sb.privateTxGate != nil && hasPrivateTransactions(block) {
+		if err := sb.privateTxGate(); err != nil {
+			return 0, err
+		}
+	}
+
 	// verify the header of proposed block
 	err := sb.VerifyHeader(sb.chain, block.Header(), false)
 	// ignore errEmptyCommittedSeals error because we don't have the committed seals yet
@@ -242,9 +282,29 @@ func (sb *backend) Verify(proposal istanbul.Proposal) (time.Duration, error) {
 	return 0, err
 }
 
+// SetPrivateTxGate implements consensus.PrivateTxGated.SetPrivateTxGate.
+// Proposals containing private transactions are refused while the gate returns
+// an error. It must be set before the engine is started.
+func (sb *backend) SetPrivateTxGate(gate func() error) {
+	sb.privateTxGate = gate
+}
+
+// hasPrivateTransactions reports whether the block contains a private transaction.
+func hasPrivateTransactions(block *types.Block) bool {
+	for _, tx := range block.Transactions() {
+		if tx.IsPrivate() {
+			return true
+		}
+	}
+	return false
+}
+
 // Sign implements istanbul.Backend.Sign
 func (sb *backend) Sign(data []byte) ([]byte, error) {
 	hashData := crypto.Keccak256([]byte(data))
+	if sb.signer != nil {
+		return sb.signer.sign(hashData)
+	}
 	return crypto.Sign(hashData, sb.privateKey)
 }
 
@@ -319,5 +379,6 @@ func (sb *backend) HasBadProposal(hash common.Hash) bool {
 
 
 func (sb *backend) Close() error {
+	sb.valIndex.close()
 	return nil
 }
\ No newline at end of file
@@ -24,11 +24,13 @@ import (
 	"testing"
 	"time"
 
+	"github.com/ethereum/go-ethereum/accounts"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/consensus/istanbul"
 	"github.com/ethereum/go-ethereum/consensus/istanbul/validator"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb"
 )
 
 func TestSign(t *testing.T) {
@@ -48,6 +50,52 @@ func TestSign(t *testing.T) {
 	}
 }
 
+func TestSignWithSigner(t *testing.T) {
+	key, _ := generatePrivateKey()
+	addr := crypto.PubkeyToAddress(key.PublicKey)
+	signFn := func(account accounts.Account, hash []byte) ([]byte, error) {
+		if account.Address != addr {
+			t.Errorf("account mismatch: have %v, want %v", account.Address.Hex(), addr.Hex())
+		}
+		sig, err := crypto.Sign(hash, key)
+		if err == nil {
+			sig[64] += 27 // External signers may use the yellow paper V
+		}
+		return sig, err
+	}
+	config := *istanbul.DefaultConfig
+	config.SignerRateLimit = 2
+	b := NewWithSigner(&config, addr, signFn, ethdb.NewMemDatabase()).(*backend)
+	if b.Address() != addr {
+		t.Errorf("address mismatch: have %v, want %v", b.Address().Hex(), addr.Hex())
+	}
+	data := []byte("Here is a string....")
+	for i := 0; i < 2; i++ {
+		sig, err := b.Sign(data)
+		if err != nil {
+			t.Fatalf("error mismatch: have %v, want nil", err)
+		}
+		if err := b.CheckSignature(data, addr, sig); err != nil {
+			t.Errorf("error mismatch: have %v, want nil", err)
+		}
+	}
+	if _, err := b.Sign(data); err != errSignerRateLimited {
+		t.Errorf("error mismatch: have %v, want %v", err, errSignerRateLimited)
+	}
+}
+
+func TestSignWithInvalidSigner(t *testing.T) {
+	key, _ := generatePrivateKey()
+	other, _ := crypto.GenerateKey()
+	signFn := func(account accounts.Account, hash []byte) ([]byte, error) {
+		return crypto.Sign(hash, other)
+	}
+	b := NewWithSigner(istanbul.DefaultConfig, crypto.PubkeyToAddress(key.PublicKey), signFn, ethdb.NewMemDatabase()).(*backend)
+	if _, err := b.Sign([]byte("Here is a string....")); err != errInvalidSignerSignature {
+		t.Errorf("error mismatch: have %v, want %v", err, errInvalidSignerSignature)
+	}
+}
+
 func TestCheckSignature(t *testing.T) {
 	key, _ := generatePrivateKey()
 	data := []byte("Here is a string....")
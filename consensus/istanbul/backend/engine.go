@@ -78,6 +78,9 @@ var (
 	errInvalidVote = errors.New("vote nonce not 0x00..0 or 0xff..f")
 	// errInvalidCommittedSeals is returned if the committed seal is not signed by any of parent validators.
 	errInvalidCommittedSeals = errors.New("invalid committed seals")
+	// errInvalidValidatorTransition is returned if a header changes the validator set
+	// other than by the vote of its parent.
+	errInvalidValidatorTransition = errors.New("invalid validator set transition")
 	// errEmptyCommittedSeals is returned if the field of committed seals is zero.
 	errEmptyCommittedSeals = errors.New("zero committed seals")
 	// errMismatchTxhashes is returned if the TxHash in header is mismatch.
@@ -115,6 +118,15 @@ func (sb *backend) VerifyHeader(chain consensus.ChainReader, header *types.Heade
 // looking those up from the database. This is useful for concurrently verifying
 // a batch of new headers.
 func (sb *backend) verifyHeader(chain consensus.ChainReader, header *types.Header, parents []*types.Header) error {
+	if err := sb.verifyHeaderFields(header); err != nil {
+		return err
+	}
+	return sb.verifyCascadingFields(chain, header, parents)
+}
+
+// verifyHeaderFields verifies all the header fields that are standalone, not
+// depending on any previous headers.
+func (sb *backend) verifyHeaderFields(header *types.Header) error {
 	if header.Number == nil {
 		return errUnknownBlock
 	}
@@ -145,8 +157,7 @@ func (sb *backend) verifyHeader(chain consensus.ChainReader, header *types.Heade
 	if header.Difficulty == nil || header.Difficulty.Cmp(defaultDifficulty) != 0 {
 		return errInvalidDifficulty
 	}
-
-	return sb.verifyCascadingFields(chain, header, parents)
+	return nil
 }
 
 // verifyCascadingFields verifies all the header fields that are not standalone,
@@ -411,6 +422,15 @@ func (sb *backend) Seal(chain consensus.ChainReader, block *types.Block, results
 	if parent == nil {
 		return consensus.ErrUnknownAncestor
 	}
+	// hold back empty blocks until the empty block period has passed, new
+	// transactions will resubmit the work and replace the empty block
+	if len(block.Transactions()) == 0 && sb.config.EmptyBlockPeriod > sb.config.BlockPeriod {
+		emptyTime := new(big.Int).Add(parent.Time, new(big.Int).SetUint64(sb.config.EmptyBlockPeriod))
+		if header.Time.Cmp(emptyTime) < 0 {
+			header.Time = emptyTime
+			block = block.WithSeal(header)
+		}
+	}
 	block, err = sb.updateBlock(parent, block)
 	if err != nil {
 		return err
@@ -503,6 +523,8 @@ func (sb *backend) Start(chain consensus.ChainReader, currentBlock func() *types
 	if err := sb.core.Start(); err != nil {
 		return err
 	}
+	sb.announceQuit = make(chan struct{})
+	go sb.announceLoop(sb.announceQuit)
 
 	sb.coreStarted = true
 	return nil
@@ -518,6 +540,8 @@ func (sb *backend) Stop() error {
 	if err := sb.core.Stop(); err != nil {
 		return err
 	}
+	close(sb.announceQuit)
+
 	sb.coreStarted = false
 	return nil
 }
@@ -535,8 +559,9 @@ func (sb *backend) snapshot(chain consensus.ChainReader, number uint64, hash com
 			snap = s.(*Snapshot)
 			break
 		}
-		// If an on-disk checkpoint snapshot can be found, use that
-		if number%checkpointInterval == 0 {
+		// If an on-disk checkpoint snapshot can be found, use that. Epoch snapshots
+		// are stored when syncing validator sets.
+		if number%checkpointInterval == 0 || number%sb.config.Epoch == 0 {
 			if s, err := loadSnapshot(sb.config.Epoch, sb.db, hash); err == nil {
 				log.Trace("Loaded voting snapshot form disk", "number", number, "hash", hash)
 				snap = s
@@ -596,6 +621,7 @@ func (sb *backend) snapshot(chain consensus.ChainReader, number uint64, hash com
 		}
 		log.Trace("Stored voting snapshot to disk", "number", snap.Number, "hash", snap.Hash)
 	}
+	sb.valIndex.notify(sb, chain)
 	return snap, err
 }
 
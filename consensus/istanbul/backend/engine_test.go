@@ -191,6 +191,48 @@ func TestSealStopChannel(t *testing.T) {
 	}
 }
 
+func TestSealEmptyBlockPeriod(t *testing.T) {
+	chain, engine := newBlockChain(1)
+
+	// Seal a parent block at the current time, so the empty block period is
+	// counted from a known timestamp.
+	parent := makeBlock(chain, engine, chain.Genesis())
+	if _, err := chain.InsertChain(types.Blocks{parent}); err != nil {
+		t.Fatalf("failed to insert parent block: %v", err)
+	}
+	config := *engine.config
+	config.EmptyBlockPeriod = config.BlockPeriod + 2
+	engine.config = &config
+
+	block := makeBlockWithoutSeal(chain, engine, parent)
+	eventSub := engine.EventMux().Subscribe(istanbul.RequestEvent{})
+	defer eventSub.Unsubscribe()
+
+	resultCh := make(chan *types.Block, 10)
+	go func() {
+		err := engine.Seal(chain, block, resultCh, make(chan struct{}))
+		if err != nil {
+			t.Errorf("error mismatch: have %v, want nil", err)
+		}
+	}()
+
+	// The empty block must be held back past the block period, and proposed
+	// once the empty block period has passed.
+	emptyTime := time.Unix(parent.Time().Int64()+int64(config.EmptyBlockPeriod), 0)
+	select {
+	case ev := <-eventSub.Chan():
+		if arrival := time.Now(); arrival.Before(emptyTime) {
+			t.Errorf("empty block proposed at %v, before the empty block period ending %v: %v", arrival, emptyTime, ev.Data)
+		}
+	case <-time.After(time.Until(emptyTime) + 5*time.Second):
+		t.Fatalf("empty block not proposed after the empty block period")
+	}
+	finalBlock := <-resultCh
+	if want := emptyTime.Unix(); finalBlock.Time().Int64() != want {
+		t.Errorf("timestamp mismatch: have %v, want %v", finalBlock.Time(), want)
+	}
+}
+
 func TestSealCommittedOtherHash(t *testing.T) {
 	chain, engine := newBlockChain(4)
 	block := makeBlockWithoutSeal(chain, engine, chain.Genesis())
@@ -0,0 +1,408 @@
+// Copyright 2017 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package backend
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+const (
+	dbKeyValidatorHead   = "istanbul-validator-head"    // Head of the indexed validator history
+	dbKeyValidatorChange = "istanbul-validator-change-" // Prefix of the validator changes, followed by the block number
+)
+
+// errNonCanonicalHistory is returned if the indexed validator history doesn't
+// line up with the canonical chain anymore.
+var errNonCanonicalHistory = errors.New("validator history not on canonical chain")
+
+// ValidatorChange is a point in the chain where the set of validators changed.
+type ValidatorChange struct {
+	Number     uint64           `json:"number"`     // Block number from which on the validator set is in effect
+	Hash       common.Hash      `json:"hash"`       // Block hash from which on the validator set is in effect
+	Validators []common.Address `json:"validators"` // Set of authorized validators, in ascending order
+}
+
+// ValidatorHistory is the list of all validator set changes up to a block.
+type ValidatorHistory struct {
+	Head     uint64             `json:"head"`     // Last block number covered by the history
+	HeadHash common.Hash        `json:"headHash"` // Last block hash covered by the history
+	Changes  []*ValidatorChange `json:"changes"`  // Validator set changes in ascending block order
+}
+
+// validatorHistoryHead is the database record of the head of the validator
+// history. The changes themselves are stored under their own keys, each linking
+// to the previous one.
+type validatorHistoryHead struct {
+	Head     uint64      // Last block number covered by the history
+	HeadHash common.Hash // Last block hash covered by the history
+	Last     uint64      // Block number of the most recent validator change
+}
+
+// storedValidatorChange is the database record of a single validator change.
+type storedValidatorChange struct {
+	Prev   uint64 // Block number of the preceding change, unused for genesis
+	Change *ValidatorChange
+}
+
+// validatorChangeKey = dbKeyValidatorChange + number (uint64 big endian)
+func validatorChangeKey(number uint64) []byte {
+	key := make([]byte, len(dbKeyValidatorChange)+8)
+	copy(key, dbKeyValidatorChange)
+	binary.BigEndian.PutUint64(key[len(dbKeyValidatorChange):], number)
+	return key
+}
+
+// validatorIndex maintains the validator history of the canonical chain in the
+// background, so the validators at any indexed height can be looked up without
+// replaying votes on top of a snapshot.
+type validatorIndex struct {
+	db      ethdb.Database
+	history ValidatorHistory
+	lock    sync.RWMutex
+
+	snap *Snapshot // Running snapshot at the head of the history, owned by loop
+
+	update chan consensus.ChainReader
+	quit   chan struct{}
+	start  sync.Once
+}
+
+// newValidatorIndex creates a validator index, loading any history previously
+// stored in the database.
+func newValidatorIndex(db ethdb.Database) *validatorIndex {
+	idx := &validatorIndex{
+		db:     db,
+		update: make(chan consensus.ChainReader, 1),
+		quit:   make(chan struct{}),
+	}
+	if history, err := loadValidatorHistory(db); err != nil {
+		log.Warn("Failed to load validator history, reindexing", "err", err)
+	} else if history != nil {
+		idx.history = *history
+	}
+	return idx
+}
+
+// loadValidatorHistory reads the validator history from the database, walking
+// the stored changes back from the most recent one to genesis.
+func loadValidatorHistory(db ethdb.Database) (*ValidatorHistory, error) {
+	blob, err := db.Get([]byte(dbKeyValidatorHead))
+	if err != nil {
+		return nil, nil
+	}
+	var head validatorHistoryHead
+	if err := rlp.DecodeBytes(blob, &head); err != nil {
+		return nil, err
+	}
+	var changes []*ValidatorChange
+	for number := head.Last; ; {
+		blob, err := db.Get(validatorChangeKey(number))
+		if err != nil {
+			return nil, fmt.Errorf("missing validator change %d", number)
+		}
+		var stored storedValidatorChange
+		if err := rlp.DecodeBytes(blob, &stored); err != nil {
+			return nil, err
+		}
+		if stored.Change == nil || stored.Change.Number != number {
+			return nil, fmt.Errorf("corrupt validator change %d", number)
+		}
+		changes = append(changes, stored.Change)
+		if number == 0 {
+			break
+		}
+		if stored.Prev >= number {
+			return nil, fmt.Errorf("corrupt validator change %d: preceded by %d", number, stored.Prev)
+		}
+		number = stored.Prev
+	}
+	for i, j := 0, len(changes)-1; i < j; i, j = i+1, j-1 {
+		changes[i], changes[j] = changes[j], changes[i]
+	}
+	return &ValidatorHistory{Head: head.Head, HeadHash: head.HeadHash, Changes: changes}, nil
+}
+
+// lookup returns the validators at the given block number, if it's covered by
+// the history.
+func (idx *validatorIndex) lookup(number uint64) ([]common.Address, bool) {
+	idx.lock.RLock()
+	defer idx.lock.RUnlock()
+
+	changes := idx.history.Changes
+	if len(changes) == 0 || number > idx.history.Head {
+		return nil, false
+	}
+	i := sort.Search(len(changes), func(i int) bool { return changes[i].Number > number })
+	if i == 0 {
+		return nil, false
+	}
+	return changes[i-1].Validators, true
+}
+
+// export returns a copy of the validator history indexed so far.
+func (idx *validatorIndex) export() *ValidatorHistory {
+	idx.lock.RLock()
+	defer idx.lock.RUnlock()
+
+	return &ValidatorHistory{
+		Head:     idx.history.Head,
+		HeadHash: idx.history.HeadHash,
+		Changes:  append([]*ValidatorChange(nil), idx.history.Changes...),
+	}
+}
+
+// notify signals the index that the chain might have progressed, starting the
+// indexing loop on first use. It never blocks.
+func (idx *validatorIndex) notify(sb *backend, chain consensus.ChainReader) {
+	idx.start.Do(func() { go idx.loop(sb) })
+
+	select {
+	case idx.update <- chain:
+	default:
+	}
+}
+
+// close terminates the indexing loop.
+func (idx *validatorIndex) close() {
+	select {
+	case <-idx.quit:
+	default:
+		close(idx.quit)
+	}
+}
+
+// loop extends the validator history up to the chain head whenever notified.
+func (idx *validatorIndex) loop(sb *backend) {
+	for {
+		select {
+		case chain := <-idx.update:
+			err := idx.extend(sb, chain)
+			if err == errNonCanonicalHistory {
+				log.Debug("Validator history diverged from chain, rewinding")
+				idx.rewind(chain)
+				err = idx.extend(sb, chain)
+			}
+			if err != nil {
+				log.Debug("Failed to extend validator history", "err", err)
+			}
+		case <-idx.quit:
+			return
+		}
+	}
+}
+
+// rewind truncates the history back to the last block it shares with the
+// canonical chain, following the abandoned chain back to the fork point. If the
+// abandoned headers are gone, it falls back to the last canonical change.
+func (idx *validatorIndex) rewind(chain consensus.ChainReader) {
+	idx.lock.RLock()
+	number, hash, changes := idx.history.Head, idx.history.HeadHash, idx.history.Changes
+	idx.lock.RUnlock()
+
+	idx.snap = nil
+	for {
+		if canon := chain.GetHeaderByNumber(number); canon != nil && canon.Hash() == hash {
+			break
+		}
+		header := chain.GetHeader(hash, number)
+		if header == nil || number == 0 {
+			keep := len(changes)
+			for ; keep > 0; keep-- {
+				change := changes[keep-1]
+				if canon := chain.GetHeaderByNumber(change.Number); canon != nil && canon.Hash() == change.Hash {
+					break
+				}
+			}
+			if keep == 0 {
+				idx.truncate(0, 0, common.Hash{})
+				return
+			}
+			number, hash = changes[keep-1].Number, changes[keep-1].Hash
+			break
+		}
+		number, hash = number-1, header.ParentHash
+	}
+	keep := sort.Search(len(changes), func(i int) bool { return changes[i].Number > number })
+	idx.truncate(keep, number, hash)
+}
+
+// truncate keeps only the first changes of the history, moving its head back
+// to the given block. Dropping every change empties the history.
+func (idx *validatorIndex) truncate(keep int, number uint64, hash common.Hash) {
+	idx.lock.Lock()
+	dropped := idx.history.Changes[keep:]
+	if keep == 0 {
+		idx.history = ValidatorHistory{}
+	} else {
+		idx.history.Changes = idx.history.Changes[:keep:keep]
+		idx.history.Head, idx.history.HeadHash = number, hash
+	}
+	idx.lock.Unlock()
+
+	log.Info("Rewound validator history", "number", number, "dropped", len(dropped))
+
+	batch := idx.db.NewBatch()
+	for _, change := range dropped {
+		batch.Delete(validatorChangeKey(change.Number))
+	}
+	if keep == 0 {
+		batch.Delete([]byte(dbKeyValidatorHead))
+	}
+	if err := batch.Write(); err != nil {
+		log.Warn("Failed to truncate validator history", "err", err)
+	}
+	if keep > 0 {
+		idx.store()
+	}
+}
+
+// extend applies the headers between the head of the history and the head of
+// the chain, recording every change of the validator set.
+func (idx *validatorIndex) extend(sb *backend, chain consensus.ChainReader) error {
+	current := chain.CurrentHeader()
+	if current == nil {
+		return nil
+	}
+	if idx.snap == nil {
+		idx.lock.RLock()
+		head, hash, empty := idx.history.Head, idx.history.HeadHash, len(idx.history.Changes) == 0
+		idx.lock.RUnlock()
+
+		if empty {
+			genesis := chain.GetHeaderByNumber(0)
+			if genesis == nil {
+				return consensus.ErrUnknownAncestor
+			}
+			head, hash = 0, genesis.Hash()
+		} else if header := chain.GetHeaderByNumber(head); header == nil || header.Hash() != hash {
+			return errNonCanonicalHistory
+		}
+		snap, err := sb.snapshot(chain, head, hash, nil)
+		if err != nil {
+			return err
+		}
+		if empty {
+			idx.record(snap, true)
+		}
+		idx.snap = snap
+	} else if header := chain.GetHeaderByNumber(idx.snap.Number); header == nil || header.Hash() != idx.snap.Hash {
+		// The chain was rewound or reorganised below the head of the history
+		return errNonCanonicalHistory
+	}
+	defer idx.store()
+
+	for number := idx.snap.Number + 1; number <= current.Number.Uint64(); number++ {
+		header := chain.GetHeaderByNumber(number)
+		if header == nil {
+			return consensus.ErrUnknownAncestor
+		}
+		if header.ParentHash != idx.snap.Hash {
+			return errNonCanonicalHistory
+		}
+		snap, err := idx.snap.apply([]*types.Header{header})
+		if err != nil {
+			return err
+		}
+		idx.record(snap, !sameValidators(idx.snap, snap))
+		idx.snap = snap
+
+		if number%checkpointInterval == 0 {
+			idx.store()
+		}
+		select {
+		case <-idx.quit:
+			return nil
+		default:
+		}
+	}
+	return nil
+}
+
+// record advances the head of the history to the snapshot, adding its
+// validators as a new change if requested. A new change is written to the
+// database right away, the head only when the history is stored.
+func (idx *validatorIndex) record(snap *Snapshot, changed bool) {
+	idx.lock.Lock()
+	defer idx.lock.Unlock()
+
+	if changed {
+		stored := storedValidatorChange{Change: &ValidatorChange{
+			Number:     snap.Number,
+			Hash:       snap.Hash,
+			Validators: snap.validators(),
+		}}
+		if n := len(idx.history.Changes); n > 0 {
+			stored.Prev = idx.history.Changes[n-1].Number
+		}
+		blob, err := rlp.EncodeToBytes(&stored)
+		if err == nil {
+			err = idx.db.Put(validatorChangeKey(snap.Number), blob)
+		}
+		if err != nil {
+			log.Warn("Failed to store validator change", "number", snap.Number, "err", err)
+		}
+		idx.history.Changes = append(idx.history.Changes, stored.Change)
+	}
+	idx.history.Head, idx.history.HeadHash = snap.Number, snap.Hash
+}
+
+// store persists the head of the validator history into the database.
+func (idx *validatorIndex) store() {
+	idx.lock.RLock()
+	changes := idx.history.Changes
+	if len(changes) == 0 {
+		idx.lock.RUnlock()
+		return
+	}
+	blob, err := rlp.EncodeToBytes(&validatorHistoryHead{
+		Head:     idx.history.Head,
+		HeadHash: idx.history.HeadHash,
+		Last:     changes[len(changes)-1].Number,
+	})
+	idx.lock.RUnlock()
+
+	if err == nil {
+		err = idx.db.Put([]byte(dbKeyValidatorHead), blob)
+	}
+	if err != nil {
+		log.Warn("Failed to store validator history", "err", err)
+	}
+}
+
+// sameValidators reports whether two snapshots have the same set of validators.
+func sameValidators(a, b *Snapshot) bool {
+	if a.ValSet.Size() != b.ValSet.Size() {
+		return false
+	}
+	for _, val := range a.ValSet.List() {
+		if _, v := b.ValSet.GetByAddress(val.Address()); v == nil {
+			return false
+		}
+	}
+	return true
+}
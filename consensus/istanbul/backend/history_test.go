@@ -0,0 +1,172 @@
+// Copyright 2017 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package backend
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/istanbul"
+	"github.com/ethereum/go-ethereum/consensus/istanbul/validator"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethdb"
+)
+
+func TestValidatorIndexLookup(t *testing.T) {
+	var (
+		a = common.StringToAddress("1234567891")
+		b = common.StringToAddress("1234567892")
+		c = common.StringToAddress("1234567893")
+	)
+	idx := newValidatorIndex(ethdb.NewMemDatabase())
+	if _, ok := idx.lookup(0); ok {
+		t.Errorf("lookup on empty history succeeded")
+	}
+	idx.history = ValidatorHistory{
+		Head: 100,
+		Changes: []*ValidatorChange{
+			{Number: 0, Validators: []common.Address{a}},
+			{Number: 10, Validators: []common.Address{a, b}},
+			{Number: 50, Validators: []common.Address{a, b, c}},
+		},
+	}
+	tests := []struct {
+		number     uint64
+		validators []common.Address
+		ok         bool
+	}{
+		{0, []common.Address{a}, true},
+		{9, []common.Address{a}, true},
+		{10, []common.Address{a, b}, true},
+		{49, []common.Address{a, b}, true},
+		{50, []common.Address{a, b, c}, true},
+		{100, []common.Address{a, b, c}, true},
+		{101, nil, false},
+	}
+	for i, tt := range tests {
+		validators, ok := idx.lookup(tt.number)
+		if ok != tt.ok || !reflect.DeepEqual(validators, tt.validators) {
+			t.Errorf("test %d: lookup mismatch: have %x (%v), want %x (%v)", i, validators, ok, tt.validators, tt.ok)
+		}
+	}
+}
+
+func TestValidatorIndexExtend(t *testing.T) {
+	chain, engine := newBlockChain(1)
+	block := chain.Genesis()
+	for i := 0; i < 3; i++ {
+		block = makeBlock(chain, engine, block)
+		if _, err := chain.InsertChain(types.Blocks{block}); err != nil {
+			t.Fatalf("failed to insert block %d: %v", i, err)
+		}
+		engine.NewChainHead()
+	}
+	db := ethdb.NewMemDatabase()
+	idx := newValidatorIndex(db)
+	if err := idx.extend(engine, chain); err != nil {
+		t.Fatalf("failed to extend validator history: %v", err)
+	}
+	history := idx.export()
+	if history.Head != 3 || history.HeadHash != block.Hash() {
+		t.Errorf("head mismatch: have %d/%x, want %d/%x", history.Head, history.HeadHash, 3, block.Hash())
+	}
+	if len(history.Changes) != 1 || history.Changes[0].Number != 0 {
+		t.Fatalf("changes mismatch: have %v, want genesis only", history.Changes)
+	}
+	if !reflect.DeepEqual(history.Changes[0].Validators, []common.Address{engine.Address()}) {
+		t.Errorf("validators mismatch: have %x, want %x", history.Changes[0].Validators, engine.Address())
+	}
+	// The history must survive a restart
+	if reloaded := newValidatorIndex(db).export(); !reflect.DeepEqual(reloaded, history) {
+		t.Errorf("reloaded history mismatch: have %v, want %v", reloaded, history)
+	}
+}
+
+func TestValidatorIndexRewind(t *testing.T) {
+	chain, engine := newBlockChain(1)
+	block := chain.Genesis()
+	for i := 0; i < 3; i++ {
+		block = makeBlock(chain, engine, block)
+		if _, err := chain.InsertChain(types.Blocks{block}); err != nil {
+			t.Fatalf("failed to insert block %d: %v", i, err)
+		}
+		engine.NewChainHead()
+	}
+	db := ethdb.NewMemDatabase()
+	idx := newValidatorIndex(db)
+	if err := idx.extend(engine, chain); err != nil {
+		t.Fatalf("failed to extend validator history: %v", err)
+	}
+	if ok, _ := db.Has(validatorChangeKey(0)); !ok {
+		t.Fatalf("genesis validator change not stored under its own key")
+	}
+	// Rewind the chain below the head of the history, which must be detected
+	// and truncated instead of serving the abandoned blocks
+	if err := chain.SetHead(1); err != nil {
+		t.Fatalf("failed to rewind chain: %v", err)
+	}
+	if err := idx.extend(engine, chain); err != errNonCanonicalHistory {
+		t.Fatalf("error mismatch: have %v, want %v", err, errNonCanonicalHistory)
+	}
+	idx.rewind(chain)
+	if err := idx.extend(engine, chain); err != nil {
+		t.Fatalf("failed to extend validator history: %v", err)
+	}
+	history := idx.export()
+	if head := chain.CurrentHeader(); history.Head != 1 || history.HeadHash != head.Hash() {
+		t.Errorf("head mismatch: have %d/%x, want %d/%x", history.Head, history.HeadHash, 1, head.Hash())
+	}
+	if _, ok := idx.lookup(2); ok {
+		t.Errorf("lookup beyond the rewound head succeeded")
+	}
+	if len(history.Changes) != 1 || history.Changes[0].Number != 0 {
+		t.Fatalf("changes mismatch: have %v, want genesis only", history.Changes)
+	}
+	if reloaded := newValidatorIndex(db).export(); !reflect.DeepEqual(reloaded, history) {
+		t.Errorf("reloaded history mismatch: have %v, want %v", reloaded, history)
+	}
+}
+
+func TestValidatorIndexTruncate(t *testing.T) {
+	var (
+		a = common.StringToAddress("1234567891")
+		b = common.StringToAddress("1234567892")
+	)
+	db := ethdb.NewMemDatabase()
+	idx := newValidatorIndex(db)
+	idx.record(&Snapshot{Number: 0, ValSet: validator.NewSet([]common.Address{a}, istanbul.RoundRobin)}, true)
+	idx.record(&Snapshot{Number: 10, ValSet: validator.NewSet([]common.Address{a, b}, istanbul.RoundRobin)}, true)
+	idx.record(&Snapshot{Number: 20, ValSet: validator.NewSet([]common.Address{a}, istanbul.RoundRobin)}, true)
+	idx.store()
+
+	if history := newValidatorIndex(db).export(); len(history.Changes) != 3 || history.Head != 20 {
+		t.Fatalf("reloaded history mismatch: have %d changes up to %d, want 3 up to 20", len(history.Changes), history.Head)
+	}
+	idx.truncate(2, 15, common.Hash{0x15})
+
+	if ok, _ := db.Has(validatorChangeKey(20)); ok {
+		t.Errorf("truncated validator change still stored")
+	}
+	history := newValidatorIndex(db).export()
+	if len(history.Changes) != 2 || history.Head != 15 || history.HeadHash != (common.Hash{0x15}) {
+		t.Fatalf("reloaded history mismatch: have %d changes up to %d/%x, want 2 up to 15", len(history.Changes), history.Head, history.HeadHash)
+	}
+	if validators, _ := idx.lookup(15); !reflect.DeepEqual(validators, []common.Address{a, b}) {
+		t.Errorf("validators mismatch: have %x, want %x", validators, []common.Address{a, b})
+	}
+}
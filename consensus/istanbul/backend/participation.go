@@ -0,0 +1,106 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package backend
+
+import (
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/consensus/istanbul"
+	istanbulCore "github.com/ethereum/go-ethereum/consensus/istanbul/core"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// Participation describes how the validators took part in committing the most
+// recent blocks of the chain.
+type Participation struct {
+	Blocks     uint64 `json:"blocks"`     // Number of blocks inspected
+	Validators int    `json:"validators"` // Size of the validator set at the chain head
+	Committers int    `json:"committers"` // Validators with a committed seal in any inspected block
+	Validator  bool   `json:"validator"`  // Whether the local node is a validator at the chain head
+	Committed  uint64 `json:"committed"`  // Number of inspected blocks committed by the local node
+}
+
+// participationCache holds the participation last inspected, which stays valid
+// until the chain head changes.
+type participationCache struct {
+	head   common.Hash
+	blocks uint64
+	result *Participation
+	lock   sync.Mutex
+}
+
+// Participation inspects the committed seals of up to the given number of
+// blocks at the head of the chain. The seals are only recovered once per chain
+// head, later calls at the same head are answered from the cache.
+func (sb *backend) Participation(chain consensus.ChainReader, blocks uint64) (*Participation, error) {
+	head := chain.CurrentHeader()
+
+	cache := &sb.participation
+	cache.lock.Lock()
+	defer cache.lock.Unlock()
+
+	if cache.result == nil || cache.head != head.Hash() || cache.blocks != blocks {
+		result, err := sb.inspectParticipation(chain, head, blocks)
+		if err != nil {
+			return nil, err
+		}
+		cache.head, cache.blocks, cache.result = head.Hash(), blocks, result
+	}
+	participation := *cache.result
+	return &participation, nil
+}
+
+// inspectParticipation recovers the committers of up to the given number of
+// blocks ending at head.
+func (sb *backend) inspectParticipation(chain consensus.ChainReader, head *types.Header, blocks uint64) (*Participation, error) {
+	snap, err := sb.snapshot(chain, head.Number.Uint64(), head.Hash(), nil)
+	if err != nil {
+		return nil, err
+	}
+	_, local := snap.ValSet.GetByAddress(sb.address)
+	participation := &Participation{
+		Validators: snap.ValSet.Size(),
+		Validator:  local != nil,
+	}
+	committers := make(map[common.Address]bool)
+	for header := head; header != nil && header.Number.Sign() > 0 && participation.Blocks < blocks; header = chain.GetHeader(header.ParentHash, header.Number.Uint64()-1) {
+		extra, err := types.ExtractIstanbulExtra(header)
+		if err != nil {
+			return nil, err
+		}
+		proposalSeal := istanbulCore.PrepareCommittedSeal(header.Hash())
+		for _, seal := range extra.CommittedSeal {
+			addr, err := istanbul.GetSignatureAddress(proposalSeal, seal)
+			if err != nil {
+				return nil, errInvalidSignature
+			}
+			committers[addr] = true
+			if addr == sb.address {
+				participation.Committed++
+			}
+		}
+		participation.Blocks++
+	}
+	for _, validator := range snap.ValSet.List() {
+		if committers[validator.Address()] {
+			participation.Committers++
+		}
+	}
+	return participation, nil
+}
@@ -0,0 +1,64 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package backend
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestParticipation(t *testing.T) {
+	chain, engine := newBlockChain(1)
+
+	// The genesis block carries no committed seals to inspect.
+	participation, err := engine.Participation(chain, 10)
+	if err != nil {
+		t.Fatalf("failed to inspect participation: %v", err)
+	}
+	if participation.Blocks != 0 || participation.Validators != 1 || !participation.Validator || participation.Committers != 0 {
+		t.Errorf("genesis participation mismatch: %+v", participation)
+	}
+	// Commit a few blocks by the single validator and inspect a part of them.
+	parent := chain.Genesis()
+	for i := 0; i < 3; i++ {
+		block := makeBlock(chain, engine, parent)
+		if _, err := chain.InsertChain(types.Blocks{block}); err != nil {
+			t.Fatalf("failed to insert block %d: %v", i, err)
+		}
+		parent = block
+	}
+	participation, err = engine.Participation(chain, 2)
+	if err != nil {
+		t.Fatalf("failed to inspect participation: %v", err)
+	}
+	if participation.Blocks != 2 || participation.Committers != 1 || participation.Committed != 2 {
+		t.Errorf("participation mismatch: %+v", participation)
+	}
+	// The participation is inspected anew once the chain head changes.
+	block := makeBlock(chain, engine, parent)
+	if _, err := chain.InsertChain(types.Blocks{block}); err != nil {
+		t.Fatalf("failed to insert block: %v", err)
+	}
+	participation, err = engine.Participation(chain, 10)
+	if err != nil {
+		t.Fatalf("failed to inspect participation: %v", err)
+	}
+	if participation.Blocks != 4 || participation.Committed != 4 {
+		t.Errorf("participation mismatch after new head: %+v", participation)
+	}
+}
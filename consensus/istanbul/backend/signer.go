@@ -0,0 +1,168 @@
+// Copyright 2017 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package backend
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+const (
+	// remoteSignMethod is the RPC method an external signer must expose to sign
+	// consensus messages. It takes the signer address and a 32 byte hash and
+	// returns a 65 byte [R || S || V] signature over the hash as is, without the
+	// personal message prefix. Clef serves it, gated by an ApproveSignHash rule.
+	remoteSignMethod = "account_signHash"
+
+	// remoteSignTimeout bounds a single signing round trip to an external signer,
+	// keeping it well below the Istanbul round timeout.
+	remoteSignTimeout = time.Second
+)
+
+var (
+	// errSignerRateLimited is returned if the validator key is asked to sign more
+	// consensus messages than the configured rate limit allows.
+	errSignerRateLimited = errors.New("signer rate limit exceeded")
+	// errInvalidSignHash is returned if the data handed to the signer is not a hash.
+	errInvalidSignHash = errors.New("invalid hash to sign")
+	// errInvalidSignerSignature is returned if the signer produced a malformed
+	// signature or one that doesn't recover to the validator address.
+	errInvalidSignerSignature = errors.New("signer returned invalid signature")
+)
+
+// SignerFn is a signer callback function to request a hash to be signed by a
+// backing account.
+type SignerFn func(accounts.Account, []byte) ([]byte, error)
+
+// NewRemoteSigner returns a signer function requesting signatures from an
+// external signer listening on the given IPC endpoint. The connection is only
+// established on first use, so the node can start before its signer does.
+func NewRemoteSigner(endpoint string) SignerFn {
+	var (
+		client *rpc.Client
+		lock   sync.Mutex
+	)
+	return func(account accounts.Account, hash []byte) ([]byte, error) {
+		ctx, cancel := context.WithTimeout(context.Background(), remoteSignTimeout)
+		defer cancel()
+
+		lock.Lock()
+		if client == nil {
+			c, err := rpc.DialIPC(ctx, endpoint)
+			if err != nil {
+				lock.Unlock()
+				return nil, err
+			}
+			client = c
+		}
+		c := client
+		lock.Unlock()
+
+		var sig hexutil.Bytes
+		if err := c.CallContext(ctx, &sig, remoteSignMethod, account.Address, hexutil.Bytes(hash)); err != nil {
+			return nil, err
+		}
+		return sig, nil
+	}
+}
+
+// signerPolicy wraps a signer function with a local rate limit and sanity checks
+// on everything going in and out of it, so a misbehaving or compromised signer
+// can't make the validator emit garbage.
+type signerPolicy struct {
+	address common.Address
+	signFn  SignerFn
+
+	rate      float64   // Signatures allowed per second, 0 for unlimited
+	allowance float64   // Signatures currently available in the bucket
+	last      time.Time // Last time the bucket was refilled
+	lock      sync.Mutex
+}
+
+// newSignerPolicy creates a signing policy for the given validator address,
+// allowing at most rate signatures per second (0 meaning unlimited).
+func newSignerPolicy(address common.Address, signFn SignerFn, rate uint64) *signerPolicy {
+	return &signerPolicy{
+		address:   address,
+		signFn:    signFn,
+		rate:      float64(rate),
+		allowance: float64(rate),
+		last:      time.Now(),
+	}
+}
+
+// allow reports whether a signature may be produced now, consuming one from the
+// bucket if so.
+func (p *signerPolicy) allow() bool {
+	if p.rate == 0 {
+		return true
+	}
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	now := time.Now()
+	p.allowance += now.Sub(p.last).Seconds() * p.rate
+	if p.allowance > p.rate {
+		p.allowance = p.rate
+	}
+	p.last = now
+
+	if p.allowance < 1 {
+		return false
+	}
+	p.allowance--
+	return true
+}
+
+// sign requests a signature over hash from the underlying signer and verifies
+// that it was produced by the validator key.
+func (p *signerPolicy) sign(hash []byte) ([]byte, error) {
+	if len(hash) != common.HashLength {
+		return nil, errInvalidSignHash
+	}
+	if !p.allow() {
+		return nil, errSignerRateLimited
+	}
+	sig, err := p.signFn(accounts.Account{Address: p.address}, hash)
+	if err != nil {
+		return nil, err
+	}
+	if len(sig) != 65 {
+		return nil, errInvalidSignerSignature
+	}
+	// External signers may return V as 27/28 instead of 0/1
+	sig = common.CopyBytes(sig)
+	if sig[64] >= 27 {
+		sig[64] -= 27
+	}
+	pubkey, err := crypto.SigToPub(hash, sig)
+	if err != nil {
+		return nil, errInvalidSignerSignature
+	}
+	if crypto.PubkeyToAddress(*pubkey) != p.address {
+		return nil, errInvalidSignerSignature
+	}
+	return sig, nil
+}
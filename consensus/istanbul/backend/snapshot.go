@@ -96,6 +96,55 @@ func (s *Snapshot) store(db ethdb.Database) error {
 	return db.Put(append([]byte(dbKeySnapshotPrefix), s.Hash[:]...), blob)
 }
 
+// PruneSnapshots deletes all checkpoint snapshots from the database apart from
+// the genesis one and the given number of most recent ones, returning the number
+// of snapshots deleted. Validators at pruned heights remain available through
+// the validator history.
+func PruneSnapshots(db *ethdb.LDBDatabase, keep uint64) (int, error) {
+	type stored struct {
+		key    []byte
+		number uint64
+	}
+	var (
+		snaps []stored
+		head  uint64
+	)
+	it := db.NewIteratorWithPrefix([]byte(dbKeySnapshotPrefix))
+	for it.Next() {
+		var snap snapshotJSON
+		if err := json.Unmarshal(it.Value(), &snap); err != nil {
+			continue
+		}
+		snaps = append(snaps, stored{common.CopyBytes(it.Key()), snap.Number})
+		if snap.Number > head {
+			head = snap.Number
+		}
+	}
+	it.Release()
+	if err := it.Error(); err != nil {
+		return 0, err
+	}
+
+	batch := db.NewBatch()
+	pruned := 0
+	for _, snap := range snaps {
+		if snap.number == 0 || snap.number+keep*checkpointInterval > head {
+			continue
+		}
+		if err := batch.Delete(snap.key); err != nil {
+			return 0, err
+		}
+		pruned++
+		if batch.ValueSize() >= ethdb.IdealBatchSize {
+			if err := batch.Write(); err != nil {
+				return 0, err
+			}
+			batch.Reset()
+		}
+	}
+	return pruned, batch.Write()
+}
+
 // copy creates a deep copy of the snapshot, though not the individual votes.
 func (s *Snapshot) copy() *Snapshot {
 	cpy := &Snapshot{
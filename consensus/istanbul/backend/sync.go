@@ -0,0 +1,214 @@
+// Copyright 2017 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package backend
+
+import (
+	"bytes"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/consensus/istanbul"
+	istanbulCore "github.com/ethereum/go-ethereum/consensus/istanbul/core"
+	"github.com/ethereum/go-ethereum/consensus/istanbul/validator"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// VerifyHeaderChain implements consensus.HeaderChainVerifier. If validator set
+// sync is enabled, headers are verified against the validator set declared in
+// their own extra-data instead of a snapshot replaying all votes from the last
+// checkpoint. Committed seals are checked whenever requested, whenever the
+// validator set changes and at every epoch boundary. The voting snapshots of
+// epoch headers are stored, so full block processing after the sync resumes
+// from the last epoch instead of replaying the chain from genesis.
+func (sb *backend) VerifyHeaderChain(chain consensus.ChainReader, headers []*types.Header, seals []bool) (chan<- struct{}, <-chan error) {
+	if !sb.config.ValidatorSetSync {
+		return sb.VerifyHeaders(chain, headers, seals)
+	}
+	abort := make(chan struct{})
+	results := make(chan error, len(headers))
+	go func() {
+		for i, header := range headers {
+			err := sb.verifyHeaderTransition(chain, header, headers[:i], seals[i])
+
+			select {
+			case <-abort:
+				return
+			case results <- err:
+			}
+		}
+	}()
+	return abort, results
+}
+
+// verifyHeaderTransition checks a header against the validator set it declares,
+// and any change of that set against the vote of its parent. The caller may optionally pass in a batch of parents (ascending
+// order) to avoid looking those up from the database.
+func (sb *backend) verifyHeaderTransition(chain consensus.ChainReader, header *types.Header, parents []*types.Header, seal bool) error {
+	if err := sb.verifyHeaderFields(header); err != nil {
+		return err
+	}
+	number := header.Number.Uint64()
+	if number == 0 {
+		return nil
+	}
+	var parent *types.Header
+	if len(parents) > 0 {
+		parent = parents[len(parents)-1]
+	} else {
+		parent = chain.GetHeader(header.ParentHash, number-1)
+	}
+	if parent == nil || parent.Number.Uint64() != number-1 || parent.Hash() != header.ParentHash {
+		return consensus.ErrUnknownAncestor
+	}
+	if parent.Time.Uint64()+sb.config.BlockPeriod > header.Time.Uint64() {
+		return errInvalidTimestamp
+	}
+	extra, err := types.ExtractIstanbulExtra(header)
+	if err != nil {
+		return errInvalidExtraDataFormat
+	}
+	parentExtra, err := types.ExtractIstanbulExtra(parent)
+	if err != nil {
+		return errInvalidExtraDataFormat
+	}
+	// The proposer must be one of the validators the header was created by, and
+	// those validators must have committed it
+	signer, err := ecrecover(header)
+	if err != nil {
+		return err
+	}
+	valSet := validator.NewSet(extra.Validators, sb.config.ProposerPolicy)
+	if _, v := valSet.GetByAddress(signer); v == nil {
+		return errUnauthorized
+	}
+	transition := !sameAddresses(extra.Validators, parentExtra.Validators)
+	if seal || transition || number%sb.config.Epoch == 0 {
+		if err := verifyCommittedSealsBy(header, extra, valSet); err != nil {
+			return err
+		}
+	}
+	// A validator set change must be the outcome of the vote cast by the parent,
+	// which was committed by the previous validators
+	if transition {
+		if err := verifyValidatorTransition(parent, parentExtra, extra); err != nil {
+			return err
+		}
+		prevSet := validator.NewSet(parentExtra.Validators, sb.config.ProposerPolicy)
+		if err := verifyCommittedSealsBy(parent, parentExtra, prevSet); err != nil {
+			return err
+		}
+	}
+	if number%sb.config.Epoch == 0 {
+		return sb.storeEpochSnapshot(header, extra)
+	}
+	return nil
+}
+
+// storeEpochSnapshot stores the voting snapshot of a verified epoch header. As
+// votes are reset at epoch boundaries, it only depends on the validators the
+// header declares, which are those of its parent's snapshot, and the vote cast
+// by the header itself.
+func (sb *backend) storeEpochSnapshot(header *types.Header, extra *types.IstanbulExtra) error {
+	number := header.Number.Uint64()
+	parent := newSnapshot(sb.config.Epoch, number-1, header.ParentHash, validator.NewSet(extra.Validators, sb.config.ProposerPolicy))
+	snap, err := parent.apply([]*types.Header{header})
+	if err != nil {
+		return err
+	}
+	sb.recents.Add(snap.Hash, snap)
+	if err := snap.store(sb.db); err != nil {
+		return err
+	}
+	log.Trace("Stored epoch voting snapshot to disk", "number", snap.Number, "hash", snap.Hash)
+	return nil
+}
+
+// verifyValidatorTransition checks whether the validators declared by a header
+// differ from its parent's exactly by the validator the parent voted on.
+func verifyValidatorTransition(parent *types.Header, parentExtra, extra *types.IstanbulExtra) error {
+	authorize := bytes.Equal(parent.Nonce[:], nonceAuthVote)
+
+	want := make(map[common.Address]bool)
+	for _, addr := range parentExtra.Validators {
+		want[addr] = true
+	}
+	if want[parent.Coinbase] == authorize {
+		return errInvalidValidatorTransition
+	}
+	if authorize {
+		want[parent.Coinbase] = true
+	} else {
+		delete(want, parent.Coinbase)
+	}
+	if len(extra.Validators) != len(want) {
+		return errInvalidValidatorTransition
+	}
+	for _, addr := range extra.Validators {
+		if !want[addr] {
+			return errInvalidValidatorTransition
+		}
+	}
+	return nil
+}
+
+// verifyCommittedSealsBy checks whether more than two thirds of the given
+// validators committed the header. Seals of validators outside of the set are
+// ignored.
+func verifyCommittedSealsBy(header *types.Header, extra *types.IstanbulExtra, valSet istanbul.ValidatorSet) error {
+	if len(extra.CommittedSeal) == 0 {
+		return errEmptyCommittedSeals
+	}
+	var (
+		proposalSeal = istanbulCore.PrepareCommittedSeal(header.Hash())
+		sealed       = make(map[common.Address]bool)
+		validSeal    = 0
+	)
+	for _, seal := range extra.CommittedSeal {
+		addr, err := istanbul.GetSignatureAddress(proposalSeal, seal)
+		if err != nil {
+			return errInvalidSignature
+		}
+		// Every validator can have only one seal
+		if sealed[addr] {
+			return errInvalidCommittedSeals
+		}
+		sealed[addr] = true
+
+		if _, v := valSet.GetByAddress(addr); v != nil {
+			validSeal++
+		}
+	}
+	if validSeal <= 2*valSet.F() {
+		return errInvalidCommittedSeals
+	}
+	return nil
+}
+
+// sameAddresses reports whether two address lists contain the same addresses
+// in the same order.
+func sameAddresses(a, b []common.Address) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
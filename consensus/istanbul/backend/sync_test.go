@@ -0,0 +1,239 @@
+// Copyright 2017 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package backend
+
+import (
+	"crypto/ecdsa"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/consensus/istanbul"
+	istanbulCore "github.com/ethereum/go-ethereum/consensus/istanbul/core"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb"
+)
+
+func TestVerifyHeaderChain(t *testing.T) {
+	chain, engine := newBlockChain(1)
+	block := chain.Genesis()
+	headers := make([]*types.Header, 0, 3)
+	for i := 0; i < 3; i++ {
+		block = makeBlock(chain, engine, block)
+		if _, err := chain.InsertChain(types.Blocks{block}); err != nil {
+			t.Fatalf("failed to insert block %d: %v", i, err)
+		}
+		engine.NewChainHead()
+		headers = append(headers, block.Header())
+	}
+	config := *engine.config
+	config.ValidatorSetSync = true
+	config.Epoch = 2
+	engine.config = &config
+
+	// Valid headers pass, storing the voting snapshot of the epoch header only
+	abort, results := engine.VerifyHeaderChain(chain, headers, make([]bool, len(headers)))
+	for i := range headers {
+		if err := <-results; err != nil {
+			t.Errorf("header %d: error mismatch: have %v, want nil", i, err)
+		}
+	}
+	close(abort)
+
+	if _, err := loadSnapshot(config.Epoch, engine.db, headers[0].Hash()); err == nil {
+		t.Errorf("non-epoch voting snapshot stored during verification")
+	}
+	if snap, err := loadSnapshot(config.Epoch, engine.db, headers[1].Hash()); err != nil {
+		t.Errorf("epoch voting snapshot not stored: %v", err)
+	} else if snap.Number != 2 || len(snap.Votes) != 0 || snap.ValSet.Size() != 1 {
+		t.Errorf("epoch snapshot mismatch: have number %d, %d votes, %d validators", snap.Number, len(snap.Votes), snap.ValSet.Size())
+	}
+	// Epoch headers not committed by their validators are rejected
+	key, _ := crypto.GenerateKey()
+	forged := types.CopyHeader(headers[1])
+	seal, _ := crypto.Sign(crypto.Keccak256(istanbulCore.PrepareCommittedSeal(forged.Hash())), key)
+	if err := writeCommittedSeals(forged, [][]byte{seal}); err != nil {
+		t.Fatalf("failed to write committed seals: %v", err)
+	}
+	abort, results = engine.VerifyHeaderChain(chain, []*types.Header{headers[0], forged}, make([]bool, 2))
+	defer close(abort)
+
+	if err := <-results; err != nil {
+		t.Errorf("error mismatch: have %v, want nil", err)
+	}
+	if err := <-results; err != errInvalidCommittedSeals {
+		t.Errorf("error mismatch: have %v, want %v", err, errInvalidCommittedSeals)
+	}
+}
+
+func TestVerifyHeaderChainTransition(t *testing.T) {
+	chain, engine := newBlockChain(1)
+
+	// Vote a new validator in, which the single validator decides on its own
+	candidate := common.StringToAddress("candidate")
+	engine.candidates[candidate] = true
+	voted := makeBlock(chain, engine, chain.Genesis())
+	if _, err := chain.InsertChain(types.Blocks{voted}); err != nil {
+		t.Fatalf("failed to insert block: %v", err)
+	}
+	// The next header declares and is committed by the new validator set
+	header := makeBlockWithoutSeal(chain, engine, voted).Header()
+	extra, _ := types.ExtractIstanbulExtra(header)
+	if len(extra.Validators) != 2 {
+		t.Fatalf("validator count mismatch: have %d, want 2", len(extra.Validators))
+	}
+	signHeader(t, engine, header)
+	time.Sleep(time.Until(time.Unix(header.Time.Int64(), 0)))
+
+	config := *engine.config
+	config.ValidatorSetSync = true
+	engine.config = &config
+
+	abort, results := engine.VerifyHeaderChain(chain, []*types.Header{voted.Header(), header}, []bool{false, false})
+	for i := 0; i < 2; i++ {
+		if err := <-results; err != nil {
+			t.Errorf("header %d: error mismatch: have %v, want nil", i, err)
+		}
+	}
+	close(abort)
+
+	// Validator set changes the parent did not vote for are rejected
+	forged := types.CopyHeader(header)
+	forged.Extra, _ = prepareExtra(forged, []common.Address{engine.Address(), common.StringToAddress("other")})
+	signHeader(t, engine, forged)
+
+	abort, results = engine.VerifyHeaderChain(chain, []*types.Header{voted.Header(), forged}, []bool{false, false})
+	defer close(abort)
+
+	if err := <-results; err != nil {
+		t.Errorf("error mismatch: have %v, want nil", err)
+	}
+	if err := <-results; err != errInvalidValidatorTransition {
+		t.Errorf("error mismatch: have %v, want %v", err, errInvalidValidatorTransition)
+	}
+}
+
+func TestImportAfterHeaderSync(t *testing.T) {
+	genesis, keys := getGenesisAndKeys(1)
+	chain, engine := newTestBlockChain(genesis, istanbul.DefaultConfig, keys[0])
+
+	blocks := make(types.Blocks, 0, 5)
+	block := chain.Genesis()
+	for i := 0; i < 5; i++ {
+		block = makeBlock(chain, engine, block)
+		if _, err := chain.InsertChain(types.Blocks{block}); err != nil {
+			t.Fatalf("failed to insert block %d: %v", i, err)
+		}
+		engine.NewChainHead()
+		blocks = append(blocks, block)
+	}
+	// Fast sync the first four blocks into a fresh node syncing validator sets
+	config := *istanbul.DefaultConfig
+	config.ValidatorSetSync = true
+	config.Epoch = 4
+	key, _ := crypto.GenerateKey()
+	synced, syncer := newTestBlockChain(genesis, &config, key)
+
+	headers := make([]*types.Header, 0, 4)
+	for _, block := range blocks[:4] {
+		headers = append(headers, block.Header())
+	}
+	if _, err := synced.InsertHeaderChain(headers, 1); err != nil {
+		t.Fatalf("failed to insert header chain: %v", err)
+	}
+	if _, err := synced.InsertReceiptChain(blocks[:4], make([]types.Receipts, 4)); err != nil {
+		t.Fatalf("failed to insert receipt chain: %v", err)
+	}
+	if err := synced.FastSyncCommitHead(blocks[3].Hash()); err != nil {
+		t.Fatalf("failed to commit fast sync head: %v", err)
+	}
+	// The first full block is verified from the epoch snapshot stored during the
+	// header sync, without needing any header before it
+	horizon := &chainHorizon{ChainReader: synced, horizon: 4}
+	if err := syncer.VerifyHeader(horizon, blocks[4].Header(), true); err != nil {
+		t.Fatalf("failed to verify first full block: %v", err)
+	}
+	if _, err := synced.InsertChain(blocks[4:]); err != nil {
+		t.Fatalf("failed to import first full block: %v", err)
+	}
+	if head := synced.CurrentBlock(); head.Hash() != blocks[4].Hash() {
+		t.Errorf("head mismatch: have %x, want %x", head.Hash(), blocks[4].Hash())
+	}
+}
+
+// chainHorizon is a chain reader hiding every header below a block number.
+type chainHorizon struct {
+	consensus.ChainReader
+	horizon uint64
+}
+
+func (c *chainHorizon) GetHeader(hash common.Hash, number uint64) *types.Header {
+	if number < c.horizon {
+		return nil
+	}
+	return c.ChainReader.GetHeader(hash, number)
+}
+
+func (c *chainHorizon) GetHeaderByNumber(number uint64) *types.Header {
+	if number < c.horizon {
+		return nil
+	}
+	return c.ChainReader.GetHeaderByNumber(number)
+}
+
+func (c *chainHorizon) GetHeaderByHash(hash common.Hash) *types.Header {
+	if header := c.ChainReader.GetHeaderByHash(hash); header != nil && header.Number.Uint64() >= c.horizon {
+		return header
+	}
+	return nil
+}
+
+// newTestBlockChain creates a chain from the genesis, run by an engine with the
+// given config and key.
+func newTestBlockChain(genesis *core.Genesis, config *istanbul.Config, key *ecdsa.PrivateKey) (*core.BlockChain, *backend) {
+	db := ethdb.NewMemDatabase()
+	engine := New(config, key, db).(*backend)
+	genesis.MustCommit(db)
+	chain, err := core.NewBlockChain(db, nil, genesis.Config, engine, vm.Config{}, nil)
+	if err != nil {
+		panic(err)
+	}
+	engine.Start(chain, chain.CurrentBlock, chain.HasBadBlock)
+	return chain, engine
+}
+
+// signHeader seals and commits a header with the engine's validator key.
+func signHeader(t *testing.T, engine *backend, header *types.Header) {
+	seal, err := engine.Sign(sigHash(header).Bytes())
+	if err != nil {
+		t.Fatalf("failed to sign header: %v", err)
+	}
+	if err := writeSeal(header, seal); err != nil {
+		t.Fatalf("failed to write seal: %v", err)
+	}
+	committed, err := engine.Sign(istanbulCore.PrepareCommittedSeal(header.Hash()))
+	if err != nil {
+		t.Fatalf("failed to sign committed seal: %v", err)
+	}
+	if err := writeCommittedSeals(header, [][]byte{committed}); err != nil {
+		t.Fatalf("failed to write committed seals: %v", err)
+	}
+}
@@ -0,0 +1,355 @@
+// Copyright 2017 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package backend
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/istanbul"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/p2p"
+	"github.com/ethereum/go-ethereum/p2p/enode"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+const (
+	transportName    = "ibft" // Name of the dedicated consensus sub-protocol
+	transportVersion = 1      // Version of the dedicated consensus sub-protocol
+	transportLength  = 2      // Number of message codes of the dedicated consensus sub-protocol
+
+	consensusMsg = 0x00 // Istanbul consensus message, same payload as istanbulMsg on eth
+	announceMsg  = 0x01 // Signed enode announcement of a validator
+
+	transportQueueSize  = 256              // Consensus messages queued per peer before dropping
+	transportMaxMsgSize = 10 * 1024 * 1024 // Maximum size of a single consensus message
+
+	announceInterval = 5 * time.Minute // Time between two enode announcements of a validator
+	announceMaxDrift = time.Minute     // Maximum announcement timestamp in the future accepted
+)
+
+var (
+	// errTransportMsgTooLarge is returned if a peer sends a message exceeding the
+	// maximum consensus message size.
+	errTransportMsgTooLarge = errors.New("consensus message too large")
+	// errInvalidAnnouncement is returned if an enode announcement is malformed,
+	// stale or not signed by a validator.
+	errInvalidAnnouncement = errors.New("invalid enode announcement")
+)
+
+// announcement is the enode of a validator, signed by its validator key, so
+// that validators can find and keep direct connections to each other.
+type announcement struct {
+	Enode     string
+	Timestamp uint64
+	Signature []byte
+}
+
+// sigPayload returns the data covered by the signature of the announcement.
+func (a *announcement) sigPayload() []byte {
+	payload, _ := rlp.EncodeToBytes([]interface{}{a.Enode, a.Timestamp})
+	return payload
+}
+
+// transportPeer is a peer speaking the dedicated consensus sub-protocol. Every
+// peer has its own send queue drained by a dedicated writer, so consensus
+// messages never wait behind block and transaction traffic.
+type transportPeer struct {
+	*p2p.Peer
+	rw    p2p.MsgReadWriter
+	queue chan []byte
+	term  chan struct{}
+}
+
+// Send implements consensus.Peer, queueing Istanbul messages on the consensus
+// lane of the peer.
+func (p *transportPeer) Send(msgcode uint64, data interface{}) error {
+	payload, ok := data.([]byte)
+	if msgcode != istanbulMsg || !ok {
+		return p2p.Send(p.rw, msgcode, data)
+	}
+	select {
+	case p.queue <- payload:
+	default:
+		log.Debug("Dropping consensus message to slow peer", "peer", p.ID())
+	}
+	return nil
+}
+
+// sendLoop writes the queued consensus messages to the peer.
+func (p *transportPeer) sendLoop() {
+	for {
+		select {
+		case payload := <-p.queue:
+			if err := p2p.Send(p.rw, consensusMsg, payload); err != nil {
+				return
+			}
+		case <-p.term:
+			return
+		}
+	}
+}
+
+// transport keeps track of the peers speaking the consensus sub-protocol and
+// the enodes announced by validators.
+type transport struct {
+	server *p2p.Server
+
+	peers     map[common.Address]*transportPeer // Connected peers by validator address
+	addresses map[enode.ID]common.Address       // Validator addresses by announced enode
+	announces map[common.Address]*announcement  // Latest announcement of each validator
+	lock      sync.RWMutex
+}
+
+func newTransport() *transport {
+	return &transport{
+		peers:     make(map[common.Address]*transportPeer),
+		addresses: make(map[enode.ID]common.Address),
+		announces: make(map[common.Address]*announcement),
+	}
+}
+
+// address returns the validator address of a peer, as announced by the
+// validator or else derived from its node key.
+func (t *transport) address(p *p2p.Peer) common.Address {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+
+	if addr, ok := t.addresses[p.ID()]; ok {
+		return addr
+	}
+	if pubkey := p.Node().Pubkey(); pubkey != nil {
+		return crypto.PubkeyToAddress(*pubkey)
+	}
+	return common.Address{}
+}
+
+// findPeers returns the connected consensus peers among the given targets.
+func (t *transport) findPeers(targets map[common.Address]bool) map[common.Address]*transportPeer {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+
+	m := make(map[common.Address]*transportPeer)
+	for addr := range targets {
+		if p, ok := t.peers[addr]; ok {
+			m[addr] = p
+		}
+	}
+	return m
+}
+
+// Protocols implements consensus.Transport, returning the dedicated consensus
+// sub-protocol.
+func (sb *backend) Protocols() []p2p.Protocol {
+	return []p2p.Protocol{{
+		Name:    transportName,
+		Version: transportVersion,
+		Length:  transportLength,
+		Run:     sb.runTransportPeer,
+	}}
+}
+
+// SetServer implements consensus.Transport, using the server to dial the
+// validators announcing their enodes.
+func (sb *backend) SetServer(server *p2p.Server) {
+	sb.transport.lock.Lock()
+	defer sb.transport.lock.Unlock()
+
+	sb.transport.server = server
+}
+
+// runTransportPeer registers a peer speaking the consensus sub-protocol and
+// handles its messages until the connection drops.
+func (sb *backend) runTransportPeer(p *p2p.Peer, rw p2p.MsgReadWriter) error {
+	peer := &transportPeer{
+		Peer:  p,
+		rw:    rw,
+		queue: make(chan []byte, transportQueueSize),
+		term:  make(chan struct{}),
+	}
+	addr := sb.transport.address(p)
+
+	sb.transport.lock.Lock()
+	sb.transport.peers[addr] = peer
+	announces := make([]*announcement, 0, len(sb.transport.announces))
+	for _, a := range sb.transport.announces {
+		announces = append(announces, a)
+	}
+	sb.transport.lock.Unlock()
+
+	defer func() {
+		close(peer.term)
+		sb.transport.lock.Lock()
+		for key, p := range sb.transport.peers {
+			if p == peer {
+				delete(sb.transport.peers, key)
+			}
+		}
+		sb.transport.lock.Unlock()
+	}()
+	go peer.sendLoop()
+
+	// Bring the new peer up to date with the validators we know of
+	for _, a := range announces {
+		if err := p2p.Send(rw, announceMsg, a); err != nil {
+			return err
+		}
+	}
+	for {
+		msg, err := rw.ReadMsg()
+		if err != nil {
+			return err
+		}
+		if msg.Size > transportMaxMsgSize {
+			msg.Discard()
+			return errTransportMsgTooLarge
+		}
+		switch msg.Code {
+		case consensusMsg:
+			msg.Code = istanbulMsg
+			_, err = sb.HandleMsg(addr, msg)
+			if err == istanbul.ErrStoppedEngine {
+				err = nil // Not a validator at the moment, nothing to do
+			}
+		case announceMsg:
+			var a announcement
+			if err = msg.Decode(&a); err == nil {
+				err = sb.handleAnnouncement(p.ID(), &a)
+			}
+		}
+		msg.Discard()
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// handleAnnouncement verifies an enode announcement and, if it's new, relays it
+// to the other consensus peers and dials the validator behind it.
+func (sb *backend) handleAnnouncement(from enode.ID, a *announcement) error {
+	node, err := enode.ParseV4(a.Enode)
+	if err != nil {
+		return errInvalidAnnouncement
+	}
+	if time.Unix(int64(a.Timestamp), 0).After(now().Add(announceMaxDrift)) {
+		return errInvalidAnnouncement
+	}
+	addr, err := istanbul.GetSignatureAddress(a.sigPayload(), a.Signature)
+	if err != nil {
+		return errInvalidAnnouncement
+	}
+	// Only validators of the current head may announce themselves
+	sb.coreMu.RLock()
+	started := sb.coreStarted
+	sb.coreMu.RUnlock()
+	if !started {
+		return nil
+	}
+	head := sb.chain.CurrentHeader()
+	valSet := sb.getValidators(head.Number.Uint64(), head.Hash())
+	if _, v := valSet.GetByAddress(addr); v == nil {
+		log.Debug("Ignoring enode announcement of non-validator", "address", addr, "peer", from)
+		return nil
+	}
+	sb.transport.lock.Lock()
+	if old, ok := sb.transport.announces[addr]; ok && old.Timestamp >= a.Timestamp {
+		sb.transport.lock.Unlock()
+		return nil
+	}
+	sb.transport.announces[addr] = a
+	sb.transport.addresses[node.ID()] = addr
+
+	// Track an already connected validator by its announced address
+	for key, p := range sb.transport.peers {
+		if p.ID() == node.ID() && key != addr {
+			delete(sb.transport.peers, key)
+			sb.transport.peers[addr] = p
+		}
+	}
+
+	server := sb.transport.server
+	relay := make([]*transportPeer, 0, len(sb.transport.peers))
+	for _, p := range sb.transport.peers {
+		if p.ID() != from && p.ID() != node.ID() {
+			relay = append(relay, p)
+		}
+	}
+	sb.transport.lock.Unlock()
+
+	for _, p := range relay {
+		go p2p.Send(p.rw, announceMsg, a)
+	}
+	// Keep a direct connection to every other validator if we're one ourselves
+	if _, v := valSet.GetByAddress(sb.Address()); v != nil && addr != sb.Address() && server != nil {
+		log.Debug("Connecting to announced validator", "address", addr, "enode", node)
+		server.AddPeer(node)
+	}
+	return nil
+}
+
+// announce signs the local enode with the validator key and sends it to all
+// consensus peers.
+func (sb *backend) announce() {
+	sb.transport.lock.RLock()
+	server := sb.transport.server
+	sb.transport.lock.RUnlock()
+	if server == nil {
+		return
+	}
+	a := &announcement{
+		Enode:     server.Self().String(),
+		Timestamp: uint64(now().Unix()),
+	}
+	sig, err := sb.Sign(a.sigPayload())
+	if err != nil {
+		log.Warn("Failed to sign enode announcement", "err", err)
+		return
+	}
+	a.Signature = sig
+
+	sb.transport.lock.Lock()
+	sb.transport.announces[sb.Address()] = a
+	peers := make([]*transportPeer, 0, len(sb.transport.peers))
+	for _, p := range sb.transport.peers {
+		peers = append(peers, p)
+	}
+	sb.transport.lock.Unlock()
+
+	for _, p := range peers {
+		go p2p.Send(p.rw, announceMsg, a)
+	}
+}
+
+// announceLoop periodically announces the local enode while the engine runs.
+func (sb *backend) announceLoop(quit chan struct{}) {
+	sb.announce()
+
+	ticker := time.NewTicker(announceInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			sb.announce()
+		case <-quit:
+			return
+		}
+	}
+}
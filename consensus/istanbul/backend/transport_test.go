@@ -0,0 +1,77 @@
+// Copyright 2017 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package backend
+
+import (
+	"crypto/ecdsa"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/p2p/enode"
+)
+
+func newTestAnnouncement(key *ecdsa.PrivateKey, timestamp time.Time) (*announcement, *enode.Node) {
+	nodeKey, _ := crypto.GenerateKey()
+	node := enode.NewV4(&nodeKey.PublicKey, net.ParseIP("127.0.0.1"), 30303, 30303, 0)
+
+	a := &announcement{
+		Enode:     node.String(),
+		Timestamp: uint64(timestamp.Unix()),
+	}
+	a.Signature, _ = crypto.Sign(crypto.Keccak256(a.sigPayload()), key)
+	return a, node
+}
+
+func TestHandleAnnouncement(t *testing.T) {
+	_, engine := newBlockChain(1)
+
+	// Announcements of validators are accepted and tracked
+	a, node := newTestAnnouncement(engine.privateKey, time.Now())
+	if err := engine.handleAnnouncement(enode.ID{}, a); err != nil {
+		t.Fatalf("error mismatch: have %v, want nil", err)
+	}
+	if addr := engine.transport.addresses[node.ID()]; addr != engine.Address() {
+		t.Errorf("announced address mismatch: have %x, want %x", addr, engine.Address())
+	}
+	if engine.transport.announces[engine.Address()] != a {
+		t.Errorf("announcement not recorded")
+	}
+	// Older announcements are ignored
+	old, oldNode := newTestAnnouncement(engine.privateKey, time.Now().Add(-time.Hour))
+	if err := engine.handleAnnouncement(enode.ID{}, old); err != nil {
+		t.Fatalf("error mismatch: have %v, want nil", err)
+	}
+	if _, ok := engine.transport.addresses[oldNode.ID()]; ok {
+		t.Errorf("stale announcement recorded")
+	}
+	// Announcements of non-validators are ignored
+	key, _ := crypto.GenerateKey()
+	other, otherNode := newTestAnnouncement(key, time.Now())
+	if err := engine.handleAnnouncement(enode.ID{}, other); err != nil {
+		t.Fatalf("error mismatch: have %v, want nil", err)
+	}
+	if _, ok := engine.transport.addresses[otherNode.ID()]; ok {
+		t.Errorf("non-validator announcement recorded")
+	}
+	// Announcements from the future are rejected
+	future, _ := newTestAnnouncement(engine.privateKey, time.Now().Add(time.Hour))
+	if err := engine.handleAnnouncement(enode.ID{}, future); err != errInvalidAnnouncement {
+		t.Errorf("error mismatch: have %v, want %v", err, errInvalidAnnouncement)
+	}
+}
@@ -16,6 +16,8 @@
 
 package istanbul
 
+import "github.com/ethereum/go-ethereum/common"
+
 type ProposerPolicy uint64
 
 const (
@@ -24,10 +26,16 @@ const (
 )
 
 type Config struct {
-	RequestTimeout uint64         `toml:",omitempty"` // The timeout for each Istanbul round in milliseconds.
-	BlockPeriod    uint64         `toml:",omitempty"` // Default minimum difference between two consecutive block's timestamps in second
-	ProposerPolicy ProposerPolicy `toml:",omitempty"` // The policy for proposer selection
-	Epoch          uint64         `toml:",omitempty"` // The number of blocks after which to checkpoint and reset the pending votes
+	RequestTimeout   uint64         `toml:",omitempty"` // The timeout for each Istanbul round in milliseconds.
+	BlockPeriod      uint64         `toml:",omitempty"` // Default minimum difference between two consecutive block's timestamps in second
+	ProposerPolicy   ProposerPolicy `toml:",omitempty"` // The policy for proposer selection
+	Epoch            uint64         `toml:",omitempty"` // The number of blocks after which to checkpoint and reset the pending votes
+	EmptyBlockPeriod uint64         `toml:",omitempty"` // Maximum difference between the timestamps of an empty block and its parent in seconds (0 = disabled)
+	ValidatorSetSync bool           `toml:",omitempty"` // Verify synced header chains by validator set transitions and epoch seals instead of replaying votes
+
+	Validator       common.Address `toml:",omitempty"` // Validator address signing through an account wallet or external signer instead of the node key
+	SignerEndpoint  string         `toml:",omitempty"` // IPC endpoint of an external signer holding the validator key
+	SignerRateLimit uint64         `toml:",omitempty"` // Maximum number of consensus signatures per second by the validator key (0 = unlimited)
 }
 
 var DefaultConfig = &Config{
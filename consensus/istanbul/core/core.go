@@ -331,6 +331,10 @@ func (c *core) newRoundChangeTimer() {
 	round := c.current.Round().Uint64()
 	if round > 0 {
 		timeout += time.Duration(math.Pow(2, float64(round))) * time.Second
+	} else if c.config.EmptyBlockPeriod > c.config.BlockPeriod {
+		// the proposer may hold back an empty block for up to the empty block
+		// period, an idle chain is not a failed round
+		timeout += time.Duration(c.config.EmptyBlockPeriod) * time.Second
 	}
 
 	c.roundChangeTimer = time.AfterFunc(timeout, func() {
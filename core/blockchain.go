@@ -42,6 +42,7 @@ import (
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/metrics"
 	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/private"
 	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/ethereum/go-ethereum/trie"
 	"github.com/hashicorp/golang-lru"
@@ -134,6 +135,8 @@ type BlockChain struct {
 	badBlocks      *lru.Cache              // Bad block cache
 	shouldPreserve func(*types.Block) bool // Function used to determine whether should preserve the given block.
 
+	importGate func(abort <-chan struct{}) bool // Quorum: holds back imports of private transactions, if set
+
 	privateStateCache state.Database // Private state database to reuse between imports (contains state cache)
 }
 
@@ -395,6 +398,11 @@ func (bc *BlockChain) Validator() Validator {
 	return bc.validator
 }
 
+// GetVMConfig returns the block chain VM config.
+func (bc *BlockChain) GetVMConfig() *vm.Config {
+	return &bc.vmConfig
+}
+
 // Processor returns the current processor.
 func (bc *BlockChain) Processor() Processor {
 	bc.procmu.RLock()
@@ -646,10 +654,29 @@ func (bc *BlockChain) GetReceiptsByHash(hash common.Hash) types.Receipts {
 	}
 
 	receipts := rawdb.ReadReceipts(bc.db, hash, *number)
+	if bc.chainConfig.IsQuorum {
+		markPrivateLogs(receipts, rawdb.ReadBody(bc.db, hash, *number))
+	}
 	bc.receiptsCache.Add(hash, receipts)
 	return receipts
 }
 
+// markPrivateLogs flags the logs of the receipts of private transactions as
+// private, as the flag isn't part of the stored logs.
+func markPrivateLogs(receipts types.Receipts, body *types.Body) {
+	if body == nil || len(body.Transactions) != len(receipts) {
+		return
+	}
+	for i, tx := range body.Transactions {
+		if !tx.IsPrivate() {
+			continue
+		}
+		for _, l := range receipts[i].Logs {
+			l.IsPrivate = true
+		}
+	}
+}
+
 // GetBlocksFromHash returns the block corresponding to hash and up to n-1 ancestors.
 // [deprecated by eth/62]
 func (bc *BlockChain) GetBlocksFromHash(hash common.Hash, n int) (blocks []*types.Block) {
@@ -1069,11 +1096,37 @@ func (bc *BlockChain) WriteBlockWithState(block *types.Block, receipts []*types.
 //
 // After insertion is done, all accumulated events will be fired.
 func (bc *BlockChain) InsertChain(chain types.Blocks) (int, error) {
+	if bc.importGate != nil && hasPrivateTransactions(chain) && !bc.importGate(bc.quit) {
+		log.Debug("Premature abort while holding back private transactions")
+		return 0, nil
+	}
 	n, events, logs, err := bc.insertChain(chain)
 	bc.PostChainEvents(events, logs)
 	return n, err
 }
 
+// SetImportGate installs a function blocking the import of chains containing
+// private transactions until it returns true, e.g. while the private transaction
+// manager is unreachable and processing them would diverge the private state.
+// Imports are aborted if it returns false, which it has to as soon as the
+// abort channel is closed. It must be set before blocks are imported.
+func (bc *BlockChain) SetImportGate(gate func(abort <-chan struct{}) bool) {
+	bc.importGate = gate
+}
+
+// hasPrivateTransactions reports whether any block of the chain contains a
+// private transaction.
+func hasPrivateTransactions(chain types.Blocks) bool {
+	for _, block := range chain {
+		for _, tx := range block.Transactions() {
+			if tx.IsPrivate() {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 // Given a slice of public receipts and an overlapping (smaller) slice of
 // private receipts, return a new slice where the default for each location is
 // the public receipt but we take the private receipt in each place we have
@@ -1252,6 +1305,11 @@ func (bc *BlockChain) insertChain(chain types.Blocks) (int, []interface{}, []*ty
 
 		// Process block using the parent state as reference point.
 		receipts, privateReceipts, logs, usedGas, err := bc.processor.Process(block, state, privateState, bc.vmConfig)
+		if err == private.ErrTransactionManagerDown {
+			// Not a bad block, it can be processed once the payloads are available
+			log.Warn("Aborted block import while the private transaction manager is down", "number", block.Number(), "hash", block.Hash())
+			return i, events, coalescedLogs, err
+		}
 		if err != nil {
 			bc.reportBlock(block, receipts, err)
 			return i, events, coalescedLogs, err
@@ -0,0 +1,400 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"encoding/json"
+	"errors"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/private"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// ContractExtensionAddress is the address of the native management contract
+// for private contract extensions. Private transactions sent to it are not
+// executed by the EVM, their payload is decoded as an ExtensionAction instead.
+var ContractExtensionAddress = common.HexToAddress("0x000000000000000000000000000000000000ec01")
+
+var (
+	// ExtensionProposedTopic is logged when a contract extension is proposed.
+	ExtensionProposedTopic = crypto.Keccak256Hash([]byte("ExtensionProposed(address)"))
+	// ExtensionApprovedTopic is logged when all voters approved an extension.
+	ExtensionApprovedTopic = crypto.Keccak256Hash([]byte("ExtensionApproved(address)"))
+	// ExtensionClosedTopic is logged when an extension is rejected, cancelled or
+	// its state has been shared.
+	ExtensionClosedTopic = crypto.Keccak256Hash([]byte("ExtensionClosed(address)"))
+
+	extensionCountSlot = crypto.Keccak256Hash([]byte("extensions"))
+)
+
+var (
+	errExtensionInvalid      = errors.New("invalid contract extension action")
+	errExtensionExists       = errors.New("contract extension already in progress")
+	errExtensionUnknown      = errors.New("no contract extension in progress")
+	errExtensionUnauthorized = errors.New("sender not allowed to act on contract extension")
+	errExtensionVoted        = errors.New("sender already voted on contract extension")
+)
+
+// ExtensionOp is the operation requested by an ExtensionAction.
+type ExtensionOp uint8
+
+const (
+	ExtensionPropose ExtensionOp = iota + 1 // Propose a new party for a contract
+	ExtensionVote                           // Approve or reject a proposal
+	ExtensionCancel                         // Withdraw a proposal
+	ExtensionShare                          // Record the snapshot sent to the new party
+)
+
+// ExtensionStatus is the state of a contract extension.
+type ExtensionStatus uint8
+
+const (
+	ExtensionPending   ExtensionStatus = iota + 1 // Waiting for votes
+	ExtensionApproved                             // Approved, waiting for the state snapshot
+	ExtensionRejected                             // Rejected by one of the voters
+	ExtensionCancelled                            // Withdrawn by the proposer
+	ExtensionCompleted                            // State snapshot shared with the new party
+)
+
+func (s ExtensionStatus) String() string {
+	switch s {
+	case ExtensionPending:
+		return "pending"
+	case ExtensionApproved:
+		return "approved"
+	case ExtensionRejected:
+		return "rejected"
+	case ExtensionCancelled:
+		return "cancelled"
+	case ExtensionCompleted:
+		return "completed"
+	default:
+		return "unknown"
+	}
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (s ExtensionStatus) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// ExtensionAction is the payload of a private transaction to the extension
+// management contract.
+type ExtensionAction struct {
+	Op           ExtensionOp
+	Contract     common.Address
+	Recipient    common.Address   // Propose: account of the new party
+	RecipientKey string           // Propose: transaction manager key of the new party
+	ProposerKey  string           // Propose: transaction manager key of the proposer
+	Parties      []string         // Propose: transaction manager keys management transactions are shared with
+	Voters       []common.Address // Propose: account approving for each of the parties, in the same order
+	Approve      bool             // Vote: whether the sender approves the extension
+	SnapshotHash []byte           // Share: transaction manager hash of the state snapshot
+}
+
+// ContractExtension is the management record of a contract extension, kept in
+// the private state of all parties involved.
+type ContractExtension struct {
+	Contract      common.Address   `json:"contract"`
+	Proposer      common.Address   `json:"proposer"`
+	ProposerKey   string           `json:"proposerKey"`
+	Recipient     common.Address   `json:"recipient"`
+	RecipientKey  string           `json:"recipientKey"`
+	Parties       []string         `json:"parties"`
+	Voters        []common.Address `json:"voters"`
+	Votes         []common.Address `json:"votes"`
+	Status        ExtensionStatus  `json:"status"`
+	ApprovedBlock uint64           `json:"approvedBlock"`
+	SnapshotHash  []byte           `json:"snapshotHash"`
+}
+
+// Active reports whether the extension still awaits votes or its snapshot.
+func (ext *ContractExtension) Active() bool {
+	return ext.Status == ExtensionPending || ext.Status == ExtensionApproved
+}
+
+func (ext *ContractExtension) isVoter(addr common.Address) bool {
+	for _, voter := range ext.Voters {
+		if voter == addr {
+			return true
+		}
+	}
+	return false
+}
+
+func (ext *ContractExtension) hasVoted(addr common.Address) bool {
+	for _, vote := range ext.Votes {
+		if vote == addr {
+			return true
+		}
+	}
+	return false
+}
+
+// extensionSlot returns the storage slot holding the i-th word of the record of
+// the given contract. Word 0 holds the length of the encoded record.
+func extensionSlot(contract common.Address, i uint64) common.Hash {
+	return crypto.Keccak256Hash(contract.Bytes(), new(big.Int).SetUint64(i).Bytes())
+}
+
+// extensionIndexSlot returns the storage slot holding the i-th contract ever
+// proposed for extension.
+func extensionIndexSlot(i uint64) common.Hash {
+	return crypto.Keccak256Hash(extensionCountSlot.Bytes(), new(big.Int).SetUint64(i).Bytes())
+}
+
+// ReadContractExtension retrieves the extension record of a contract from the
+// given private state, or nil if none was ever proposed.
+func ReadContractExtension(db vm.MinimalApiState, contract common.Address) *ContractExtension {
+	size := db.GetState(ContractExtensionAddress, extensionSlot(contract, 0)).Big().Uint64()
+	if size == 0 {
+		return nil
+	}
+	blob := make([]byte, 0, size+common.HashLength)
+	for i := uint64(1); uint64(len(blob)) < size; i++ {
+		blob = append(blob, db.GetState(ContractExtensionAddress, extensionSlot(contract, i)).Bytes()...)
+	}
+	ext := new(ContractExtension)
+	if err := rlp.DecodeBytes(blob[:size], ext); err != nil {
+		log.Error("Invalid contract extension record", "contract", contract, "err", err)
+		return nil
+	}
+	return ext
+}
+
+// ReadContractExtensions retrieves all extension records from the given private
+// state, in the order they were proposed.
+func ReadContractExtensions(db vm.MinimalApiState) []*ContractExtension {
+	count := db.GetState(ContractExtensionAddress, extensionCountSlot).Big().Uint64()
+
+	exts := make([]*ContractExtension, 0, count)
+	for i := uint64(0); i < count; i++ {
+		contract := common.BytesToAddress(db.GetState(ContractExtensionAddress, extensionIndexSlot(i)).Bytes())
+		if ext := ReadContractExtension(db, contract); ext != nil {
+			exts = append(exts, ext)
+		}
+	}
+	return exts
+}
+
+func writeContractExtension(db vm.StateDB, ext *ContractExtension) {
+	blob, err := rlp.EncodeToBytes(ext)
+	if err != nil {
+		log.Crit("Failed to RLP encode contract extension", "err", err)
+	}
+	if db.GetState(ContractExtensionAddress, extensionSlot(ext.Contract, 0)) == (common.Hash{}) {
+		count := db.GetState(ContractExtensionAddress, extensionCountSlot).Big().Uint64()
+		db.SetState(ContractExtensionAddress, extensionIndexSlot(count), ext.Contract.Hash())
+		db.SetState(ContractExtensionAddress, extensionCountSlot, common.BigToHash(new(big.Int).SetUint64(count+1)))
+	}
+	db.SetState(ContractExtensionAddress, extensionSlot(ext.Contract, 0), common.BigToHash(new(big.Int).SetUint64(uint64(len(blob)))))
+	for i := 0; i < len(blob); i += common.HashLength {
+		word := make([]byte, common.HashLength)
+		copy(word, blob[i:])
+		db.SetState(ContractExtensionAddress, extensionSlot(ext.Contract, uint64(i/common.HashLength)+1), common.BytesToHash(word))
+	}
+}
+
+func logContractExtension(db vm.StateDB, number *big.Int, topic common.Hash, contract common.Address) {
+	db.AddLog(&types.Log{
+		Address:     ContractExtensionAddress,
+		Topics:      []common.Hash{topic, contract.Hash()},
+		BlockNumber: number.Uint64(),
+	})
+}
+
+// applyContractExtension executes an extension management action sent by the
+// given account against the private state of the EVM. The returned error is an
+// execution error, failing the private transaction but not the block.
+func applyContractExtension(evm *vm.EVM, from common.Address, data []byte) error {
+	var action ExtensionAction
+	if err := rlp.DecodeBytes(data, &action); err != nil {
+		return errExtensionInvalid
+	}
+	db := evm.PrivateState()
+	ext := ReadContractExtension(db, action.Contract)
+
+	switch action.Op {
+	case ExtensionPropose:
+		if ext != nil && ext.Active() {
+			return errExtensionExists
+		}
+		if err := validateExtensionVoters(from, &action); err != nil {
+			return err
+		}
+		ext = &ContractExtension{
+			Contract:     action.Contract,
+			Proposer:     from,
+			ProposerKey:  action.ProposerKey,
+			Recipient:    action.Recipient,
+			RecipientKey: action.RecipientKey,
+			Parties:      action.Parties,
+			Voters:       []common.Address{from},
+			Votes:        []common.Address{from},
+			Status:       ExtensionPending,
+		}
+		for _, voter := range action.Voters {
+			if !ext.isVoter(voter) {
+				ext.Voters = append(ext.Voters, voter)
+			}
+		}
+		writeContractExtension(db, ext)
+		logContractExtension(db, evm.BlockNumber, ExtensionProposedTopic, ext.Contract)
+
+	case ExtensionVote:
+		if ext == nil || ext.Status != ExtensionPending {
+			return errExtensionUnknown
+		}
+		if !ext.isVoter(from) {
+			return errExtensionUnauthorized
+		}
+		if ext.hasVoted(from) {
+			return errExtensionVoted
+		}
+		if !action.Approve {
+			ext.Status = ExtensionRejected
+			writeContractExtension(db, ext)
+			logContractExtension(db, evm.BlockNumber, ExtensionClosedTopic, ext.Contract)
+			break
+		}
+		ext.Votes = append(ext.Votes, from)
+		if len(ext.Votes) == len(ext.Voters) {
+			ext.Status = ExtensionApproved
+			ext.ApprovedBlock = evm.BlockNumber.Uint64()
+			writeContractExtension(db, ext)
+			logContractExtension(db, evm.BlockNumber, ExtensionApprovedTopic, ext.Contract)
+			break
+		}
+		writeContractExtension(db, ext)
+
+	case ExtensionCancel:
+		if ext == nil || !ext.Active() {
+			return errExtensionUnknown
+		}
+		if from != ext.Proposer {
+			return errExtensionUnauthorized
+		}
+		ext.Status = ExtensionCancelled
+		writeContractExtension(db, ext)
+		logContractExtension(db, evm.BlockNumber, ExtensionClosedTopic, ext.Contract)
+
+	case ExtensionShare:
+		if ext == nil || ext.Status != ExtensionApproved {
+			return errExtensionUnknown
+		}
+		if from != ext.Proposer {
+			return errExtensionUnauthorized
+		}
+		ext.Status = ExtensionCompleted
+		ext.SnapshotHash = action.SnapshotHash
+		writeContractExtension(db, ext)
+		logContractExtension(db, evm.BlockNumber, ExtensionClosedTopic, ext.Contract)
+
+		// Only the new party can decrypt the snapshot, everyone else is done
+		if err := restoreContractSnapshot(db, ext); err != nil {
+			log.Error("Failed to apply extended contract state", "contract", ext.Contract, "err", err)
+		}
+
+	default:
+		return errExtensionInvalid
+	}
+	return nil
+}
+
+// validateExtensionVoters checks that a proposal names an approving account for
+// every party it is shared with, the proposer approving for its own key and the
+// new party for the recipient key, so that no current party can be left out of
+// the vote.
+func validateExtensionVoters(from common.Address, action *ExtensionAction) error {
+	if action.RecipientKey == "" || action.ProposerKey == "" || action.ProposerKey == action.RecipientKey {
+		return errExtensionInvalid
+	}
+	if len(action.Voters) != len(action.Parties) {
+		return errExtensionInvalid
+	}
+	seen := make(map[string]bool)
+	for i, party := range action.Parties {
+		voter := action.Voters[i]
+		if seen[party] || voter == (common.Address{}) {
+			return errExtensionInvalid
+		}
+		seen[party] = true
+
+		switch party {
+		case action.ProposerKey:
+			if voter != from {
+				return errExtensionInvalid
+			}
+		case action.RecipientKey:
+			if voter != action.Recipient {
+				return errExtensionInvalid
+			}
+		}
+	}
+	if !seen[action.ProposerKey] || !seen[action.RecipientKey] {
+		return errExtensionInvalid
+	}
+	return nil
+}
+
+// restoreContractSnapshot fetches the state snapshot of an extended contract
+// from the transaction manager and applies it to the private state, if this
+// node is the recipient and doesn't know the contract yet.
+func restoreContractSnapshot(db vm.StateDB, ext *ContractExtension) error {
+	if private.P == nil || len(ext.SnapshotHash) == 0 {
+		return nil
+	}
+	payload, err := private.P.Receive(ext.SnapshotHash)
+	if err != nil || len(payload) == 0 {
+		return err
+	}
+	if db.GetCodeSize(ext.Contract) != 0 {
+		log.Warn("Ignoring snapshot of already known private contract", "contract", ext.Contract)
+		return nil
+	}
+	restorer, ok := db.(interface {
+		RestoreAccount(common.Address, state.DumpAccount) error
+	})
+	if !ok {
+		return errors.New("private state does not support restoring accounts")
+	}
+	var account state.DumpAccount
+	if err := json.Unmarshal(payload, &account); err != nil {
+		return err
+	}
+	if err := restorer.RestoreAccount(ext.Contract, account); err != nil {
+		return err
+	}
+	log.Info("Applied extended private contract state", "contract", ext.Contract, "proposer", ext.Proposer)
+	return nil
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
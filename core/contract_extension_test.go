@@ -0,0 +1,128 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+func TestContractExtensionVoting(t *testing.T) {
+	var (
+		contract  = common.Address{0xc0}
+		proposer  = common.Address{0x01}
+		recipient = common.Address{0x02}
+		voter     = common.Address{0x03}
+		outsider  = common.Address{0x04}
+	)
+	db := ethdb.NewMemDatabase()
+	publicState, _ := state.New(common.Hash{}, state.NewDatabase(db))
+	privateState, _ := state.New(common.Hash{}, state.NewDatabase(db))
+	evm := vm.NewEVM(vm.Context{BlockNumber: big.NewInt(7)}, publicState, privateState, params.QuorumTestChainConfig, vm.Config{})
+
+	apply := func(from common.Address, action *ExtensionAction) error {
+		data, err := rlp.EncodeToBytes(action)
+		if err != nil {
+			t.Fatalf("failed to encode action: %v", err)
+		}
+		return applyContractExtension(evm, from, data)
+	}
+	propose := &ExtensionAction{
+		Op:           ExtensionPropose,
+		Contract:     contract,
+		Recipient:    recipient,
+		RecipientKey: "recipient",
+		ProposerKey:  "proposer",
+		Parties:      []string{"proposer", "voter", "recipient"},
+		Voters:       []common.Address{proposer, voter, recipient},
+	}
+	// Proposals must name an approving account for every party
+	for i, voters := range [][]common.Address{
+		nil,
+		{proposer, recipient},
+		{proposer, voter, outsider},
+		{outsider, voter, recipient},
+		{proposer, {}, recipient},
+	} {
+		invalid := *propose
+		invalid.Voters = voters
+		if err := apply(proposer, &invalid); err != errExtensionInvalid {
+			t.Fatalf("proposal %d with voters %x: error mismatch: have %v, want %v", i, voters, err, errExtensionInvalid)
+		}
+	}
+	if err := apply(proposer, propose); err != nil {
+		t.Fatalf("failed to propose extension: %v", err)
+	}
+	if err := apply(proposer, propose); err != errExtensionExists {
+		t.Fatalf("duplicate proposal error mismatch: have %v, want %v", err, errExtensionExists)
+	}
+	vote := &ExtensionAction{Op: ExtensionVote, Contract: contract, Approve: true}
+	if err := apply(outsider, vote); err != errExtensionUnauthorized {
+		t.Fatalf("outsider vote error mismatch: have %v, want %v", err, errExtensionUnauthorized)
+	}
+	if err := apply(proposer, vote); err != errExtensionVoted {
+		t.Fatalf("proposer vote error mismatch: have %v, want %v", err, errExtensionVoted)
+	}
+	if err := apply(voter, vote); err != nil {
+		t.Fatalf("failed to vote: %v", err)
+	}
+	if ext := ReadContractExtension(privateState, contract); ext.Status != ExtensionPending {
+		t.Fatalf("status mismatch after partial votes: have %v, want %v", ext.Status, ExtensionPending)
+	}
+	if err := apply(recipient, vote); err != nil {
+		t.Fatalf("failed to vote: %v", err)
+	}
+	ext := ReadContractExtension(privateState, contract)
+	if ext.Status != ExtensionApproved || ext.ApprovedBlock != 7 {
+		t.Fatalf("approval mismatch: have %v at %d, want %v at 7", ext.Status, ext.ApprovedBlock, ExtensionApproved)
+	}
+	share := &ExtensionAction{Op: ExtensionShare, Contract: contract, SnapshotHash: []byte{0x01}}
+	if err := apply(voter, share); err != errExtensionUnauthorized {
+		t.Fatalf("non-proposer share error mismatch: have %v, want %v", err, errExtensionUnauthorized)
+	}
+	if err := apply(proposer, share); err != nil {
+		t.Fatalf("failed to share snapshot: %v", err)
+	}
+	if ext := ReadContractExtension(privateState, contract); ext.Status != ExtensionCompleted {
+		t.Fatalf("status mismatch after sharing: have %v, want %v", ext.Status, ExtensionCompleted)
+	}
+	// A completed extension makes room for a new proposal, which can be cancelled
+	if err := apply(proposer, propose); err != nil {
+		t.Fatalf("failed to propose extension again: %v", err)
+	}
+	cancel := &ExtensionAction{Op: ExtensionCancel, Contract: contract}
+	if err := apply(voter, cancel); err != errExtensionUnauthorized {
+		t.Fatalf("non-proposer cancel error mismatch: have %v, want %v", err, errExtensionUnauthorized)
+	}
+	if err := apply(proposer, cancel); err != nil {
+		t.Fatalf("failed to cancel extension: %v", err)
+	}
+	exts := ReadContractExtensions(privateState)
+	if len(exts) != 1 || exts[0].Status != ExtensionCancelled {
+		t.Fatalf("extension list mismatch: have %v", exts)
+	}
+	if logs := privateState.Logs(); len(logs) != 5 {
+		t.Fatalf("log count mismatch: have %d, want 5", len(logs))
+	}
+}
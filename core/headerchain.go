@@ -228,7 +228,15 @@ func (hc *HeaderChain) ValidateHeaderChain(chain []*types.Header, checkFreq int)
 	}
 	seals[len(seals)-1] = true // Last should always be verified to avoid junk
 
-	abort, results := hc.engine.VerifyHeaders(hc, chain, seals)
+	var (
+		abort   chan<- struct{}
+		results <-chan error
+	)
+	if verifier, ok := hc.engine.(consensus.HeaderChainVerifier); ok {
+		abort, results = verifier.VerifyHeaderChain(hc, chain, seals)
+	} else {
+		abort, results = hc.engine.VerifyHeaders(hc, chain, seals)
+	}
 	defer close(abort)
 
 	// Iterate over the headers and ensure they all check out
@@ -317,3 +317,76 @@ func TestBlockReceiptStorage(t *testing.T) {
 		t.Fatalf("deleted receipts returned: %v", rs)
 	}
 }
+
+// Tests that revert reasons are stored along with the receipts recording them.
+func TestBlockReceiptRevertReasonStorage(t *testing.T) {
+	db := ethdb.NewMemDatabase()
+
+	reverted := &types.Receipt{
+		Status:            types.ReceiptStatusFailed,
+		CumulativeGasUsed: 1,
+		TxHash:            common.BytesToHash([]byte{0x11, 0x11}),
+		GasUsed:           111111,
+		RevertData:        common.Hex2Bytes("08c379a0"),
+	}
+	succeeded := &types.Receipt{
+		Status:            types.ReceiptStatusSuccessful,
+		CumulativeGasUsed: 2,
+		TxHash:            common.BytesToHash([]byte{0x22, 0x22}),
+		GasUsed:           222222,
+	}
+	hash := common.BytesToHash([]byte{0x03, 0x14})
+	WriteReceipts(db, hash, 0, []*types.Receipt{reverted, succeeded})
+
+	rs := ReadReceipts(db, hash, 0)
+	if len(rs) != 2 {
+		t.Fatalf("receipt count mismatch: have %d, want 2", len(rs))
+	}
+	if !bytes.Equal(rs[0].RevertData, reverted.RevertData) {
+		t.Errorf("revert data mismatch: have %x, want %x", rs[0].RevertData, reverted.RevertData)
+	}
+	if rs[0].GasUsed != reverted.GasUsed || rs[0].TxHash != reverted.TxHash {
+		t.Errorf("reverted receipt mismatch: have %v, want %v", rs[0], reverted)
+	}
+	if len(rs[1].RevertData) != 0 || rs[1].GasUsed != succeeded.GasUsed {
+		t.Errorf("successful receipt mismatch: have %v, want %v", rs[1], succeeded)
+	}
+}
+
+// Tests that private state checksums survive the receipt storage round trip,
+// next to receipts stored without one.
+func TestBlockReceiptPrivateStateChecksumStorage(t *testing.T) {
+	db := ethdb.NewMemDatabase()
+
+	checksum := common.BytesToHash([]byte{0xc5})
+	private := &types.Receipt{
+		Status:               types.ReceiptStatusFailed,
+		CumulativeGasUsed:    1,
+		TxHash:               common.BytesToHash([]byte{0x11, 0x11}),
+		GasUsed:              111111,
+		RevertData:           common.Hex2Bytes("08c379a0"),
+		PrivateStateChecksum: &checksum,
+	}
+	public := &types.Receipt{
+		Status:            types.ReceiptStatusSuccessful,
+		CumulativeGasUsed: 2,
+		TxHash:            common.BytesToHash([]byte{0x22, 0x22}),
+		GasUsed:           222222,
+	}
+	hash := common.BytesToHash([]byte{0x03, 0x15})
+	WriteReceipts(db, hash, 0, []*types.Receipt{private, public})
+
+	rs := ReadReceipts(db, hash, 0)
+	if len(rs) != 2 {
+		t.Fatalf("receipt count mismatch: have %d, want 2", len(rs))
+	}
+	if rs[0].PrivateStateChecksum == nil || *rs[0].PrivateStateChecksum != checksum {
+		t.Errorf("checksum mismatch: have %v, want %x", rs[0].PrivateStateChecksum, checksum)
+	}
+	if !bytes.Equal(rs[0].RevertData, private.RevertData) || rs[0].Status != private.Status {
+		t.Errorf("private receipt mismatch: have %v, want %v", rs[0], private)
+	}
+	if rs[1].PrivateStateChecksum != nil {
+		t.Errorf("unexpected checksum on public receipt: %x", *rs[1].PrivateStateChecksum)
+	}
+}
@@ -19,6 +19,7 @@ package state
 import (
 	"encoding/json"
 	"fmt"
+	"math/big"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/rlp"
@@ -79,3 +80,46 @@ func (self *StateDB) Dump() []byte {
 
 	return json
 }
+
+// DumpAddress returns the committed state of a single account, or false if the
+// account does not exist.
+func (self *StateDB) DumpAddress(addr common.Address) (DumpAccount, bool) {
+	obj := self.getStateObject(addr)
+	if obj == nil {
+		return DumpAccount{}, false
+	}
+	account := DumpAccount{
+		Balance:  obj.data.Balance.String(),
+		Nonce:    obj.data.Nonce,
+		Root:     common.Bytes2Hex(obj.data.Root[:]),
+		CodeHash: common.Bytes2Hex(obj.data.CodeHash),
+		Code:     common.Bytes2Hex(obj.Code(self.db)),
+		Storage:  make(map[string]string),
+	}
+	storageIt := trie.NewIterator(obj.getTrie(self.db).NodeIterator(nil))
+	for storageIt.Next() {
+		account.Storage[common.Bytes2Hex(self.trie.GetKey(storageIt.Key))] = common.Bytes2Hex(storageIt.Value)
+	}
+	return account, true
+}
+
+// RestoreAccount recreates an account from its dump, overwriting any existing
+// account at the given address.
+func (self *StateDB) RestoreAccount(addr common.Address, account DumpAccount) error {
+	balance, ok := new(big.Int).SetString(account.Balance, 10)
+	if !ok {
+		return fmt.Errorf("invalid balance %q", account.Balance)
+	}
+	self.CreateAccount(addr)
+	self.SetBalance(addr, balance)
+	self.SetNonce(addr, account.Nonce)
+	self.SetCode(addr, common.FromHex(account.Code))
+	for key, value := range account.Storage {
+		_, content, _, err := rlp.Split(common.FromHex(value))
+		if err != nil {
+			return fmt.Errorf("invalid storage value for key %s: %v", key, err)
+		}
+		self.SetState(addr, common.HexToHash(key), common.BytesToHash(content))
+	}
+	return nil
+}
@@ -18,6 +18,7 @@
 package state
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"math/big"
@@ -26,6 +27,7 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/crypto/sha3"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/ethereum/go-ethereum/trie"
@@ -612,6 +614,39 @@ func (s *StateDB) IntermediateRoot(deleteEmptyObjects bool) common.Hash {
 	return s.trie.Hash()
 }
 
+// DirtyAccounts returns the sorted addresses of the accounts modified since the
+// state was last finalised.
+func (s *StateDB) DirtyAccounts() []common.Address {
+	addrs := make([]common.Address, 0, len(s.journal.dirties))
+	for addr := range s.journal.dirties {
+		if _, exist := s.stateObjects[addr]; exist {
+			addrs = append(addrs, addr)
+		}
+	}
+	sort.Slice(addrs, func(i, j int) bool { return bytes.Compare(addrs[i][:], addrs[j][:]) < 0 })
+	return addrs
+}
+
+// AccountsChecksum returns a hash over the finalised state of the given
+// accounts, including their storage roots, or the zero hash if no accounts are
+// given. Nodes holding the same state of these accounts compute the same
+// checksum, regardless of the rest of their state.
+func (s *StateDB) AccountsChecksum(addrs []common.Address) common.Hash {
+	if len(addrs) == 0 {
+		return common.Hash{}
+	}
+	hasher := sha3.NewKeccak256()
+	for _, addr := range addrs {
+		hasher.Write(addr[:])
+		if obj := s.getStateObject(addr); obj != nil {
+			rlp.Encode(hasher, obj.data)
+		}
+	}
+	var checksum common.Hash
+	hasher.Sum(checksum[:0])
+	return checksum
+}
+
 // Prepare sets the current transaction hash and index and block hash which is
 // used when the EVM emits new state logs.
 func (self *StateDB) Prepare(thash, bhash common.Hash, ti int) {
@@ -466,3 +466,89 @@ func TestCopyOfCopy(t *testing.T) {
 		t.Fatalf("2nd copy fail, expected 42, got %v", got)
 	}
 }
+
+// Tests that a dumped account can be restored into another state, which is used
+// to share the state of a private contract with a new party.
+func TestDumpAndRestoreAccount(t *testing.T) {
+	db := NewDatabase(ethdb.NewMemDatabase())
+	orig, _ := New(common.Hash{}, db)
+
+	addr := common.BytesToAddress([]byte{0x01})
+	orig.SetBalance(addr, big.NewInt(42))
+	orig.SetNonce(addr, 3)
+	orig.SetCode(addr, []byte{0x60, 0x00})
+	orig.SetState(addr, common.Hash{0x01}, common.Hash{0x02})
+	orig.SetState(addr, common.Hash{0x03}, common.BigToHash(big.NewInt(4)))
+	root, _ := orig.Commit(false)
+
+	committed, _ := New(root, db)
+	if _, ok := committed.DumpAddress(common.BytesToAddress([]byte{0x02})); ok {
+		t.Fatalf("dumped non-existent account")
+	}
+	account, ok := committed.DumpAddress(addr)
+	if !ok {
+		t.Fatalf("failed to dump account")
+	}
+	restored, _ := New(common.Hash{}, NewDatabase(ethdb.NewMemDatabase()))
+	if err := restored.RestoreAccount(addr, account); err != nil {
+		t.Fatalf("failed to restore account: %v", err)
+	}
+	if have := restored.GetBalance(addr); have.Cmp(big.NewInt(42)) != 0 {
+		t.Errorf("balance mismatch: have %v, want 42", have)
+	}
+	if have := restored.GetNonce(addr); have != 3 {
+		t.Errorf("nonce mismatch: have %d, want 3", have)
+	}
+	if have := restored.GetCode(addr); !bytes.Equal(have, []byte{0x60, 0x00}) {
+		t.Errorf("code mismatch: have %x, want 6000", have)
+	}
+	for _, key := range []common.Hash{{0x01}, {0x03}} {
+		if have, want := restored.GetState(addr, key), committed.GetState(addr, key); have != want {
+			t.Errorf("storage %x mismatch: have %x, want %x", key, have, want)
+		}
+	}
+	restoredRoot, _ := restored.Commit(false)
+	if restoredRoot != root {
+		t.Errorf("state root mismatch: have %x, want %x", restoredRoot, root)
+	}
+}
+
+// Tests that the checksum over modified accounts only depends on the state of
+// those accounts, not on the rest of the state.
+func TestAccountsChecksum(t *testing.T) {
+	var (
+		addr  = common.BytesToAddress([]byte{0x01})
+		other = common.BytesToAddress([]byte{0x02})
+	)
+	a, _ := New(common.Hash{}, NewDatabase(ethdb.NewMemDatabase()))
+	b, _ := New(common.Hash{}, NewDatabase(ethdb.NewMemDatabase()))
+	b.SetBalance(other, big.NewInt(1))
+	b.Finalise(true)
+
+	if dirty := b.DirtyAccounts(); len(dirty) != 0 {
+		t.Fatalf("dirty accounts after finalising: %v", dirty)
+	}
+	checksums := make([]common.Hash, 0, 2)
+	for _, state := range []*StateDB{a, b} {
+		state.SetNonce(addr, 1)
+		state.SetState(addr, common.Hash{0x01}, common.Hash{0x02})
+
+		dirty := state.DirtyAccounts()
+		if len(dirty) != 1 || dirty[0] != addr {
+			t.Fatalf("dirty accounts mismatch: have %v, want [%x]", dirty, addr)
+		}
+		state.Finalise(true)
+		checksums = append(checksums, state.AccountsChecksum(dirty))
+	}
+	if checksums[0] != checksums[1] {
+		t.Errorf("checksum mismatch: %x != %x", checksums[0], checksums[1])
+	}
+	b.SetState(addr, common.Hash{0x01}, common.Hash{0x03})
+	b.Finalise(true)
+	if diverged := b.AccountsChecksum([]common.Address{addr}); diverged == checksums[0] {
+		t.Errorf("checksum unchanged after storage modification")
+	}
+	if checksum := a.AccountsChecksum(nil); checksum != (common.Hash{}) {
+		t.Errorf("non-zero checksum without accounts: %x", checksum)
+	}
+}
@@ -102,7 +102,7 @@ func ApplyTransaction(config *params.ChainConfig, bc *BlockChain, author *common
 		privateState = statedb
 	}
 
-	if config.IsQuorum && tx.GasPrice() != nil && tx.GasPrice().Cmp(common.Big0) > 0 {
+	if config.IsQuorum && !config.IsGasPriceEnabled(header.Number) && tx.GasPrice() != nil && tx.GasPrice().Cmp(common.Big0) > 0 {
 		return nil, nil, 0, ErrInvalidGasPrice
 	}
 
@@ -117,7 +117,7 @@ func ApplyTransaction(config *params.ChainConfig, bc *BlockChain, author *common
 	vmenv := vm.NewEVM(context, statedb, privateState, config, cfg)
 
 	// Apply the transaction to the current state (included in the env)
-	_, gas, failed, err := ApplyMessage(vmenv, msg, gp)
+	ret, gas, failed, err := ApplyMessage(vmenv, msg, gp)
 	if err != nil {
 		return nil, nil, 0, err
 	}
@@ -146,9 +146,15 @@ func ApplyTransaction(config *params.ChainConfig, bc *BlockChain, author *common
 	// Set the receipt logs and create a bloom for filtering
 	receipt.Logs = statedb.GetLogs(tx.Hash())
 	receipt.Bloom = types.CreateBloom(types.Receipts{receipt})
+	if publicFailed && cfg.SaveRevertReason {
+		receipt.RevertData = common.CopyBytes(ret)
+	}
 
 	var privateReceipt *types.Receipt
 	if config.IsQuorum && tx.IsPrivate() {
+		// Accounts touched by the transaction, which all participants must agree on
+		touched := privateState.DirtyAccounts()
+
 		var privateRoot []byte
 		if config.IsByzantium(header.Number) {
 			privateState.Finalise(true)
@@ -163,7 +169,17 @@ func ApplyTransaction(config *params.ChainConfig, bc *BlockChain, author *common
 		}
 
 		privateReceipt.Logs = privateState.GetLogs(tx.Hash())
+		for _, l := range privateReceipt.Logs {
+			l.IsPrivate = true
+		}
 		privateReceipt.Bloom = types.CreateBloom(types.Receipts{privateReceipt})
+		if len(touched) > 0 {
+			checksum := privateState.AccountsChecksum(touched)
+			privateReceipt.PrivateStateChecksum = &checksum
+		}
+		if failed && cfg.SaveRevertReason {
+			privateReceipt.RevertData = common.CopyBytes(ret)
+		}
 	}
 
 	return receipt, privateReceipt, gas, err
@@ -82,6 +82,13 @@ type PrivateMessage interface {
 	IsPrivate() bool
 }
 
+// PublicMessage implements a message explicitly executed against the public
+// state, even when it calls a private contract
+type PublicMessage interface {
+	Message
+	IsPublic() bool
+}
+
 // IntrinsicGas computes the 'intrinsic gas' for a message with the given data.
 func IntrinsicGas(data []byte, contractCreation, homestead bool) (uint64, error) {
 	// Set the starting gas for the raw transaction
@@ -194,35 +201,41 @@ func (st *StateTransition) preCheck() error {
 //    and NOT the actual private payload
 // 2. For private transactions, we only deduct intrinsic gas from the gas pool
 //    regardless the current node is party to the transaction or not
+// 3. Consequently, with gas pricing enabled the sender of a private transaction
+//    only pays for the intrinsic gas, on participant and non-participant nodes
 func (st *StateTransition) TransitionDb() (ret []byte, usedGas uint64, failed bool, err error) {
-	if err = st.preCheck(); err != nil {
-		return
-	}
 	msg := st.msg
 	sender := vm.AccountRef(msg.From())
 	homestead := st.evm.ChainConfig().IsHomestead(st.evm.BlockNumber)
 	contractCreation := msg.To() == nil
 	isQuorum := st.evm.ChainConfig().IsQuorum
 
+	// Retrieve the private payload before buying gas, so a transaction skipped
+	// while the transaction manager is down leaves the gas pool untouched
 	var data []byte
 	isPrivate := false
 	publicState := st.state
 	if msg, ok := msg.(PrivateMessage); ok && isQuorum && msg.IsPrivate() {
 		isPrivate = true
 		data, err = private.P.Receive(st.data)
-		// Increment the public account nonce if:
-		// 1. Tx is private and *not* a participant of the group and either call or create
-		// 2. Tx is private we are part of the group and is a call
-		if err != nil || !contractCreation {
-			publicState.SetNonce(sender.Address(), publicState.GetNonce(sender.Address())+1)
-		}
-
 		if err != nil {
-			return nil, 0, false, nil
+			// The payload is unknown rather than absent, so processing the
+			// transaction as a non-participant could diverge the private state
+			log.Warn("Failed to retrieve private transaction payload", "err", err)
+			return nil, 0, false, private.ErrTransactionManagerDown
 		}
 	} else {
 		data = st.data
 	}
+	if err = st.preCheck(); err != nil {
+		return
+	}
+	// Increment the public account nonce if:
+	// 1. Tx is private and *not* a participant of the group and either call or create
+	// 2. Tx is private we are part of the group and is a call
+	if isPrivate && !contractCreation {
+		publicState.SetNonce(sender.Address(), publicState.GetNonce(sender.Address())+1)
+	}
 
 	// Pay intrinsic gas. For a private contract this is done using the public hash passed in,
 	// not the private data retrieved above. This is because we need any (participant) validator
@@ -261,11 +274,16 @@ func (st *StateTransition) TransitionDb() (ret []byte, usedGas uint64, failed bo
 		//if input is empty for the smart contract call, return
 		if len(data) == 0 && isPrivate {
 			st.refundGas()
-			st.state.AddBalance(st.evm.Coinbase, new(big.Int).Mul(new(big.Int).SetUint64(st.gasUsed()), st.gasPrice))
+			st.payFee()
 			return nil, 0, false, nil
 		}
 
-		ret, leftoverGas, vmerr = evm.Call(sender, to, data, st.gas, st.value)
+		if isPrivate && to == ContractExtensionAddress {
+			// Extension management is handled natively on the private state
+			vmerr, leftoverGas = applyContractExtension(evm, msg.From(), data), st.gas
+		} else {
+			ret, leftoverGas, vmerr = evm.Call(sender, to, data, st.gas, st.value)
+		}
 	}
 	if vmerr != nil {
 		log.Info("VM returned with error", "err", vmerr)
@@ -286,7 +304,7 @@ func (st *StateTransition) TransitionDb() (ret []byte, usedGas uint64, failed bo
 	}
 
 	st.refundGas()
-	st.state.AddBalance(st.evm.Coinbase, new(big.Int).Mul(new(big.Int).SetUint64(st.gasUsed()), st.gasPrice))
+	st.payFee()
 
 	if isPrivate {
 		return ret, 0, vmerr != nil, err
@@ -311,6 +329,16 @@ func (st *StateTransition) refundGas() {
 	st.gp.AddGas(st.gas)
 }
 
+// payFee credits the fee for the gas used to the fee recipient of the chain,
+// unless the chain burns its fees.
+func (st *StateTransition) payFee() {
+	recipient, ok := st.evm.ChainConfig().GasFeeRecipient(st.evm.BlockNumber, st.evm.Coinbase)
+	if !ok {
+		return
+	}
+	st.state.AddBalance(recipient, new(big.Int).Mul(new(big.Int).SetUint64(st.gasUsed()), st.gasPrice))
+}
+
 // gasUsed returns the amount of gas used up by the state transition.
 func (st *StateTransition) gasUsed() uint64 {
 	return st.initialGas - st.gas
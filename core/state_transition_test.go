@@ -85,6 +85,76 @@ func TestStateTransition_TransitionDb_GasPoolCalculation_whenPartyNodeProcessing
 	verifyGasPoolCalculation(t, stubPTM)
 }
 
+func verifyGasPriceCharges(t *testing.T, pm private.PrivateTransactionManager) {
+	assert := testifyassert.New(t)
+	saved := private.P
+	defer func() {
+		private.P = saved
+	}()
+	private.P = pm
+
+	treasury := common.Address{0xfe}
+	config := *params.QuorumTestChainConfig
+	config.GasPriceBlock = big.NewInt(0)
+	config.GasFeeBeneficiary = &treasury
+
+	// this payload would give us 25288 intrinsic gas
+	arbitraryEncryptedPayload := "4ab80888354582b92ab442a317828386e4bf21ea4a38d1a9183fbb715f199475269d7686939017f4a6b28310d5003ebd8e012eade530b79e157657ce8dd9692a"
+	expectedFee := big.NewInt(25288 * 2) // only intrinsic gas is charged
+
+	db := ethdb.NewMemDatabase()
+	privateState, _ := state.New(common.Hash{}, state.NewDatabase(db))
+	publicState, _ := state.New(common.Hash{}, state.NewDatabase(db))
+	msg := privateCallMsg{
+		callmsg: callmsg{
+			addr:     common.Address{2},
+			to:       &common.Address{},
+			value:    new(big.Int),
+			gas:      100000,
+			gasPrice: big.NewInt(2),
+			data:     common.Hex2Bytes(arbitraryEncryptedPayload),
+		},
+	}
+	ctx := NewEVMContext(msg, &dualStateTestHeader, nil, &common.Address{})
+	evm := vm.NewEVM(ctx, publicState, privateState, &config, vm.Config{})
+	arbitraryBalance := big.NewInt(100000000)
+	publicState.SetBalance(evm.Coinbase, arbitraryBalance)
+	publicState.SetBalance(msg.From(), arbitraryBalance)
+
+	_, _, failed, err := NewStateTransition(evm, msg, new(GasPool).AddGas(200000)).TransitionDb()
+
+	assert.NoError(err)
+	assert.False(failed)
+
+	assert.Equal(new(big.Int).Sub(arbitraryBalance, expectedFee), publicState.GetBalance(msg.From()), "sender must pay the intrinsic gas")
+	assert.Equal(expectedFee, publicState.GetBalance(treasury), "beneficiary must receive the fee")
+	assert.Equal(arbitraryBalance, publicState.GetBalance(evm.Coinbase), "block author must not receive the fee")
+}
+
+func TestStateTransition_TransitionDb_GasPriceCharges_whenNonPartyNodeProcessingPrivateTransactions(t *testing.T) {
+	stubPTM := &StubPrivateTransactionManager{
+		responses: map[string][]interface{}{
+			"Receive": {
+				[]byte{},
+				nil,
+			},
+		},
+	}
+	verifyGasPriceCharges(t, stubPTM)
+}
+
+func TestStateTransition_TransitionDb_GasPriceCharges_whenPartyNodeProcessingPrivateTransactions(t *testing.T) {
+	stubPTM := &StubPrivateTransactionManager{
+		responses: map[string][]interface{}{
+			"Receive": {
+				common.Hex2Bytes("600a6000526001601ff300"),
+				nil,
+			},
+		},
+	}
+	verifyGasPriceCharges(t, stubPTM)
+}
+
 type privateCallMsg struct {
 	callmsg
 }
@@ -113,3 +183,41 @@ func (spm *StubPrivateTransactionManager) Receive(data []byte) ([]byte, error) {
 	}
 	return nil, nil
 }
+
+func TestStateTransition_TransitionDb_whenPrivateTransactionManagerUnreachable(t *testing.T) {
+	assert := testifyassert.New(t)
+	saved := private.P
+	defer func() {
+		private.P = saved
+	}()
+	private.P = &StubPrivateTransactionManager{
+		responses: map[string][]interface{}{
+			"Receive": {
+				nil,
+				fmt.Errorf("connection refused"),
+			},
+		},
+	}
+	db := ethdb.NewMemDatabase()
+	privateState, _ := state.New(common.Hash{}, state.NewDatabase(db))
+	publicState, _ := state.New(common.Hash{}, state.NewDatabase(db))
+	msg := privateCallMsg{
+		callmsg: callmsg{
+			addr:     common.Address{2},
+			to:       &common.Address{},
+			value:    new(big.Int),
+			gas:      100000,
+			gasPrice: big.NewInt(0),
+			data:     common.Hex2Bytes("4ab80888354582b92ab442a317828386e4bf21ea4a38d1a9183fbb715f199475269d7686939017f4a6b28310d5003ebd8e012eade530b79e157657ce8dd9692a"),
+		},
+	}
+	ctx := NewEVMContext(msg, &dualStateTestHeader, nil, &common.Address{})
+	evm := vm.NewEVM(ctx, publicState, privateState, params.QuorumTestChainConfig, vm.Config{})
+
+	gp := new(GasPool).AddGas(200000)
+	_, _, _, err := NewStateTransition(evm, msg, gp).TransitionDb()
+
+	assert.Equal(private.ErrTransactionManagerDown, err, "transaction must not be processed as a non-party one")
+	assert.Equal(uint64(0), publicState.GetNonce(msg.From()), "nonce must not be changed")
+	assert.Equal(uint64(200000), gp.Gas(), "gas pool must not be changed")
+}
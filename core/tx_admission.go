@@ -0,0 +1,285 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+// admissionWindow is the time window the transaction and gas rates of accounts
+// and peers are measured over.
+const admissionWindow = time.Minute
+
+var (
+	// ErrSenderNotAllowed is returned if the pool only admits transactions of
+	// allow-listed senders and the sender is not among them.
+	ErrSenderNotAllowed = errors.New("sender not allowed")
+
+	// ErrAccountRateLimit is returned if an account sent more remote transactions
+	// than permitted within the admission window.
+	ErrAccountRateLimit = errors.New("account transaction rate exceeded")
+
+	// ErrAccountGasQuota is returned if the remote transactions of an account
+	// used more gas than permitted within the admission window.
+	ErrAccountGasQuota = errors.New("account gas quota exceeded")
+
+	// ErrPeerRateLimit is returned if a peer relayed more transactions than
+	// permitted within the admission window.
+	ErrPeerRateLimit = errors.New("peer transaction rate exceeded")
+)
+
+var (
+	// Metrics for transactions rejected by admission policies
+	admissionRejectCounter = metrics.NewRegisteredCounter("txpool/admission/rejected", nil) // Rejected by any policy
+	senderRejectCounter    = metrics.NewRegisteredCounter("txpool/admission/sender", nil)
+	rateRejectCounter      = metrics.NewRegisteredCounter("txpool/admission/ratelimit", nil)
+	gasQuotaRejectCounter  = metrics.NewRegisteredCounter("txpool/admission/gasquota", nil)
+	peerRateRejectCounter  = metrics.NewRegisteredCounter("txpool/admission/peer", nil)
+)
+
+// TxAdmissionPolicy decides whether a new transaction may enter the pool, on top
+// of the validity checks of the pool itself. This is the only protection against
+// spam on networks where transactions don't cost anything. Policies are only
+// consulted for transactions submitted to the pool, not for those re-injected
+// after a reorg, and they are always called with the pool lock held.
+type TxAdmissionPolicy interface {
+	// Admit returns an error if the transaction of the given sender is to be
+	// rejected. Local transactions were submitted through this node or are
+	// sent by one of its local accounts.
+	Admit(tx *types.Transaction, from common.Address, local bool) error
+}
+
+// TxAdmissionCharger is an admission policy accounting for the transactions it
+// admitted. Transactions are only charged once they were added to the pool, so
+// duplicates and transactions failing validation don't count against a quota.
+type TxAdmissionCharger interface {
+	TxAdmissionPolicy
+
+	// Charge accounts for a transaction admitted by all policies and added to
+	// the pool.
+	Charge(tx *types.Transaction, from common.Address, local bool)
+}
+
+// senderAllowList admits transactions of the listed senders only.
+type senderAllowList map[common.Address]struct{}
+
+func newSenderAllowList(senders []common.Address) senderAllowList {
+	list := make(senderAllowList, len(senders))
+	for _, sender := range senders {
+		list[sender] = struct{}{}
+	}
+	return list
+}
+
+// Admit implements TxAdmissionPolicy.
+func (list senderAllowList) Admit(tx *types.Transaction, from common.Address, local bool) error {
+	if _, ok := list[from]; !ok {
+		senderRejectCounter.Inc(1)
+		return ErrSenderNotAllowed
+	}
+	return nil
+}
+
+// quotaUsage is the number of transactions and gas admitted for an account or
+// a peer within the current admission window.
+type quotaUsage struct {
+	start time.Time
+	txs   uint64
+	gas   uint64
+}
+
+// accountQuota limits the number and the gas of the remote transactions of every
+// account within the admission window. Local transactions are exempt, as the
+// quotas are enforced by every node they are relayed to.
+type accountQuota struct {
+	txLimit  uint64 // Maximum number of transactions per account and window (0 = unlimited)
+	gasLimit uint64 // Maximum gas of transactions per account and window (0 = unlimited)
+
+	usage     map[common.Address]*quotaUsage
+	lastSweep time.Time
+}
+
+func newAccountQuota(txLimit, gasLimit uint64) *accountQuota {
+	return &accountQuota{
+		txLimit:  txLimit,
+		gasLimit: gasLimit,
+		usage:    make(map[common.Address]*quotaUsage),
+	}
+}
+
+// Admit implements TxAdmissionPolicy.
+func (q *accountQuota) Admit(tx *types.Transaction, from common.Address, local bool) error {
+	if local {
+		return nil
+	}
+	usage := q.current(from)
+	if q.txLimit > 0 && usage.txs >= q.txLimit {
+		rateRejectCounter.Inc(1)
+		return ErrAccountRateLimit
+	}
+	if q.gasLimit > 0 && usage.gas+tx.Gas() > q.gasLimit {
+		gasQuotaRejectCounter.Inc(1)
+		return ErrAccountGasQuota
+	}
+	return nil
+}
+
+// Charge implements TxAdmissionCharger.
+func (q *accountQuota) Charge(tx *types.Transaction, from common.Address, local bool) {
+	if local {
+		return
+	}
+	usage := q.current(from)
+	usage.txs++
+	usage.gas += tx.Gas()
+}
+
+// current returns the usage of an account within the current admission window,
+// dropping the usage of all accounts whose window passed every now and then.
+func (q *accountQuota) current(from common.Address) *quotaUsage {
+	now := time.Now()
+	if now.Sub(q.lastSweep) > admissionWindow {
+		for addr, usage := range q.usage {
+			if now.Sub(usage.start) >= admissionWindow {
+				delete(q.usage, addr)
+			}
+		}
+		q.lastSweep = now
+	}
+	usage := q.usage[from]
+	if usage == nil || now.Sub(usage.start) >= admissionWindow {
+		usage = &quotaUsage{start: now}
+		q.usage[from] = usage
+	}
+	return usage
+}
+
+// peerLimiter limits the number of transactions every peer may relay within the
+// admission window.
+type peerLimiter struct {
+	limit uint64
+	usage map[string]*quotaUsage
+	lock  sync.Mutex
+}
+
+func newPeerLimiter(limit uint64) *peerLimiter {
+	return &peerLimiter{
+		limit: limit,
+		usage: make(map[string]*quotaUsage),
+	}
+}
+
+// admit returns how many of the given number of transactions relayed by a peer
+// may be processed.
+func (l *peerLimiter) admit(peer string, count int) int {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	now := time.Now()
+	usage := l.usage[peer]
+	if usage == nil || now.Sub(usage.start) >= admissionWindow {
+		// Drop the usage of all peers silent for a whole window
+		for id, usage := range l.usage {
+			if now.Sub(usage.start) >= admissionWindow {
+				delete(l.usage, id)
+			}
+		}
+		usage = &quotaUsage{start: now}
+		l.usage[peer] = usage
+	}
+	allowed := uint64(count)
+	if usage.txs+allowed > l.limit {
+		allowed = l.limit - usage.txs
+	}
+	usage.txs += allowed
+	return int(allowed)
+}
+
+// AddAdmissionPolicy adds a policy every transaction submitted to the pool from
+// now on has to pass.
+func (pool *TxPool) AddAdmissionPolicy(policy TxAdmissionPolicy) {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	pool.policies = append(pool.policies, policy)
+}
+
+// admit checks a submitted transaction against all admission policies. Known
+// transactions are dropped before any policy is consulted.
+func (pool *TxPool) admit(tx *types.Transaction, local bool) error {
+	if len(pool.policies) == 0 {
+		return nil
+	}
+	if hash := tx.Hash(); pool.all.Get(hash) != nil {
+		log.Trace("Discarding already known transaction", "hash", hash)
+		return fmt.Errorf("known transaction: %x", hash)
+	}
+	from, err := types.Sender(pool.signer, tx)
+	if err != nil {
+		return ErrInvalidSender
+	}
+	local = local || pool.locals.contains(from)
+	for _, policy := range pool.policies {
+		if err := policy.Admit(tx, from, local); err != nil {
+			admissionRejectCounter.Inc(1)
+			return err
+		}
+	}
+	return nil
+}
+
+// charge accounts for an admitted transaction added to the pool with all the
+// admission policies keeping track of their usage.
+func (pool *TxPool) charge(tx *types.Transaction, local bool) {
+	if len(pool.policies) == 0 {
+		return
+	}
+	from, _ := types.Sender(pool.signer, tx) // already validated
+	local = local || pool.locals.contains(from)
+	for _, policy := range pool.policies {
+		if charger, ok := policy.(TxAdmissionCharger); ok {
+			charger.Charge(tx, from, local)
+		}
+	}
+}
+
+// AddRemotesFromPeer enqueues a batch of transactions relayed by the given peer
+// into the pool, dropping those exceeding the transaction rate of the peer.
+func (pool *TxPool) AddRemotesFromPeer(peer string, txs []*types.Transaction) []error {
+	if pool.peerLimiter == nil {
+		return pool.AddRemotes(txs)
+	}
+	allowed := pool.peerLimiter.admit(peer, len(txs))
+
+	errs := make([]error, len(txs))
+	copy(errs, pool.AddRemotes(txs[:allowed]))
+	for i := allowed; i < len(txs); i++ {
+		errs[i] = ErrPeerRateLimit
+	}
+	if dropped := len(txs) - allowed; dropped > 0 {
+		peerRateRejectCounter.Inc(int64(dropped))
+	}
+	return errs
+}
@@ -33,6 +33,7 @@ import (
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/metrics"
 	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/private"
 )
 
 const (
@@ -82,6 +83,18 @@ var (
 	// ErrEtherValueUnsupported is returned if a transaction specifies an Ether Value
 	// for a private Quorum transaction.
 	ErrEtherValueUnsupported = errors.New("ether value is not supported for private transactions")
+
+	// ErrPrivatePayloadHashInvalid is returned if a local private transaction
+	// doesn't carry a well formed payload hash.
+	ErrPrivatePayloadHashInvalid = errors.New("private transaction payload hash must be 64 bytes")
+
+	// ErrPrivatePayloadUnknown is returned if the payload of a local private
+	// transaction isn't held by the private transaction manager.
+	ErrPrivatePayloadUnknown = errors.New("private transaction payload unknown to the private transaction manager")
+
+	// ErrPrivateTxManagerUnavailable is returned if local private transactions
+	// are to be checked but no private transaction manager is configured.
+	ErrPrivateTxManagerUnavailable = errors.New("private transaction manager not available")
 )
 
 var (
@@ -145,6 +158,13 @@ type TxPoolConfig struct {
 	GlobalQueue  uint64 // Maximum number of non-executable transaction slots for all accounts
 
 	Lifetime time.Duration // Maximum amount of time non-executable transaction are queued
+
+	CheckPrivatePayloads bool // Whether the payloads of local private transactions are checked with the private transaction manager
+
+	AllowedSenders []common.Address // Accounts transactions are exclusively accepted from (empty = everyone)
+	AccountTxRate  uint64           // Maximum number of remote transactions accepted per account and minute (0 = unlimited)
+	AccountGasRate uint64           // Maximum gas of remote transactions accepted per account and minute (0 = unlimited)
+	PeerTxRate     uint64           // Maximum number of transactions accepted per peer and minute (0 = unlimited)
 }
 
 // DefaultTxPoolConfig contains the default configurations for the transaction
@@ -211,6 +231,9 @@ type TxPool struct {
 	locals  *accountSet // Set of local transaction to exempt from eviction rules
 	journal *txJournal  // Journal of local transaction to back up to disk
 
+	policies    []TxAdmissionPolicy // Policies submitted transactions have to pass
+	peerLimiter *peerLimiter        // Transaction rate limits of relaying peers, if enabled
+
 	pending map[common.Address]*txList   // All currently processable transactions
 	queue   map[common.Address]*txList   // Queued but non-processable transactions
 	beats   map[common.Address]time.Time // Last heartbeat from each known account
@@ -220,6 +243,7 @@ type TxPool struct {
 	wg sync.WaitGroup // for shutdown sync
 
 	homestead bool
+	gasPriced bool // Whether the next block accepts non-zero gas prices on a Quorum chain
 }
 
 // NewTxPool creates a new transaction pool to gather, sort and filter inbound
@@ -249,6 +273,17 @@ func NewTxPool(config TxPoolConfig, chainconfig *params.ChainConfig, chain block
 	pool.priced = newTxPricedList(pool.all)
 	pool.reset(nil, chain.CurrentBlock().Header())
 
+	// Set up the admission policies requested by the configuration
+	if len(config.AllowedSenders) > 0 {
+		pool.policies = append(pool.policies, newSenderAllowList(config.AllowedSenders))
+	}
+	if config.AccountTxRate > 0 || config.AccountGasRate > 0 {
+		pool.policies = append(pool.policies, newAccountQuota(config.AccountTxRate, config.AccountGasRate))
+	}
+	if config.PeerTxRate > 0 {
+		pool.peerLimiter = newPeerLimiter(config.PeerTxRate)
+	}
+
 	// If local transactions and journaling is enabled, load from disk
 	if !config.NoLocals && config.Journal != "" {
 		pool.journal = newTxJournal(config.Journal)
@@ -423,6 +458,7 @@ func (pool *TxPool) reset(oldHead, newHead *types.Header) {
 	pool.currentState = statedb
 	pool.pendingState = state.ManageState(statedb)
 	pool.currentMaxGas = newHead.GasLimit
+	pool.gasPriced = pool.chainconfig.IsGasPriceEnabled(new(big.Int).Add(newHead.Number, big.NewInt(1)))
 
 	// Inject any transactions discarded due to reorgs
 	log.Debug("Reinjecting stale transactions", "count", len(reinject))
@@ -576,13 +612,14 @@ func (pool *TxPool) local() map[common.Address]types.Transactions {
 // validateTx checks whether a transaction is valid according to the consensus
 // rules and adheres to some heuristic limits of the local node (price and size).
 func (pool *TxPool) validateTx(tx *types.Transaction, local bool) error {
-	isQuorum := pool.chainconfig.IsQuorum
+	// Quorum chains require a zero gas price unless gas pricing was enabled
+	zeroPrice := pool.chainconfig.IsQuorum && !pool.gasPriced
 	sizeLimit := pool.chainconfig.TransactionSizeLimit
 	if sizeLimit == 0 {
 		sizeLimit = DefaultTxPoolConfig.TransactionSizeLimit
 	}
 
-	if isQuorum && tx.GasPrice().Cmp(common.Big0) != 0 {
+	if zeroPrice && tx.GasPrice().Cmp(common.Big0) != 0 {
 		return ErrInvalidGasPrice
 	}
 	// Reject transactions over 32KB (or manually set limit) to prevent DOS attacks
@@ -605,7 +642,7 @@ func (pool *TxPool) validateTx(tx *types.Transaction, local bool) error {
 	}
 	// Drop non-local transactions under our own minimal accepted gas price
 	local = local || pool.locals.contains(from) // account may be local even if the transaction arrived from the network
-	if !isQuorum && !local && pool.gasPrice.Cmp(tx.GasPrice()) > 0 {
+	if !zeroPrice && !local && pool.gasPrice.Cmp(tx.GasPrice()) > 0 {
 		return ErrUnderpriced
 	}
 	// Ensure the transaction adheres to nonce ordering
@@ -631,6 +668,29 @@ func (pool *TxPool) validateTx(tx *types.Transaction, local bool) error {
 	return nil
 }
 
+// validatePrivatePayload checks that the data of a private transaction is a well
+// formed payload hash the private transaction manager holds the payload for.
+// The transaction manager is queried outside of the pool lock.
+func (pool *TxPool) validatePrivatePayload(tx *types.Transaction) error {
+	if !tx.IsPrivate() || !pool.config.CheckPrivatePayloads {
+		return nil
+	}
+	if len(tx.Data()) != 64 {
+		return ErrPrivatePayloadHashInvalid
+	}
+	if private.P == nil {
+		return ErrPrivateTxManagerUnavailable
+	}
+	payload, err := private.P.Receive(tx.Data())
+	if err != nil {
+		return fmt.Errorf("private transaction manager: %v", err)
+	}
+	if len(payload) == 0 {
+		return ErrPrivatePayloadUnknown
+	}
+	return nil
+}
+
 // add validates a transaction and inserts it into the non-executable queue for
 // later pending promotion and execution. If the transaction is a replacement for
 // an already pending or queued one, it overwrites the previous and returns this
@@ -793,8 +853,12 @@ func (pool *TxPool) promoteTx(addr common.Address, hash common.Hash, tx *types.T
 
 // AddLocal enqueues a single transaction into the pool if it is valid, marking
 // the sender as a local one in the mean time, ensuring it goes around the local
-// pricing constraints.
+// pricing constraints. If enabled, private transactions are only accepted if the
+// private transaction manager holds their payload.
 func (pool *TxPool) AddLocal(tx *types.Transaction) error {
+	if err := pool.validatePrivatePayload(tx); err != nil {
+		return err
+	}
 	return pool.addTx(tx, !pool.config.NoLocals)
 }
 
@@ -824,11 +888,16 @@ func (pool *TxPool) addTx(tx *types.Transaction, local bool) error {
 	pool.mu.Lock()
 	defer pool.mu.Unlock()
 
+	if err := pool.admit(tx, local); err != nil {
+		return err
+	}
 	// Try to inject the transaction and update any state
 	replace, err := pool.add(tx, local)
 	if err != nil {
 		return err
 	}
+	pool.charge(tx, local)
+
 	// If we added a new transaction, run promotion checks and return
 	if !replace {
 		from, _ := types.Sender(pool.signer, tx) // already validated
@@ -842,7 +911,29 @@ func (pool *TxPool) addTxs(txs []*types.Transaction, local bool) []error {
 	pool.mu.Lock()
 	defer pool.mu.Unlock()
 
-	return pool.addTxsLocked(txs, local)
+	if len(pool.policies) == 0 {
+		return pool.addTxsLocked(txs, local)
+	}
+	// Admit the transactions one by one, so every one is checked against the
+	// usage charged for those added before it
+	dirty := make(map[common.Address]struct{})
+	errs := make([]error, len(txs))
+
+	for i, tx := range txs {
+		if errs[i] = pool.admit(tx, local); errs[i] != nil {
+			continue
+		}
+		var replace bool
+		if replace, errs[i] = pool.add(tx, local); errs[i] == nil {
+			pool.charge(tx, local)
+			if !replace {
+				from, _ := types.Sender(pool.signer, tx) // already validated
+				dirty[from] = struct{}{}
+			}
+		}
+	}
+	pool.promoteDirty(dirty)
+	return errs
 }
 
 // addTxsLocked attempts to queue a batch of transactions if they are valid,
@@ -859,6 +950,13 @@ func (pool *TxPool) addTxsLocked(txs []*types.Transaction, local bool) []error {
 			dirty[from] = struct{}{}
 		}
 	}
+	pool.promoteDirty(dirty)
+	return errs
+}
+
+// promoteDirty runs promotion checks for the accounts transactions were added
+// to, if any.
+func (pool *TxPool) promoteDirty(dirty map[common.Address]struct{}) {
 	// Only reprocess the internal state if something was actually added
 	if len(dirty) > 0 {
 		addrs := make([]common.Address, 0, len(dirty))
@@ -867,7 +965,6 @@ func (pool *TxPool) addTxsLocked(txs []*types.Transaction, local bool) []error {
 		}
 		pool.promoteExecutables(addrs)
 	}
-	return errs
 }
 
 // Status returns the status (unknown/pending/queued) of a batch of transactions
@@ -34,6 +34,7 @@ import (
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/event"
 	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/private"
 )
 
 // testTxPoolConfig is a transaction pool configuration without stateful disk
@@ -326,6 +327,32 @@ func TestQuorumInvalidTransactions(t *testing.T) {
 
 }
 
+// Tests that Quorum pools accept gas prices once the gas price fork is reached.
+func TestQuorumGasPricedTransactions(t *testing.T) {
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(ethdb.NewMemDatabase()))
+	blockchain := &testBlockChain{statedb, statedb, 1000000, new(event.Feed)}
+
+	config := *params.QuorumTestChainConfig
+	config.GasPriceBlock = big.NewInt(1)
+
+	pool := NewTxPool(testTxPoolConfig, &config, blockchain)
+	defer pool.Stop()
+
+	key, _ := crypto.GenerateKey()
+	pool.currentState.AddBalance(crypto.PubkeyToAddress(key.PublicKey), big.NewInt(1000000))
+
+	if err := pool.AddRemote(transaction(0, 100000, key)); err != nil {
+		t.Fatalf("failed to add priced transaction: %v", err)
+	}
+	// Remote transactions under the minimal gas price are dropped, local ones not
+	if err := pool.AddRemote(pricedTransaction(1, 100000, big.NewInt(0), key)); err != ErrUnderpriced {
+		t.Fatalf("underpriced error mismatch: have %v, want %v", err, ErrUnderpriced)
+	}
+	if err := pool.AddLocal(pricedTransaction(1, 100000, big.NewInt(0), key)); err != nil {
+		t.Fatalf("failed to add free local transaction: %v", err)
+	}
+}
+
 func TestValidateTx_whenValueZeroTransferForPrivateTransaction(t *testing.T) {
 	pool, key := setupQuorumTxPool()
 	defer pool.Stop()
@@ -374,6 +401,129 @@ func TestValidateTx_whenValueNonZeroWithSmartContractForPrivateTransaction(t *te
 	}
 }
 
+func TestAddLocal_whenPrivatePayloadsChecked(t *testing.T) {
+	saved := private.P
+	defer func() {
+		private.P = saved
+	}()
+	db := ethdb.NewMemDatabase()
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(db))
+	blockchain := &testBlockChain{statedb, statedb, 1000000, new(event.Feed)}
+
+	config := testTxPoolConfig
+	config.CheckPrivatePayloads = true
+	pool := NewTxPool(config, params.QuorumTestChainConfig, blockchain)
+	defer pool.Stop()
+
+	key, _ := crypto.GenerateKey()
+	payloadHash := make([]byte, 64)
+
+	// Malformed payload hashes are rejected
+	malformedTx, balance, from := newPrivateTransaction(common.Big0, []byte("arbitrary bytecode"), key)
+	pool.currentState.AddBalance(from, balance)
+	if err := pool.AddLocal(malformedTx); err != ErrPrivatePayloadHashInvalid {
+		t.Error("expected:", ErrPrivatePayloadHashInvalid, "; got:", err)
+	}
+	// Payloads unknown to the private transaction manager are rejected
+	private.P = &StubPrivateTransactionManager{
+		responses: map[string][]interface{}{
+			"Receive": {[]byte{}, nil},
+		},
+	}
+	unknownTx, _, _ := newPrivateTransaction(common.Big0, payloadHash, key)
+	if err := pool.AddLocal(unknownTx); err != ErrPrivatePayloadUnknown {
+		t.Error("expected:", ErrPrivatePayloadUnknown, "; got:", err)
+	}
+	// Remote transactions are not checked
+	if err := pool.AddRemote(unknownTx); err != nil {
+		t.Error("expected: <nil>; got:", err)
+	}
+	// Payloads held by the private transaction manager are accepted
+	private.P = &StubPrivateTransactionManager{
+		responses: map[string][]interface{}{
+			"Receive": {[]byte("arbitrary bytecode"), nil},
+		},
+	}
+	otherKey, _ := crypto.GenerateKey()
+	knownTx, balance, from := newPrivateTransaction(common.Big0, payloadHash, otherKey)
+	pool.currentState.AddBalance(from, balance)
+	if err := pool.AddLocal(knownTx); err != nil {
+		t.Error("expected: <nil>; got:", err)
+	}
+}
+
+func TestTransactionAdmissionPolicies(t *testing.T) {
+	t.Parallel()
+
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(ethdb.NewMemDatabase()))
+	blockchain := &testBlockChain{statedb, statedb, 1000000, new(event.Feed)}
+
+	allowed, _ := crypto.GenerateKey()
+	other, _ := crypto.GenerateKey()
+
+	config := testTxPoolConfig
+	config.AllowedSenders = []common.Address{crypto.PubkeyToAddress(allowed.PublicKey)}
+	config.AccountTxRate = 2
+	config.AccountGasRate = 250000
+	config.PeerTxRate = 3
+	pool := NewTxPool(config, params.QuorumTestChainConfig, blockchain)
+	defer pool.Stop()
+
+	pool.currentState.AddBalance(crypto.PubkeyToAddress(allowed.PublicKey), big.NewInt(1000000))
+	pool.currentState.AddBalance(crypto.PubkeyToAddress(other.PublicKey), big.NewInt(1000000))
+
+	// Senders outside of the allow list are rejected, even locally
+	if err := pool.AddLocal(pricedTransaction(0, 100000, common.Big0, other)); err != ErrSenderNotAllowed {
+		t.Fatalf("disallowed sender error mismatch: have %v, want %v", err, ErrSenderNotAllowed)
+	}
+	// Remote transactions of an account are limited by count and gas
+	if err := pool.AddRemote(pricedTransaction(0, 100000, common.Big0, allowed)); err != nil {
+		t.Fatalf("failed to add first remote transaction: %v", err)
+	}
+	// Neither known nor invalid transactions are charged to the quotas
+	if err := pool.AddRemote(pricedTransaction(0, 100000, common.Big0, allowed)); err == nil {
+		t.Fatalf("added known remote transaction")
+	}
+	if err := pool.AddRemote(pricedTransaction(0, 50000, common.Big0, allowed)); err != ErrReplaceUnderpriced {
+		t.Fatalf("replacement error mismatch: have %v, want %v", err, ErrReplaceUnderpriced)
+	}
+	if err := pool.AddRemote(pricedTransaction(1, 200000, common.Big0, allowed)); err != ErrAccountGasQuota {
+		t.Fatalf("gas quota error mismatch: have %v, want %v", err, ErrAccountGasQuota)
+	}
+	if err := pool.AddRemote(pricedTransaction(1, 100000, common.Big0, allowed)); err != nil {
+		t.Fatalf("failed to add second remote transaction: %v", err)
+	}
+	errs := pool.AddRemotes([]*types.Transaction{pricedTransaction(2, 21000, common.Big0, allowed)})
+	if errs[0] != ErrAccountRateLimit {
+		t.Fatalf("rate limit error mismatch: have %v, want %v", errs[0], ErrAccountRateLimit)
+	}
+	// Local transactions are exempt from the account quotas
+	if err := pool.AddLocal(pricedTransaction(2, 100000, common.Big0, allowed)); err != nil {
+		t.Fatalf("failed to add local transaction: %v", err)
+	}
+	pending, _ := pool.Stats()
+	if pending != 3 {
+		t.Fatalf("pending transactions mismatch: have %d, want 3", pending)
+	}
+	// Peers may only relay a limited number of transactions
+	txs := make([]*types.Transaction, 5)
+	for i := range txs {
+		txs[i] = pricedTransaction(uint64(i), 21000, common.Big0, other)
+	}
+	errs = pool.AddRemotesFromPeer("peer", txs)
+	for i, err := range errs {
+		if i < 3 && err != ErrSenderNotAllowed {
+			t.Errorf("tx %d: error mismatch: have %v, want %v", i, err, ErrSenderNotAllowed)
+		}
+		if i >= 3 && err != ErrPeerRateLimit {
+			t.Errorf("tx %d: error mismatch: have %v, want %v", i, err, ErrPeerRateLimit)
+		}
+	}
+	if errs := pool.AddRemotesFromPeer("other", txs[:1]); errs[0] != ErrSenderNotAllowed {
+		t.Errorf("limit of other peer exhausted: %v", errs[0])
+	}
+}
+
 func TestTransactionQueue(t *testing.T) {
 	t.Parallel()
 
@@ -23,6 +23,7 @@ func (l Log) MarshalJSON() ([]byte, error) {
 		BlockHash   common.Hash    `json:"blockHash"`
 		Index       hexutil.Uint   `json:"logIndex" gencodec:"required"`
 		Removed     bool           `json:"removed"`
+		IsPrivate   bool           `json:"isPrivate"`
 	}
 	var enc Log
 	enc.Address = l.Address
@@ -34,6 +35,7 @@ func (l Log) MarshalJSON() ([]byte, error) {
 	enc.BlockHash = l.BlockHash
 	enc.Index = hexutil.Uint(l.Index)
 	enc.Removed = l.Removed
+	enc.IsPrivate = l.IsPrivate
 	return json.Marshal(&enc)
 }
 
@@ -48,6 +50,7 @@ func (l *Log) UnmarshalJSON(input []byte) error {
 		BlockHash   *common.Hash    `json:"blockHash"`
 		Index       *hexutil.Uint   `json:"logIndex" gencodec:"required"`
 		Removed     *bool           `json:"removed"`
+		IsPrivate   *bool           `json:"isPrivate"`
 	}
 	var dec Log
 	if err := json.Unmarshal(input, &dec); err != nil {
@@ -86,5 +89,8 @@ func (l *Log) UnmarshalJSON(input []byte) error {
 	if dec.Removed != nil {
 		l.Removed = *dec.Removed
 	}
+	if dec.IsPrivate != nil {
+		l.IsPrivate = *dec.IsPrivate
+	}
 	return nil
 }
@@ -53,6 +53,11 @@ type Log struct {
 	// The Removed field is true if this log was reverted due to a chain reorganisation.
 	// You must pay attention to this field if you receive logs through a filter query.
 	Removed bool `json:"removed"`
+
+	// Quorum
+	// The IsPrivate field is true if this log was emitted by a private transaction
+	// into the private state of the node.
+	IsPrivate bool `json:"isPrivate"`
 }
 
 type logMarshaling struct {
@@ -55,6 +55,20 @@ type Receipt struct {
 	TxHash          common.Hash    `json:"transactionHash" gencodec:"required"`
 	ContractAddress common.Address `json:"contractAddress"`
 	GasUsed         uint64         `json:"gasUsed" gencodec:"required"`
+
+	// Quorum
+	// RevertData is the return data of a failed execution, stored if revert
+	// reasons are saved. It is decoded into RevertReason by the API layer.
+	RevertData []byte `json:"-"`
+
+	// RevertReason is the decoded revert reason as reported over RPC. It is
+	// not stored.
+	RevertReason string `json:"revertReason,omitempty"`
+
+	// PrivateStateChecksum is a hash over the private accounts touched by a
+	// private transaction after its execution. All participants of the
+	// transaction are expected to compute the same checksum.
+	PrivateStateChecksum *common.Hash `json:"privateStateChecksum,omitempty"`
 }
 
 type receiptMarshaling struct {
@@ -80,6 +94,21 @@ type receiptStorageRLP struct {
 	ContractAddress   common.Address
 	Logs              []*LogForStorage
 	GasUsed           uint64
+
+	// Quorum fields are stored as a single trailing list tagged with its format
+	// version, and are absent from receipts which don't have any
+	Quorum []*quorumReceiptStorageRLP `rlp:"tail"`
+}
+
+// quorumReceiptStorageVersion is the format version of quorumReceiptStorageRLP.
+const quorumReceiptStorageVersion = 1
+
+// quorumReceiptStorageRLP is the storage encoding of the Quorum specific fields
+// of a receipt. An empty PrivateStateChecksum stands for no checksum.
+type quorumReceiptStorageRLP struct {
+	Version              uint
+	RevertData           []byte
+	PrivateStateChecksum []byte
 }
 
 // NewReceipt creates a barebone transaction receipt, copying the init fields.
@@ -168,6 +197,13 @@ func (r *ReceiptForStorage) EncodeRLP(w io.Writer) error {
 	for i, log := range r.Logs {
 		enc.Logs[i] = (*LogForStorage)(log)
 	}
+	if len(r.RevertData) > 0 || r.PrivateStateChecksum != nil {
+		quorum := &quorumReceiptStorageRLP{Version: quorumReceiptStorageVersion, RevertData: r.RevertData}
+		if r.PrivateStateChecksum != nil {
+			quorum.PrivateStateChecksum = r.PrivateStateChecksum.Bytes()
+		}
+		enc.Quorum = []*quorumReceiptStorageRLP{quorum}
+	}
 	return rlp.Encode(w, enc)
 }
 
@@ -189,6 +225,27 @@ func (r *ReceiptForStorage) DecodeRLP(s *rlp.Stream) error {
 	}
 	// Assign the implementation fields
 	r.TxHash, r.ContractAddress, r.GasUsed = dec.TxHash, dec.ContractAddress, dec.GasUsed
+
+	// Assign the Quorum fields, if any were stored
+	switch len(dec.Quorum) {
+	case 0:
+	case 1:
+		quorum := dec.Quorum[0]
+		if quorum.Version != quorumReceiptStorageVersion {
+			return fmt.Errorf("unsupported receipt storage version %d", quorum.Version)
+		}
+		r.RevertData = quorum.RevertData
+		switch len(quorum.PrivateStateChecksum) {
+		case 0:
+		case common.HashLength:
+			checksum := common.BytesToHash(quorum.PrivateStateChecksum)
+			r.PrivateStateChecksum = &checksum
+		default:
+			return fmt.Errorf("invalid private state checksum length %d", len(quorum.PrivateStateChecksum))
+		}
+	default:
+		return fmt.Errorf("invalid Quorum receipt field count %d", len(dec.Quorum))
+	}
 	return nil
 }
 
@@ -37,6 +37,8 @@ type Config struct {
 	NoRecursion bool
 	// Enable recording of SHA3/keccak preimages
 	EnablePreimageRecording bool
+	// Enable storing the revert reasons of failed transactions in their receipts
+	SaveRevertReason bool
 	// JumpTable contains the EVM instruction table. This
 	// may be left uninitialised and will be set to the default
 	// table.
@@ -36,6 +36,7 @@ import (
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/internal/ethapi"
 	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/private"
 	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/ethereum/go-ethereum/rpc"
 	"github.com/ethereum/go-ethereum/trie"
@@ -183,6 +184,23 @@ func (api *PrivateMinerAPI) GetHashrate() uint64 {
 	return api.e.miner.HashRate()
 }
 
+// PublicQuorumAPI provides an API to access the health of the Quorum specific
+// components of the node.
+type PublicQuorumAPI struct {
+	eth *Ethereum
+}
+
+// NewPublicQuorumAPI creates a new Quorum API.
+func NewPublicQuorumAPI(eth *Ethereum) *PublicQuorumAPI {
+	return &PublicQuorumAPI{eth: eth}
+}
+
+// TransactionManagerStatus returns the health of the private transaction
+// manager as of the last check.
+func (api *PublicQuorumAPI) TransactionManagerStatus() private.TransactionManagerStatus {
+	return api.eth.ptmMonitor.Status()
+}
+
 // PrivateAdminAPI is the collection of Ethereum full node-related APIs
 // exposed over the private admin endpoint.
 type PrivateAdminAPI struct {
@@ -227,6 +245,15 @@ func hasAllBlocks(chain *core.BlockChain, bs []*types.Block) bool {
 	return true
 }
 
+// PrivateStateDivergences returns the most recent private transactions whose
+// private state checksum differs between this node and one of its peers.
+func (api *PrivateAdminAPI) PrivateStateDivergences() ([]*PrivateStateDivergence, error) {
+	if api.eth.checksums == nil {
+		return nil, errors.New("private state checksum exchange disabled")
+	}
+	return api.eth.checksums.Divergences(), nil
+}
+
 // ImportChain imports a blockchain from a local file.
 func (api *PrivateAdminAPI) ImportChain(file string) (bool, error) {
 	// Make sure the can access the file to import
@@ -19,6 +19,7 @@ package eth
 import (
 	"context"
 	"math/big"
+	"time"
 
 	"github.com/ethereum/go-ethereum/accounts"
 	"github.com/ethereum/go-ethereum/common"
@@ -33,6 +34,7 @@ import (
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/event"
 	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/private"
 	"github.com/ethereum/go-ethereum/rpc"
 )
 
@@ -152,8 +154,12 @@ func (b *EthAPIBackend) GetEVM(ctx context.Context, msg core.Message, state vm.M
 		to = *msg.To()
 	}
 
+	// Private messages, such as simulated private transactions, always run
+	// against the private state, explicitly public ones never do
 	privateState := statedb.privateState
-	if !privateState.Exist(to) {
+	if msg, ok := msg.(core.PublicMessage); ok && msg.IsPublic() {
+		privateState = statedb.state
+	} else if msg, ok := msg.(core.PrivateMessage); (!ok || !msg.IsPrivate()) && !privateState.Exist(to) {
 		privateState = statedb.state
 	}
 
@@ -225,13 +231,42 @@ func (b *EthAPIBackend) ProtocolVersion() int {
 }
 
 func (b *EthAPIBackend) SuggestPrice(ctx context.Context) (*big.Int, error) {
-	if b.ChainConfig().IsQuorum {
+	config := b.ChainConfig()
+	next := new(big.Int).Add(b.eth.blockchain.CurrentBlock().Number(), big.NewInt(1))
+	if config.IsQuorum && !config.IsGasPriceEnabled(next) {
 		return big.NewInt(0), nil
 	} else {
 		return b.gpo.SuggestPrice(ctx)
 	}
 }
 
+// PrivateTxManagerAvailable returns an error if private transactions are to be
+// refused because the private transaction manager is down.
+func (b *EthAPIBackend) PrivateTxManagerAvailable() error {
+	if monitor := b.eth.ptmMonitor; monitor != nil && b.eth.config.PrivateTxManagerRejectPrivate && !monitor.Up() {
+		return private.ErrTransactionManagerDown
+	}
+	return nil
+}
+
+// CheckPrivatePayloads returns whether local private transactions are checked
+// before they are sent to the private transaction manager.
+func (b *EthAPIBackend) CheckPrivatePayloads() bool {
+	return b.eth.config.TxPool.CheckPrivatePayloads
+}
+
+// SimulatePrivateTransactions returns whether private transactions sent through
+// the node's accounts are simulated before they are distributed.
+func (b *EthAPIBackend) SimulatePrivateTransactions() bool {
+	return b.eth.config.PrivateSimulation
+}
+
+// PrivateSimulationTimeout returns the execution time limit of a private
+// transaction simulation.
+func (b *EthAPIBackend) PrivateSimulationTimeout() time.Duration {
+	return b.eth.config.PrivateSimulationTimeout
+}
+
 func (b *EthAPIBackend) ChainDb() ethdb.Database {
 	return b.eth.ChainDb()
 }
@@ -323,4 +358,14 @@ func (s EthAPIState) GetCodeHash(addr common.Address) common.Hash {
 	return s.state.GetCodeHash(addr)
 }
 
+// PublicState returns the public state alone, regardless of private accounts.
+func (s EthAPIState) PublicState() vm.MinimalApiState {
+	return s.state
+}
+
+// PrivateState returns the private state alone.
+func (s EthAPIState) PrivateState() vm.MinimalApiState {
+	return s.privateState
+}
+
 //func (s MinimalApiState) Error
@@ -44,12 +44,14 @@ import (
 	"github.com/ethereum/go-ethereum/eth/gasprice"
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/event"
+	"github.com/ethereum/go-ethereum/extension"
 	"github.com/ethereum/go-ethereum/internal/ethapi"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/miner"
 	"github.com/ethereum/go-ethereum/node"
 	"github.com/ethereum/go-ethereum/p2p"
 	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/private"
 	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/ethereum/go-ethereum/rpc"
 )
@@ -86,6 +88,11 @@ type Ethereum struct {
 	bloomIndexer  *core.ChainIndexer             // Bloom indexer operating during block imports
 
 	APIBackend *EthAPIBackend
+	nonceLock  *ethapi.AddrLocker // Nonce lock shared by every service sending transactions
+
+	extensionService *extension.Service     // Quorum: private contract extension workflow
+	checksums        *checksumExchange      // Quorum: private state checksum exchange, if enabled
+	ptmMonitor       *private.HealthMonitor // Quorum: private transaction manager health checks
 
 	miner     *miner.Miner
 	gasPrice  *big.Int
@@ -158,11 +165,16 @@ func New(ctx *node.ServiceContext, config *Config) (*Ethereum, error) {
 		etherbase:      config.Etherbase,
 		bloomRequests:  make(chan chan *bloombits.Retrieval),
 		bloomIndexer:   NewBloomIndexer(chainDb, params.BloomBitsBlocks, params.BloomConfirms),
+		nonceLock:      new(ethapi.AddrLocker),
 	}
 
 	// force to set the istanbul etherbase to node key address
 	if chainConfig.Istanbul != nil {
-		eth.etherbase = crypto.PubkeyToAddress(ctx.NodeKey().PublicKey)
+		if config.Istanbul.Validator != (common.Address{}) {
+			eth.etherbase = config.Istanbul.Validator
+		} else {
+			eth.etherbase = crypto.PubkeyToAddress(ctx.NodeKey().PublicKey)
+		}
 	}
 
 	log.Info("Initialising Ethereum protocol", "versions", ProtocolVersions, "network", config.NetworkId)
@@ -177,6 +189,7 @@ func New(ctx *node.ServiceContext, config *Config) (*Ethereum, error) {
 	var (
 		vmConfig = vm.Config{
 			EnablePreimageRecording: config.EnablePreimageRecording,
+			SaveRevertReason:        config.SaveRevertReason,
 			EWASMInterpreter:        config.EWASMInterpreter,
 			EVMInterpreter:          config.EVMInterpreter,
 		}
@@ -213,6 +226,21 @@ func New(ctx *node.ServiceContext, config *Config) (*Ethereum, error) {
 	}
 	eth.APIBackend.gpo = gasprice.NewOracle(eth.APIBackend, gpoParams)
 
+	if chainConfig.IsQuorum {
+		eth.extensionService = extension.New(eth.blockchain, eth.APIBackend, eth.nonceLock)
+		if config.PrivateStateChecksums {
+			eth.checksums = newChecksumExchange(eth.blockchain)
+		}
+		eth.ptmMonitor = private.NewHealthMonitor(private.P, config.PrivateTxManagerCheckInterval)
+		if config.PrivateTxManagerPauseImport {
+			eth.blockchain.SetImportGate(eth.ptmMonitor.WaitUp)
+		}
+		// Validators don't vote for proposals they can't process
+		if gated, ok := eth.engine.(consensus.PrivateTxGated); ok {
+			gated.SetPrivateTxGate(eth.ptmMonitor.Available)
+		}
+	}
+
 	return eth, nil
 }
 
@@ -257,6 +285,10 @@ func CreateConsensusEngine(ctx *node.ServiceContext, chainConfig *params.ChainCo
 			config.Istanbul.Epoch = chainConfig.Istanbul.Epoch
 		}
 		config.Istanbul.ProposerPolicy = istanbul.ProposerPolicy(chainConfig.Istanbul.ProposerPolicy)
+		config.Istanbul.EmptyBlockPeriod = chainConfig.Istanbul.EmptyBlockPeriod
+		if config.Istanbul.Validator != (common.Address{}) {
+			return istanbulBackend.NewWithSigner(&config.Istanbul, config.Istanbul.Validator, istanbulSigner(ctx, &config.Istanbul), db)
+		}
 		return istanbulBackend.New(&config.Istanbul, ctx.NodeKey(), db)
 	}
 
@@ -280,14 +312,43 @@ func CreateConsensusEngine(ctx *node.ServiceContext, chainConfig *params.ChainCo
 	}
 }
 
+// istanbulSigner returns the signer function holding the Istanbul validator key,
+// either an external signer reached over IPC or a wallet of the account manager.
+func istanbulSigner(ctx *node.ServiceContext, config *istanbul.Config) istanbulBackend.SignerFn {
+	if config.SignerEndpoint != "" {
+		log.Info("Signing Istanbul messages with external signer", "validator", config.Validator, "endpoint", config.SignerEndpoint)
+		return istanbulBackend.NewRemoteSigner(config.SignerEndpoint)
+	}
+	log.Info("Signing Istanbul messages with account wallet", "validator", config.Validator)
+	return func(account accounts.Account, hash []byte) ([]byte, error) {
+		wallet, err := ctx.AccountManager.Find(account)
+		if err != nil {
+			return nil, err
+		}
+		return wallet.SignHash(account, hash)
+	}
+}
+
 // APIs return the collection of RPC services the ethereum package offers.
 // NOTE, some of these services probably need to be moved to somewhere else.
 func (s *Ethereum) APIs() []rpc.API {
-	apis := ethapi.GetAPIs(s.APIBackend)
+	apis := ethapi.GetAPIs(s.APIBackend, s.nonceLock)
 
 	// Append any APIs exposed explicitly by the consensus engine
 	apis = append(apis, s.engine.APIs(s.BlockChain())...)
 
+	if s.extensionService != nil {
+		apis = append(apis, s.extensionService.APIs()...)
+	}
+	if s.ptmMonitor != nil {
+		apis = append(apis, rpc.API{
+			Namespace: "quorum",
+			Version:   "1.0",
+			Service:   NewPublicQuorumAPI(s),
+			Public:    true,
+		})
+	}
+
 	// Append all the local APIs and return
 	return append(apis, []rpc.API{
 		{
@@ -313,7 +374,7 @@ func (s *Ethereum) APIs() []rpc.API {
 		}, {
 			Namespace: "eth",
 			Version:   "1.0",
-			Service:   filters.NewPublicFilterAPI(s.APIBackend, false),
+			Service:   filters.NewPublicFilterAPI(s.APIBackend, false, filters.WithRangeLimit(s.config.LogsBlockRange)),
 			Public:    true,
 		}, {
 			Namespace: "admin",
@@ -508,10 +569,17 @@ func (s *Ethereum) Downloader() *downloader.Downloader { return s.protocolManage
 // Protocols implements node.Service, returning all the currently configured
 // network protocols to start.
 func (s *Ethereum) Protocols() []p2p.Protocol {
+	protos := s.protocolManager.SubProtocols
+	if transport, ok := s.engine.(consensus.Transport); ok {
+		protos = append(protos, transport.Protocols()...)
+	}
+	if s.checksums != nil {
+		protos = append(protos, s.checksums.Protocols()...)
+	}
 	if s.lesServer == nil {
-		return s.protocolManager.SubProtocols
+		return protos
 	}
-	return append(s.protocolManager.SubProtocols, s.lesServer.Protocols()...)
+	return append(protos, s.lesServer.Protocols()...)
 }
 
 // Start implements node.Service, starting all internal goroutines needed by the
@@ -523,6 +591,11 @@ func (s *Ethereum) Start(srvr *p2p.Server) error {
 	// Start the RPC service
 	s.netRPCService = ethapi.NewPublicNetAPI(srvr, s.NetVersion())
 
+	// Let the consensus engine reach other consensus participants directly
+	if transport, ok := s.engine.(consensus.Transport); ok {
+		transport.SetServer(srvr)
+	}
+
 	// Figure out a max peers count based on the server limits
 	maxPeers := srvr.MaxPeers
 	if s.config.LightServ > 0 {
@@ -536,6 +609,15 @@ func (s *Ethereum) Start(srvr *p2p.Server) error {
 	if s.lesServer != nil {
 		s.lesServer.Start(srvr)
 	}
+	if s.extensionService != nil {
+		s.extensionService.Start()
+	}
+	if s.checksums != nil {
+		s.checksums.Start()
+	}
+	if s.ptmMonitor != nil {
+		s.ptmMonitor.Start()
+	}
 	return nil
 }
 
@@ -543,6 +625,15 @@ func (s *Ethereum) Start(srvr *p2p.Server) error {
 // Ethereum protocol.
 func (s *Ethereum) Stop() error {
 	s.bloomIndexer.Close()
+	if s.extensionService != nil {
+		s.extensionService.Stop()
+	}
+	if s.checksums != nil {
+		s.checksums.Stop()
+	}
+	if s.ptmMonitor != nil {
+		s.ptmMonitor.Stop()
+	}
 	s.blockchain.Stop()
 	s.engine.Close()
 	s.protocolManager.Stop()
@@ -0,0 +1,278 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package eth
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/p2p"
+	"github.com/hashicorp/golang-lru"
+)
+
+const (
+	checksumProtoName    = "pcs" // Name of the private state checksum sub-protocol
+	checksumProtoVersion = 1     // Version of the private state checksum sub-protocol
+	checksumProtoLength  = 1     // Number of message codes of the private state checksum sub-protocol
+
+	checksumsMsg = 0x00 // Private state checksums of the transactions of a block
+
+	checksumMaxMsgSize    = 1024 * 1024 // Maximum size of a checksum message
+	checksumChainChanSize = 10          // Size of the channel listening to imported blocks
+	checksumPendingBlocks = 256         // Blocks ahead of the local head whose remote checksums are kept
+	maxDivergences        = 128         // Most recent divergences kept for the admin API
+)
+
+// errChecksumMsgTooLarge is returned if a peer sends a checksum message exceeding
+// the maximum size.
+var errChecksumMsgTooLarge = errors.New("checksum message too large")
+
+// txChecksum is the private state checksum of a single private transaction.
+type txChecksum struct {
+	TxHash   common.Hash
+	Checksum common.Hash
+}
+
+// blockChecksums are the private state checksums of all private transactions
+// of a block a node participated in.
+type blockChecksums struct {
+	Hash      common.Hash
+	Number    uint64
+	Checksums []txChecksum
+}
+
+// remoteChecksums are block checksums received from a peer.
+type remoteChecksums struct {
+	peer string
+	msg  *blockChecksums
+}
+
+// PrivateStateDivergence is a private transaction whose private state checksum
+// differs between this node and a peer, meaning the two nodes don't agree on
+// the private state of the accounts touched by it.
+type PrivateStateDivergence struct {
+	BlockHash      common.Hash `json:"blockHash"`
+	BlockNumber    uint64      `json:"blockNumber"`
+	TxHash         common.Hash `json:"transactionHash"`
+	LocalChecksum  common.Hash `json:"localChecksum"`
+	RemoteChecksum common.Hash `json:"remoteChecksum"`
+	Peer           string      `json:"peer"`
+	Time           time.Time   `json:"time"`
+}
+
+// checksumExchange shares the private state checksums of every imported block
+// with the peers speaking the checksum sub-protocol, and compares those received
+// with the local ones. Only transactions both nodes participated in are
+// compared. Note that peers learn which private transactions a node takes part
+// in, which is why the exchange is optional.
+type checksumExchange struct {
+	chain *core.BlockChain
+
+	peers       map[string]p2p.MsgReadWriter // Connected checksum peers by id
+	pending     *lru.Cache                   // Remote checksums of blocks not imported yet
+	divergences []*PrivateStateDivergence    // Most recent divergences detected
+	lock        sync.RWMutex
+
+	chainCh  chan core.ChainEvent
+	chainSub event.Subscription
+	wg       sync.WaitGroup
+}
+
+func newChecksumExchange(chain *core.BlockChain) *checksumExchange {
+	pending, _ := lru.New(checksumPendingBlocks)
+	return &checksumExchange{
+		chain:   chain,
+		peers:   make(map[string]p2p.MsgReadWriter),
+		pending: pending,
+		chainCh: make(chan core.ChainEvent, checksumChainChanSize),
+	}
+}
+
+// Protocols returns the private state checksum sub-protocol.
+func (ce *checksumExchange) Protocols() []p2p.Protocol {
+	return []p2p.Protocol{{
+		Name:    checksumProtoName,
+		Version: checksumProtoVersion,
+		Length:  checksumProtoLength,
+		Run:     ce.runPeer,
+	}}
+}
+
+// Start begins sharing the checksums of imported blocks.
+func (ce *checksumExchange) Start() {
+	ce.chainSub = ce.chain.SubscribeChainEvent(ce.chainCh)
+
+	ce.wg.Add(1)
+	go ce.loop()
+}
+
+// Stop terminates the checksum exchange.
+func (ce *checksumExchange) Stop() {
+	ce.chainSub.Unsubscribe()
+	ce.wg.Wait()
+}
+
+// Divergences returns the most recent private state divergences detected.
+func (ce *checksumExchange) Divergences() []*PrivateStateDivergence {
+	ce.lock.RLock()
+	defer ce.lock.RUnlock()
+
+	return append([]*PrivateStateDivergence{}, ce.divergences...)
+}
+
+func (ce *checksumExchange) loop() {
+	defer ce.wg.Done()
+
+	for {
+		select {
+		case ev := <-ce.chainCh:
+			local := ce.localChecksums(ev.Block)
+			if local == nil {
+				continue
+			}
+			ce.broadcast(local)
+			if remotes, ok := ce.pending.Get(ev.Hash); ok {
+				ce.pending.Remove(ev.Hash)
+				for _, remote := range remotes.([]*remoteChecksums) {
+					ce.compare(remote.peer, local, remote.msg)
+				}
+			}
+		case <-ce.chainSub.Err():
+			return
+		}
+	}
+}
+
+// localChecksums collects the private state checksums of a block from the
+// local receipts, or nil if this node participated in no private transaction.
+func (ce *checksumExchange) localChecksums(block *types.Block) *blockChecksums {
+	var checksums []txChecksum
+	for _, receipt := range ce.chain.GetReceiptsByHash(block.Hash()) {
+		if receipt.PrivateStateChecksum != nil {
+			checksums = append(checksums, txChecksum{receipt.TxHash, *receipt.PrivateStateChecksum})
+		}
+	}
+	if len(checksums) == 0 {
+		return nil
+	}
+	return &blockChecksums{Hash: block.Hash(), Number: block.NumberU64(), Checksums: checksums}
+}
+
+// broadcast sends the checksums of a block to all checksum peers.
+func (ce *checksumExchange) broadcast(msg *blockChecksums) {
+	ce.lock.RLock()
+	defer ce.lock.RUnlock()
+
+	for id, rw := range ce.peers {
+		if err := p2p.Send(rw, checksumsMsg, msg); err != nil {
+			log.Debug("Failed to send private state checksums", "peer", id, "err", err)
+		}
+	}
+}
+
+// handle compares the checksums received from a peer with the local ones, or
+// keeps them until the block is imported.
+func (ce *checksumExchange) handle(peer string, msg *blockChecksums) {
+	if block := ce.chain.GetBlockByHash(msg.Hash); block != nil {
+		if local := ce.localChecksums(block); local != nil {
+			ce.compare(peer, local, msg)
+		}
+		return
+	}
+	if msg.Number <= ce.chain.CurrentBlock().NumberU64() {
+		return // Block we don't know and never will, e.g. a side chain
+	}
+	var remotes []*remoteChecksums
+	if cached, ok := ce.pending.Get(msg.Hash); ok {
+		remotes = cached.([]*remoteChecksums)
+	}
+	ce.pending.Add(msg.Hash, append(remotes, &remoteChecksums{peer, msg}))
+}
+
+// compare records every transaction whose checksum differs between the local
+// and remote checksums of a block.
+func (ce *checksumExchange) compare(peer string, local, remote *blockChecksums) {
+	known := make(map[common.Hash]common.Hash, len(local.Checksums))
+	for _, c := range local.Checksums {
+		known[c.TxHash] = c.Checksum
+	}
+	for _, c := range remote.Checksums {
+		checksum, ok := known[c.TxHash]
+		if !ok || checksum == c.Checksum {
+			continue
+		}
+		log.Error("Private state divergence detected", "number", local.Number, "hash", local.Hash, "tx", c.TxHash, "local", checksum, "remote", c.Checksum, "peer", peer)
+
+		ce.lock.Lock()
+		ce.divergences = append(ce.divergences, &PrivateStateDivergence{
+			BlockHash:      local.Hash,
+			BlockNumber:    local.Number,
+			TxHash:         c.TxHash,
+			LocalChecksum:  checksum,
+			RemoteChecksum: c.Checksum,
+			Peer:           peer,
+			Time:           time.Now(),
+		})
+		if len(ce.divergences) > maxDivergences {
+			ce.divergences = ce.divergences[len(ce.divergences)-maxDivergences:]
+		}
+		ce.lock.Unlock()
+	}
+}
+
+// runPeer registers a peer speaking the checksum sub-protocol and handles its
+// messages until the connection drops.
+func (ce *checksumExchange) runPeer(p *p2p.Peer, rw p2p.MsgReadWriter) error {
+	id := p.ID().String()
+
+	ce.lock.Lock()
+	ce.peers[id] = rw
+	ce.lock.Unlock()
+
+	defer func() {
+		ce.lock.Lock()
+		delete(ce.peers, id)
+		ce.lock.Unlock()
+	}()
+	for {
+		msg, err := rw.ReadMsg()
+		if err != nil {
+			return err
+		}
+		if msg.Size > checksumMaxMsgSize {
+			msg.Discard()
+			return errChecksumMsgTooLarge
+		}
+		switch msg.Code {
+		case checksumsMsg:
+			var checksums blockChecksums
+			if err = msg.Decode(&checksums); err == nil {
+				ce.handle(id, &checksums)
+			}
+		}
+		msg.Discard()
+		if err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,63 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package eth
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Tests that only transactions known to both nodes are compared, and that
+// differing checksums are reported as divergences.
+func TestChecksumCompare(t *testing.T) {
+	ce := newChecksumExchange(nil)
+
+	local := &blockChecksums{
+		Hash:   common.Hash{0x01},
+		Number: 1,
+		Checksums: []txChecksum{
+			{TxHash: common.Hash{0xa1}, Checksum: common.Hash{0xc1}},
+			{TxHash: common.Hash{0xa2}, Checksum: common.Hash{0xc2}},
+		},
+	}
+	remote := &blockChecksums{
+		Hash:   common.Hash{0x01},
+		Number: 1,
+		Checksums: []txChecksum{
+			{TxHash: common.Hash{0xa1}, Checksum: common.Hash{0xc1}},
+			{TxHash: common.Hash{0xa2}, Checksum: common.Hash{0xff}},
+			{TxHash: common.Hash{0xa3}, Checksum: common.Hash{0xc3}},
+		},
+	}
+	ce.compare("peer", local, remote)
+
+	divergences := ce.Divergences()
+	if len(divergences) != 1 {
+		t.Fatalf("divergence count mismatch: have %d, want 1", len(divergences))
+	}
+	d := divergences[0]
+	if d.TxHash != (common.Hash{0xa2}) || d.LocalChecksum != (common.Hash{0xc2}) || d.RemoteChecksum != (common.Hash{0xff}) || d.Peer != "peer" {
+		t.Errorf("divergence mismatch: have %+v", d)
+	}
+	for i := 0; i < maxDivergences; i++ {
+		ce.compare("peer", local, remote)
+	}
+	if have := len(ce.Divergences()); have != maxDivergences {
+		t.Errorf("divergences not capped: have %d, want %d", have, maxDivergences)
+	}
+}
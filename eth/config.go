@@ -54,6 +54,9 @@ var DefaultConfig = Config{
 	MinerGasPrice: big.NewInt(params.GWei),
 	MinerRecommit: 3 * time.Second,
 
+	PrivateTxManagerCheckInterval: 10 * time.Second,
+	PrivateSimulationTimeout:      5 * time.Second,
+
 	TxPool: core.DefaultTxPoolConfig,
 	GPO: gasprice.Config{
 		Blocks:     20,
@@ -119,9 +122,27 @@ type Config struct {
 	// Gas Price Oracle options
 	GPO gasprice.Config
 
+	// Maximum number of blocks eth_getLogs queries may span (0 = unlimited)
+	LogsBlockRange uint64
+
 	// Enables tracking of SHA3 preimages in the VM
 	EnablePreimageRecording bool
 
+	// Enables storing the revert reasons of failed transactions in their receipts
+	SaveRevertReason bool
+
+	// Enables exchanging private state checksums with peers
+	PrivateStateChecksums bool
+
+	// Simulation of private transactions before they are distributed
+	PrivateSimulation        bool          // Simulate private transactions sent through the node's accounts
+	PrivateSimulationTimeout time.Duration // Execution time limit of a single simulation
+
+	// Private transaction manager health checks
+	PrivateTxManagerCheckInterval time.Duration // Interval between upchecks (0 = disabled)
+	PrivateTxManagerPauseImport   bool          // Hold back imports of private transactions while it is down
+	PrivateTxManagerRejectPrivate bool          // Refuse submitting private transactions while it is down
+
 	RaftMode             bool
 	EnableNodePermission bool
 	// Istanbul options
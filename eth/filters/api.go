@@ -26,11 +26,14 @@ import (
 	"time"
 
 	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/event"
+	"github.com/ethereum/go-ethereum/private"
 	"github.com/ethereum/go-ethereum/rpc"
 )
 
@@ -59,10 +62,22 @@ type PublicFilterAPI struct {
 	events    *EventSystem
 	filtersMu sync.Mutex
 	filters   map[rpc.ID]*filter
+	maxRange  uint64 // Maximum number of blocks a log query may span (0 = unlimited)
+}
+
+// FilterAPIOption configures optional behaviour of a PublicFilterAPI.
+type FilterAPIOption func(*PublicFilterAPI)
+
+// WithRangeLimit limits log queries and resumed subscriptions to span at most
+// the given number of blocks, unless it is 0.
+func WithRangeLimit(blocks uint64) FilterAPIOption {
+	return func(api *PublicFilterAPI) {
+		api.maxRange = blocks
+	}
 }
 
 // NewPublicFilterAPI returns a new PublicFilterAPI instance.
-func NewPublicFilterAPI(backend Backend, lightMode bool) *PublicFilterAPI {
+func NewPublicFilterAPI(backend Backend, lightMode bool, options ...FilterAPIOption) *PublicFilterAPI {
 	api := &PublicFilterAPI{
 		backend: backend,
 		mux:     backend.EventMux(),
@@ -70,6 +85,9 @@ func NewPublicFilterAPI(backend Backend, lightMode bool) *PublicFilterAPI {
 		events:  NewEventSystem(backend.EventMux(), backend, lightMode),
 		filters: make(map[rpc.ID]*filter),
 	}
+	for _, option := range options {
+		option(api)
+	}
 	go api.timeoutLoop()
 
 	return api
@@ -133,9 +151,66 @@ func (api *PublicFilterAPI) NewPendingTransactionFilter() rpc.ID {
 	return pendingTxSub.ID
 }
 
+// PendingTransaction is the notification of a pending transaction subscription
+// requesting full transactions. The private payload is only included for private
+// transactions signed by an account of this node.
+type PendingTransaction struct {
+	Hash           common.Hash     `json:"hash"`
+	From           common.Address  `json:"from"`
+	To             *common.Address `json:"to"`
+	Nonce          hexutil.Uint64  `json:"nonce"`
+	Gas            hexutil.Uint64  `json:"gas"`
+	GasPrice       *hexutil.Big    `json:"gasPrice"`
+	Value          *hexutil.Big    `json:"value"`
+	Input          hexutil.Bytes   `json:"input"`
+	IsPrivate      bool            `json:"isPrivate"`
+	PrivatePayload hexutil.Bytes   `json:"privatePayload,omitempty"`
+}
+
+// newPendingTransaction assembles the notification of a pending transaction,
+// decrypting the payload of private transactions submitted by a local account.
+func (api *PublicFilterAPI) newPendingTransaction(tx *types.Transaction) *PendingTransaction {
+	var signer types.Signer = types.HomesteadSigner{}
+	if tx.Protected() && !tx.IsPrivate() {
+		signer = types.NewEIP155Signer(tx.ChainId())
+	}
+	from, _ := types.Sender(signer, tx)
+
+	pending := &PendingTransaction{
+		Hash:      tx.Hash(),
+		From:      from,
+		To:        tx.To(),
+		Nonce:     hexutil.Uint64(tx.Nonce()),
+		Gas:       hexutil.Uint64(tx.Gas()),
+		GasPrice:  (*hexutil.Big)(tx.GasPrice()),
+		Value:     (*hexutil.Big)(tx.Value()),
+		Input:     tx.Data(),
+		IsPrivate: tx.IsPrivate(),
+	}
+	if tx.IsPrivate() && private.P != nil && api.isLocalAccount(from) {
+		if payload, err := private.P.Receive(tx.Data()); err == nil && len(payload) > 0 {
+			pending.PrivatePayload = payload
+		}
+	}
+	return pending
+}
+
+// isLocalAccount reports whether the address is managed by one of the wallets
+// of the node.
+func (api *PublicFilterAPI) isLocalAccount(addr common.Address) bool {
+	am := api.backend.AccountManager()
+	if am == nil {
+		return false
+	}
+	_, err := am.Find(accounts.Account{Address: addr})
+	return err == nil
+}
+
 // NewPendingTransactions creates a subscription that is triggered each time a transaction
 // enters the transaction pool and was signed from one of the transactions this nodes manages.
-func (api *PublicFilterAPI) NewPendingTransactions(ctx context.Context) (*rpc.Subscription, error) {
+// If fullTx is set, the transactions are sent instead of their hashes, including
+// the decrypted payload of private transactions submitted by this node.
+func (api *PublicFilterAPI) NewPendingTransactions(ctx context.Context, fullTx *bool) (*rpc.Subscription, error) {
 	notifier, supported := rpc.NotifierFromContext(ctx)
 	if !supported {
 		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
@@ -143,6 +218,28 @@ func (api *PublicFilterAPI) NewPendingTransactions(ctx context.Context) (*rpc.Su
 
 	rpcSub := notifier.CreateSubscription()
 
+	if fullTx != nil && *fullTx {
+		go func() {
+			txs := make(chan core.NewTxsEvent, txChanSize)
+			txsSub := api.backend.SubscribeNewTxsEvent(txs)
+			defer txsSub.Unsubscribe()
+
+			for {
+				select {
+				case ev := <-txs:
+					for _, tx := range ev.Txs {
+						notifier.Notify(rpcSub.ID, api.newPendingTransaction(tx))
+					}
+				case <-rpcSub.Err():
+					return
+				case <-notifier.Closed():
+					return
+				}
+			}
+		}()
+		return rpcSub, nil
+	}
+
 	go func() {
 		txHashes := make(chan []common.Hash, 128)
 		pendingTxSub := api.events.SubscribePendingTxs(txHashes)
@@ -204,27 +301,86 @@ func (api *PublicFilterAPI) NewBlockFilter() rpc.ID {
 }
 
 // NewHeads send a notification each time a new (header) block is appended to the chain.
-func (api *PublicFilterAPI) NewHeads(ctx context.Context) (*rpc.Subscription, error) {
+// If the number of the last header seen by the client is given, the headers
+// following it are replayed from the chain before the new ones.
+func (api *PublicFilterAPI) NewHeads(ctx context.Context, lastSeen *rpc.BlockNumber) (*rpc.Subscription, error) {
 	notifier, supported := rpc.NotifierFromContext(ctx)
 	if !supported {
 		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
 	}
 
+	// Refuse replaying more headers than a log query may span.
+	if lastSeen != nil && *lastSeen >= 0 && api.maxRange > 0 {
+		header, _ := api.backend.HeaderByNumber(ctx, rpc.LatestBlockNumber)
+		if header != nil && header.Number.Uint64() > uint64(*lastSeen) {
+			if blocks := header.Number.Uint64() - uint64(*lastSeen); blocks > api.maxRange {
+				return nil, blockRangeError(blocks, api.maxRange)
+			}
+		}
+	}
 	rpcSub := notifier.CreateSubscription()
 
 	go func() {
 		headers := make(chan *types.Header)
 		headersSub := api.events.SubscribeNewHeads(headers)
-
+		defer headersSub.Unsubscribe()
+
+		// Replay the missed headers, collecting the new ones meanwhile up to the
+		// notification limit of the subscription, beyond which it lags. New
+		// headers replayed already are skipped until the first one that wasn't.
+		var (
+			live     []*types.Header
+			next     uint64
+			caughtUp = lastSeen == nil || *lastSeen < 0
+		)
+		if !caughtUp {
+			stop, stopped := make(chan struct{}), make(chan struct{})
+			go func() {
+				defer close(stopped)
+				for {
+					select {
+					case h := <-headers:
+						if len(live) >= notifier.Limit() {
+							notifier.Lag(rpcSub.ID)
+							return
+						}
+						live = append(live, h)
+					case <-stop:
+						return
+					}
+				}
+			}()
+			var alive bool
+			next, alive = api.replayHeaders(notifier, rpcSub, uint64(*lastSeen)+1)
+			close(stop)
+			<-stopped
+			if !alive {
+				return
+			}
+		}
+		notify := func(h *types.Header) bool {
+			if !caughtUp {
+				if h.Number.Uint64() < next {
+					return true
+				}
+				caughtUp = true
+			}
+			return notifier.Notify(rpcSub.ID, h) == nil
+		}
+		for _, h := range live {
+			if !notify(h) {
+				return
+			}
+		}
 		for {
 			select {
 			case h := <-headers:
-				notifier.Notify(rpcSub.ID, h)
+				if !notify(h) {
+					return
+				}
 			case <-rpcSub.Err():
-				headersSub.Unsubscribe()
 				return
 			case <-notifier.Closed():
-				headersSub.Unsubscribe()
 				return
 			}
 		}
@@ -234,6 +390,8 @@ func (api *PublicFilterAPI) NewHeads(ctx context.Context) (*rpc.Subscription, er
 }
 
 // Logs creates a subscription that fires for all new log that match the given filter criteria.
+// If the criteria start at a past block and end at the latest one, the matching logs
+// from that block up to the chain head are replayed before the new ones.
 func (api *PublicFilterAPI) Logs(ctx context.Context, crit FilterCriteria) (*rpc.Subscription, error) {
 	notifier, supported := rpc.NotifierFromContext(ctx)
 	if !supported {
@@ -250,19 +408,88 @@ func (api *PublicFilterAPI) Logs(ctx context.Context, crit FilterCriteria) (*rpc
 		return nil, err
 	}
 
-	go func() {
+	// Determine the past blocks to replay now, refusing ranges beyond the limit.
+	var (
+		replay = crit.FromBlock != nil && crit.FromBlock.Sign() >= 0 &&
+			(crit.ToBlock == nil || crit.ToBlock.Int64() == rpc.LatestBlockNumber.Int64())
+		head uint64
+	)
+	if replay {
+		header, _ := api.backend.HeaderByNumber(ctx, rpc.LatestBlockNumber)
+		if header == nil || header.Number.Uint64() < crit.FromBlock.Uint64() {
+			replay = false
+		} else {
+			head = header.Number.Uint64()
+			if from := crit.FromBlock.Uint64(); api.maxRange > 0 && head-from >= api.maxRange {
+				logsSub.Unsubscribe()
+				return nil, blockRangeError(head-from+1, api.maxRange)
+			}
+		}
+	}
 
+	go func() {
+		defer logsSub.Unsubscribe()
+
+		// Replay the missed logs, collecting the new ones meanwhile up to the
+		// notification limit of the subscription, beyond which it lags. New logs
+		// replayed already are skipped until the first one that wasn't.
+		var (
+			live     [][]*types.Log
+			pending  int
+			caughtUp = !replay
+		)
+		if replay {
+			stop, stopped := make(chan struct{}), make(chan struct{})
+			go func() {
+				defer close(stopped)
+				for {
+					select {
+					case logs := <-matchedLogs:
+						if pending += len(logs); pending > notifier.Limit() {
+							notifier.Lag(rpcSub.ID)
+							return
+						}
+						live = append(live, logs)
+					case <-stop:
+						return
+					}
+				}
+			}()
+			alive := api.replayLogs(notifier, rpcSub, crit, crit.FromBlock.Uint64(), head)
+			close(stop)
+			<-stopped
+			if !alive {
+				return
+			}
+		}
+		notify := func(logs []*types.Log) bool {
+			for _, log := range logs {
+				if !caughtUp {
+					if !log.Removed && log.BlockNumber <= head {
+						continue
+					}
+					caughtUp = true
+				}
+				if notifier.Notify(rpcSub.ID, log) != nil {
+					return false
+				}
+			}
+			return true
+		}
+		for _, logs := range live {
+			if !notify(logs) {
+				return
+			}
+		}
 		for {
 			select {
 			case logs := <-matchedLogs:
-				for _, log := range logs {
-					notifier.Notify(rpcSub.ID, &log)
+				if !notify(logs) {
+					return
 				}
 			case <-rpcSub.Err(): // client send an unsubscribe request
-				logsSub.Unsubscribe()
 				return
 			case <-notifier.Closed(): // connection dropped
-				logsSub.Unsubscribe()
 				return
 			}
 		}
@@ -341,6 +568,8 @@ func (api *PublicFilterAPI) GetLogs(ctx context.Context, crit FilterCriteria) ([
 		// Construct the range filter
 		filter = NewRangeFilter(api.backend, begin, end, crit.Addresses, crit.Topics)
 	}
+	filter.SetPrivacy(crit.Privacy)
+	filter.SetRangeLimit(api.maxRange)
 	// Run the filter and return all the logs
 	logs, err := filter.Logs(ctx)
 	if err != nil {
@@ -396,6 +625,8 @@ func (api *PublicFilterAPI) GetFilterLogs(ctx context.Context, id rpc.ID) ([]*ty
 		// Construct the range filter
 		filter = NewRangeFilter(api.backend, begin, end, f.crit.Addresses, f.crit.Topics)
 	}
+	filter.SetPrivacy(f.crit.Privacy)
+	filter.SetRangeLimit(api.maxRange)
 	// Run the filter and return all the logs
 	logs, err := filter.Logs(ctx)
 	if err != nil {
@@ -464,6 +695,7 @@ func (args *FilterCriteria) UnmarshalJSON(data []byte) error {
 		ToBlock   *rpc.BlockNumber `json:"toBlock"`
 		Addresses interface{}      `json:"address"`
 		Topics    []interface{}    `json:"topics"`
+		Privacy   string           `json:"privacy"`
 	}
 
 	var raw input
@@ -487,6 +719,13 @@ func (args *FilterCriteria) UnmarshalJSON(data []byte) error {
 		}
 	}
 
+	switch raw.Privacy {
+	case "", PrivacyAll, PrivacyPublic, PrivacyPrivate:
+		args.Privacy = raw.Privacy
+	default:
+		return fmt.Errorf("invalid privacy selector %q, expected %q, %q or %q", raw.Privacy, PrivacyPublic, PrivacyPrivate, PrivacyAll)
+	}
+
 	args.Addresses = []common.Address{}
 
 	if raw.Addresses != nil {
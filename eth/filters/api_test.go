@@ -182,4 +182,17 @@ func TestUnmarshalJSONNewFilterArgs(t *testing.T) {
 	if len(test7.Topics[2]) != 0 {
 		t.Fatalf("expected 0 topics, got %d topics", len(test7.Topics[2]))
 	}
+
+	// privacy selector
+	var test8 FilterCriteria
+	if err := json.Unmarshal([]byte(`{"privacy": "private"}`), &test8); err != nil {
+		t.Fatal(err)
+	}
+	if test8.Privacy != PrivacyPrivate {
+		t.Fatalf("expected privacy %q, got %q", PrivacyPrivate, test8.Privacy)
+	}
+	var test9 FilterCriteria
+	if err := json.Unmarshal([]byte(`{"privacy": "secret"}`), &test9); err == nil {
+		t.Fatal("expected error for invalid privacy selector")
+	}
 }
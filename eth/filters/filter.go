@@ -19,8 +19,10 @@ package filters
 import (
 	"context"
 	"errors"
+	"fmt"
 	"math/big"
 
+	"github.com/ethereum/go-ethereum/accounts"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/bloombits"
@@ -30,6 +32,19 @@ import (
 	"github.com/ethereum/go-ethereum/rpc"
 )
 
+// Privacy selectors restricting log filters to public or private transactions.
+const (
+	PrivacyAll     = "all"     // Logs of both public and private transactions
+	PrivacyPublic  = "public"  // Logs of public transactions only
+	PrivacyPrivate = "private" // Logs of private transactions only
+)
+
+// blockRangeError returns the error for log queries spanning more blocks than
+// the node permits.
+func blockRangeError(blocks, limit uint64) error {
+	return rpc.NewLimitExceededError(fmt.Sprintf("query spans %d blocks, exceeding the limit of %d", blocks, limit))
+}
+
 type Backend interface {
 	ChainDb() ethdb.Database
 	EventMux() *event.TypeMux
@@ -45,6 +60,9 @@ type Backend interface {
 
 	BloomStatus() (uint64, uint64)
 	ServiceFilter(ctx context.Context, session *bloombits.MatcherSession)
+
+	// Quorum
+	AccountManager() *accounts.Manager
 }
 
 // Filter can be used to retrieve and filter logs.
@@ -54,6 +72,8 @@ type Filter struct {
 	db        ethdb.Database
 	addresses []common.Address
 	topics    [][]common.Hash
+	privacy   string
+	maxRange  uint64 // Maximum number of blocks to search (0 = unlimited)
 
 	block      common.Hash // Block hash if filtering a single block
 	begin, end int64       // Range interval if filtering multiple blocks
@@ -114,6 +134,16 @@ func newFilter(backend Backend, addresses []common.Address, topics [][]common.Ha
 	}
 }
 
+// SetPrivacy restricts the filter to the logs of public or private transactions.
+func (f *Filter) SetPrivacy(privacy string) {
+	f.privacy = privacy
+}
+
+// SetRangeLimit restricts the number of blocks a range filter may search.
+func (f *Filter) SetRangeLimit(blocks uint64) {
+	f.maxRange = blocks
+}
+
 // Logs searches the blockchain for matching log entries, returning all from the
 // first block that contains matches, updating the start of the filter accordingly.
 func (f *Filter) Logs(ctx context.Context) ([]*types.Log, error) {
@@ -142,6 +172,19 @@ func (f *Filter) Logs(ctx context.Context) ([]*types.Log, error) {
 	if f.end == -1 {
 		end = head
 	}
+	if f.maxRange > 0 {
+		// Only mined blocks are searched, so pending is capped at the head
+		first, last := uint64(f.begin), end
+		if f.begin < 0 {
+			first = head
+		}
+		if f.end < 0 {
+			last = head
+		}
+		if last >= first && last-first >= f.maxRange {
+			return nil, blockRangeError(last-first+1, f.maxRange)
+		}
+	}
 	// Gather all indexed logs, and finish with non indexed ones
 	var (
 		logs []*types.Log
@@ -237,7 +280,9 @@ func (f *Filter) unindexedLogs(ctx context.Context, end uint64) ([]*types.Log, e
 
 // blockLogs returns the logs matching the filter criteria within a single block.
 func (f *Filter) blockLogs(ctx context.Context, header *types.Header) (logs []*types.Log, err error) {
-	if bloomFilter(header.Bloom, f.addresses, f.topics) {
+	bloomMatches := bloomFilter(header.Bloom, f.addresses, f.topics) ||
+		bloomFilter(core.GetPrivateBlockBloom(f.db, header.Number.Uint64()), f.addresses, f.topics)
+	if bloomMatches {
 		found, err := f.checkMatches(ctx, header)
 		if err != nil {
 			return logs, err
@@ -259,7 +304,7 @@ func (f *Filter) checkMatches(ctx context.Context, header *types.Header) (logs [
 	for _, logs := range logsList {
 		unfiltered = append(unfiltered, logs...)
 	}
-	logs = filterLogs(unfiltered, nil, nil, f.addresses, f.topics)
+	logs = filterLogs(unfiltered, nil, nil, f.addresses, f.topics, f.privacy)
 	if len(logs) > 0 {
 		// We have matching logs, check if we need to resolve full logs via the light client
 		if logs[0].TxHash == (common.Hash{}) {
@@ -271,7 +316,7 @@ func (f *Filter) checkMatches(ctx context.Context, header *types.Header) (logs [
 			for _, receipt := range receipts {
 				unfiltered = append(unfiltered, receipt.Logs...)
 			}
-			logs = filterLogs(unfiltered, nil, nil, f.addresses, f.topics)
+			logs = filterLogs(unfiltered, nil, nil, f.addresses, f.topics, f.privacy)
 		}
 		return logs, nil
 	}
@@ -289,10 +334,13 @@ func includes(addresses []common.Address, a common.Address) bool {
 }
 
 // filterLogs creates a slice of logs matching the given criteria.
-func filterLogs(logs []*types.Log, fromBlock, toBlock *big.Int, addresses []common.Address, topics [][]common.Hash) []*types.Log {
+func filterLogs(logs []*types.Log, fromBlock, toBlock *big.Int, addresses []common.Address, topics [][]common.Hash, privacy string) []*types.Log {
 	var ret []*types.Log
 Logs:
 	for _, log := range logs {
+		if (privacy == PrivacyPublic && log.IsPrivate) || (privacy == PrivacyPrivate && !log.IsPrivate) {
+			continue
+		}
 		if fromBlock != nil && fromBlock.Int64() >= 0 && fromBlock.Uint64() > log.BlockNumber {
 			continue
 		}
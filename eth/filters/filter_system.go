@@ -326,14 +326,14 @@ func (es *EventSystem) broadcast(filters filterIndex, ev interface{}) {
 	case []*types.Log:
 		if len(e) > 0 {
 			for _, f := range filters[LogsSubscription] {
-				if matchedLogs := filterLogs(e, f.logsCrit.FromBlock, f.logsCrit.ToBlock, f.logsCrit.Addresses, f.logsCrit.Topics); len(matchedLogs) > 0 {
+				if matchedLogs := filterLogs(e, f.logsCrit.FromBlock, f.logsCrit.ToBlock, f.logsCrit.Addresses, f.logsCrit.Topics, f.logsCrit.Privacy); len(matchedLogs) > 0 {
 					f.logs <- matchedLogs
 				}
 			}
 		}
 	case core.RemovedLogsEvent:
 		for _, f := range filters[LogsSubscription] {
-			if matchedLogs := filterLogs(e.Logs, f.logsCrit.FromBlock, f.logsCrit.ToBlock, f.logsCrit.Addresses, f.logsCrit.Topics); len(matchedLogs) > 0 {
+			if matchedLogs := filterLogs(e.Logs, f.logsCrit.FromBlock, f.logsCrit.ToBlock, f.logsCrit.Addresses, f.logsCrit.Topics, f.logsCrit.Privacy); len(matchedLogs) > 0 {
 				f.logs <- matchedLogs
 			}
 		}
@@ -341,7 +341,7 @@ func (es *EventSystem) broadcast(filters filterIndex, ev interface{}) {
 		if muxe, ok := e.Data.(core.PendingLogsEvent); ok {
 			for _, f := range filters[PendingLogsSubscription] {
 				if e.Time.After(f.created) {
-					if matchedLogs := filterLogs(muxe.Logs, nil, f.logsCrit.ToBlock, f.logsCrit.Addresses, f.logsCrit.Topics); len(matchedLogs) > 0 {
+					if matchedLogs := filterLogs(muxe.Logs, nil, f.logsCrit.ToBlock, f.logsCrit.Addresses, f.logsCrit.Topics, f.logsCrit.Privacy); len(matchedLogs) > 0 {
 						f.logs <- matchedLogs
 					}
 				}
@@ -362,7 +362,7 @@ func (es *EventSystem) broadcast(filters filterIndex, ev interface{}) {
 		if es.lightMode && len(filters[LogsSubscription]) > 0 {
 			es.lightFilterNewHead(e.Block.Header(), func(header *types.Header, remove bool) {
 				for _, f := range filters[LogsSubscription] {
-					if matchedLogs := es.lightFilterLogs(header, f.logsCrit.Addresses, f.logsCrit.Topics, remove); len(matchedLogs) > 0 {
+					if matchedLogs := es.lightFilterLogs(header, f.logsCrit.Addresses, f.logsCrit.Topics, f.logsCrit.Privacy, remove); len(matchedLogs) > 0 {
 						f.logs <- matchedLogs
 					}
 				}
@@ -405,7 +405,7 @@ func (es *EventSystem) lightFilterNewHead(newHeader *types.Header, callBack func
 }
 
 // filter logs of a single header in light client mode
-func (es *EventSystem) lightFilterLogs(header *types.Header, addresses []common.Address, topics [][]common.Hash, remove bool) []*types.Log {
+func (es *EventSystem) lightFilterLogs(header *types.Header, addresses []common.Address, topics [][]common.Hash, privacy string, remove bool) []*types.Log {
 	if bloomFilter(header.Bloom, addresses, topics) {
 		// Get the logs of the block
 		ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
@@ -422,7 +422,7 @@ func (es *EventSystem) lightFilterLogs(header *types.Header, addresses []common.
 				unfiltered = append(unfiltered, &logcopy)
 			}
 		}
-		logs := filterLogs(unfiltered, nil, nil, addresses, topics)
+		logs := filterLogs(unfiltered, nil, nil, addresses, topics, privacy)
 		if len(logs) > 0 && logs[0].TxHash == (common.Hash{}) {
 			// We have matching but non-derived logs
 			receipts, err := es.backend.GetReceipts(ctx, header.Hash())
@@ -437,7 +437,7 @@ func (es *EventSystem) lightFilterLogs(header *types.Header, addresses []common.
 					unfiltered = append(unfiltered, &logcopy)
 				}
 			}
-			logs = filterLogs(unfiltered, nil, nil, addresses, topics)
+			logs = filterLogs(unfiltered, nil, nil, addresses, topics, privacy)
 		}
 		return logs
 	}
@@ -21,6 +21,7 @@ import (
 	"io/ioutil"
 	"math/big"
 	"os"
+	"reflect"
 	"testing"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -230,3 +231,25 @@ func TestFilters(t *testing.T) {
 		t.Error("expected 0 log, got", len(logs))
 	}
 }
+
+func TestFilterLogsPrivacy(t *testing.T) {
+	var (
+		publicLog  = &types.Log{Address: common.HexToAddress("0x1111111111111111111111111111111111111111")}
+		privateLog = &types.Log{Address: common.HexToAddress("0x2222222222222222222222222222222222222222"), IsPrivate: true}
+		logs       = []*types.Log{publicLog, privateLog}
+	)
+	tests := []struct {
+		privacy string
+		want    []*types.Log
+	}{
+		{"", logs},
+		{PrivacyAll, logs},
+		{PrivacyPublic, []*types.Log{publicLog}},
+		{PrivacyPrivate, []*types.Log{privateLog}},
+	}
+	for i, tt := range tests {
+		if have := filterLogs(logs, nil, nil, nil, nil, tt.privacy); !reflect.DeepEqual(have, tt.want) {
+			t.Errorf("test %d: privacy %q: have %v, want %v", i, tt.privacy, have, tt.want)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package filters
+
+import (
+	"context"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+const (
+	// replayBacklog is the number of notifications waiting to be written to a
+	// client at which replaying past events pauses until the client caught up.
+	replayBacklog = 256
+
+	// replayChunk is the number of blocks searched at once when replaying logs.
+	replayChunk = 1024
+)
+
+// awaitBacklog blocks until fewer than replayBacklog notifications of the
+// subscription are waiting to be written, returning false if it ended meanwhile.
+func awaitBacklog(notifier *rpc.Notifier, sub *rpc.Subscription) bool {
+	for {
+		pending, drained := notifier.Backlog(sub.ID)
+		if pending < replayBacklog {
+			return true
+		}
+		select {
+		case <-drained:
+		case <-sub.Err():
+			return false
+		case <-notifier.Closed():
+			return false
+		}
+	}
+}
+
+// replayHeaders notifies the canonical headers from the given number on until
+// reaching the chain head, paced to the client. It returns the number following
+// the last header replayed and whether the subscription is still alive.
+func (api *PublicFilterAPI) replayHeaders(notifier *rpc.Notifier, sub *rpc.Subscription, from uint64) (uint64, bool) {
+	next := from
+	for ; ; next++ {
+		if !awaitBacklog(notifier, sub) {
+			return next, false
+		}
+		header, err := api.backend.HeaderByNumber(context.Background(), rpc.BlockNumber(next))
+		if err != nil || header == nil {
+			return next, true
+		}
+		if notifier.Notify(sub.ID, header) != nil {
+			return next, false
+		}
+	}
+}
+
+// replayLogs notifies the logs matching the criteria within the given block
+// range, searched in chunks and paced to the client. It returns whether the
+// subscription is still alive.
+func (api *PublicFilterAPI) replayLogs(notifier *rpc.Notifier, sub *rpc.Subscription, crit FilterCriteria, begin, end uint64) bool {
+	for from := begin; from <= end; from += replayChunk {
+		to := from + replayChunk - 1
+		if to > end {
+			to = end
+		}
+		filter := NewRangeFilter(api.backend, int64(from), int64(to), crit.Addresses, crit.Topics)
+		filter.SetPrivacy(crit.Privacy)
+
+		logs, err := filter.Logs(context.Background())
+		if err != nil {
+			log.Warn("Failed to replay subscribed logs", "from", from, "to", to, "err", err)
+			return false
+		}
+		for _, l := range logs {
+			if !awaitBacklog(notifier, sub) || notifier.Notify(sub.ID, l) != nil {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,195 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package filters
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/event"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// newReplayTestAPI creates a filter API over a canonical chain of blocks
+// generated by gen, serving it to an in-process client.
+func newReplayTestAPI(t *testing.T, blocks int, maxRange uint64, gen func(int, *core.BlockGen)) (*testBackend, []*types.Block, *rpc.Client) {
+	var (
+		db      = ethdb.NewMemDatabase()
+		backend = &testBackend{new(event.TypeMux), db, 0, new(event.Feed), new(event.Feed), new(event.Feed), new(event.Feed)}
+		genesis = new(core.Genesis).MustCommit(db)
+	)
+	chain, receipts := core.GenerateChain(params.TestChainConfig, genesis, ethash.NewFaker(), db, blocks, gen)
+	for i, block := range chain {
+		rawdb.WriteBlock(db, block)
+		rawdb.WriteCanonicalHash(db, block.Hash(), block.NumberU64())
+		rawdb.WriteHeadBlockHash(db, block.Hash())
+		rawdb.WriteReceipts(db, block.Hash(), block.NumberU64(), receipts[i])
+	}
+	server := rpc.NewServer()
+	if err := server.RegisterName("eth", NewPublicFilterAPI(backend, false, WithRangeLimit(maxRange))); err != nil {
+		t.Fatalf("failed to register filter API: %v", err)
+	}
+	return backend, chain, rpc.DialInProc(server)
+}
+
+func TestResumeNewHeads(t *testing.T) {
+	t.Parallel()
+
+	backend, chain, client := newReplayTestAPI(t, 10, 0, func(int, *core.BlockGen) {})
+	defer client.Close()
+
+	// Resume after block 4 and expect blocks 5 to 10 replayed.
+	headers := make(chan *types.Header)
+	sub, err := client.EthSubscribe(context.Background(), headers, "newHeads", "0x4")
+	if err != nil {
+		t.Fatalf("failed to subscribe: %v", err)
+	}
+	defer sub.Unsubscribe()
+
+	next := func() *types.Header {
+		select {
+		case header := <-headers:
+			return header
+		case err := <-sub.Err():
+			t.Fatalf("subscription failed: %v", err)
+		case <-time.After(2 * time.Second):
+			t.Fatal("header not notified in time")
+		}
+		return nil
+	}
+	for i := 4; i < len(chain); i++ {
+		if header := next(); header.Hash() != chain[i].Hash() {
+			t.Fatalf("replayed header %d mismatch: have %x, want %x", i+1, header.Hash(), chain[i].Hash())
+		}
+	}
+	// A new header replayed already is skipped, following ones are notified.
+	extended, _ := core.GenerateChain(params.TestChainConfig, chain[len(chain)-1], ethash.NewFaker(), backend.db, 1, func(int, *core.BlockGen) {})
+	backend.chainFeed.Send(core.ChainEvent{Hash: chain[len(chain)-1].Hash(), Block: chain[len(chain)-1]})
+	backend.chainFeed.Send(core.ChainEvent{Hash: extended[0].Hash(), Block: extended[0]})
+
+	if header := next(); header.Hash() != extended[0].Hash() {
+		t.Fatalf("new header mismatch: have %x, want %x", header.Hash(), extended[0].Hash())
+	}
+}
+
+func TestResumeLogs(t *testing.T) {
+	t.Parallel()
+
+	var (
+		addr   = common.HexToAddress("0x0000000000000000000000000000000000000100")
+		topics = []common.Hash{common.BytesToHash([]byte("topic1")), common.BytesToHash([]byte("topic2")), common.BytesToHash([]byte("topic3"))}
+	)
+	backend, _, client := newReplayTestAPI(t, 10, 0, func(i int, gen *core.BlockGen) {
+		if i == 2 || i == 7 {
+			receipt := types.NewReceipt(nil, false, 0)
+			receipt.Logs = []*types.Log{{Address: addr, Topics: []common.Hash{topics[i/5]}}}
+			gen.AddUncheckedReceipt(receipt)
+		}
+	})
+	defer client.Close()
+
+	// Resume from block 5 and expect the log of block 8 replayed only.
+	logs := make(chan types.Log)
+	crit := map[string]interface{}{"fromBlock": "0x5", "address": addr}
+	sub, err := client.EthSubscribe(context.Background(), logs, "logs", crit)
+	if err != nil {
+		t.Fatalf("failed to subscribe: %v", err)
+	}
+	defer sub.Unsubscribe()
+
+	next := func() types.Log {
+		select {
+		case log := <-logs:
+			return log
+		case err := <-sub.Err():
+			t.Fatalf("subscription failed: %v", err)
+		case <-time.After(2 * time.Second):
+			t.Fatal("log not notified in time")
+		}
+		return types.Log{}
+	}
+	if log := next(); log.Topics[0] != topics[1] {
+		t.Fatalf("replayed log mismatch: have topic %x, want %x", log.Topics[0], topics[1])
+	}
+	// A new log replayed already is skipped, following ones are notified.
+	backend.logsFeed.Send([]*types.Log{{Address: addr, Topics: []common.Hash{topics[1]}, BlockNumber: 8}})
+	backend.logsFeed.Send([]*types.Log{{Address: addr, Topics: []common.Hash{topics[2]}, BlockNumber: 11}})
+
+	if log := next(); log.Topics[0] != topics[2] || log.BlockNumber != 11 {
+		t.Fatalf("new log mismatch: have topic %x in block %d, want %x in block 11", log.Topics[0], log.BlockNumber, topics[2])
+	}
+}
+
+func TestResumeLogsRangeLimit(t *testing.T) {
+	t.Parallel()
+
+	_, _, client := newReplayTestAPI(t, 10, 5, func(int, *core.BlockGen) {})
+	defer client.Close()
+
+	crit := map[string]interface{}{"fromBlock": "0x2"}
+	if _, err := client.EthSubscribe(context.Background(), make(chan types.Log), "logs", crit); err == nil {
+		t.Fatal("subscription replaying beyond the block range limit succeeded")
+	}
+	crit = map[string]interface{}{"fromBlock": "0x6"}
+	sub, err := client.EthSubscribe(context.Background(), make(chan types.Log), "logs", crit)
+	if err != nil {
+		t.Fatalf("subscription replaying within the block range limit failed: %v", err)
+	}
+	sub.Unsubscribe()
+}
+
+func TestResumeNewHeadsRangeLimit(t *testing.T) {
+	t.Parallel()
+
+	_, _, client := newReplayTestAPI(t, 10, 5, func(int, *core.BlockGen) {})
+	defer client.Close()
+
+	if _, err := client.EthSubscribe(context.Background(), make(chan *types.Header), "newHeads", "0x4"); err == nil {
+		t.Fatal("subscription replaying beyond the block range limit succeeded")
+	}
+	sub, err := client.EthSubscribe(context.Background(), make(chan *types.Header), "newHeads", "0x5")
+	if err != nil {
+		t.Fatalf("subscription replaying within the block range limit failed: %v", err)
+	}
+	sub.Unsubscribe()
+}
+
+func TestGetLogsRangeLimit(t *testing.T) {
+	t.Parallel()
+
+	_, _, client := newReplayTestAPI(t, 10, 5, func(int, *core.BlockGen) {})
+	defer client.Close()
+
+	var logs []*types.Log
+	crit := map[string]interface{}{"fromBlock": "0x2", "toBlock": "latest"}
+	err := client.Call(&logs, "eth_getLogs", crit)
+	if coded, ok := err.(rpc.Error); !ok || coded.ErrorCode() != -32005 {
+		t.Fatalf("query beyond the block range limit: have error %v, want limit exceeded", err)
+	}
+	crit = map[string]interface{}{"fromBlock": "0x8", "toBlock": "pending"}
+	if err := client.Call(&logs, "eth_getLogs", crit); err != nil {
+		t.Fatalf("query up to the pending block within the range limit failed: %v", err)
+	}
+}
@@ -20,31 +20,39 @@ var _ = (*configMarshaling)(nil)
 // MarshalTOML marshals as TOML.
 func (c Config) MarshalTOML() (interface{}, error) {
 	type Config struct {
-		Genesis                 *core.Genesis `toml:",omitempty"`
-		NetworkId               uint64
-		SyncMode                downloader.SyncMode
-		NoPruning               bool
-		LightServ               int  `toml:",omitempty"`
-		LightPeers              int  `toml:",omitempty"`
-		SkipBcVersionCheck      bool `toml:"-"`
-		DatabaseHandles         int  `toml:"-"`
-		DatabaseCache           int
-		TrieCache               int
-		TrieTimeout             time.Duration
-		Etherbase               common.Address `toml:",omitempty"`
-		MinerNotify             []string       `toml:",omitempty"`
-		MinerExtraData          hexutil.Bytes  `toml:",omitempty"`
-		MinerGasFloor           uint64
-		MinerGasCeil            uint64
-		MinerGasPrice           *big.Int
-		MinerRecommit           time.Duration
-		MinerNoverify           bool
-		Ethash                  ethash.Config
-		TxPool                  core.TxPoolConfig
-		GPO                     gasprice.Config
-		EnablePreimageRecording bool
-		Istanbul                istanbul.Config
-		DocRoot                 string `toml:"-"`
+		Genesis                       *core.Genesis `toml:",omitempty"`
+		NetworkId                     uint64
+		SyncMode                      downloader.SyncMode
+		NoPruning                     bool
+		LightServ                     int  `toml:",omitempty"`
+		LightPeers                    int  `toml:",omitempty"`
+		SkipBcVersionCheck            bool `toml:"-"`
+		DatabaseHandles               int  `toml:"-"`
+		DatabaseCache                 int
+		TrieCache                     int
+		TrieTimeout                   time.Duration
+		Etherbase                     common.Address `toml:",omitempty"`
+		MinerNotify                   []string       `toml:",omitempty"`
+		MinerExtraData                hexutil.Bytes  `toml:",omitempty"`
+		MinerGasFloor                 uint64
+		MinerGasCeil                  uint64
+		MinerGasPrice                 *big.Int
+		MinerRecommit                 time.Duration
+		MinerNoverify                 bool
+		Ethash                        ethash.Config
+		TxPool                        core.TxPoolConfig
+		GPO                           gasprice.Config
+		LogsBlockRange                uint64
+		EnablePreimageRecording       bool
+		SaveRevertReason              bool
+		PrivateStateChecksums         bool
+		PrivateSimulation             bool
+		PrivateSimulationTimeout      time.Duration
+		PrivateTxManagerCheckInterval time.Duration
+		PrivateTxManagerPauseImport   bool
+		PrivateTxManagerRejectPrivate bool
+		Istanbul                      istanbul.Config
+		DocRoot                       string `toml:"-"`
 	}
 	var enc Config
 	enc.Genesis = c.Genesis
@@ -69,7 +77,15 @@ func (c Config) MarshalTOML() (interface{}, error) {
 	enc.Ethash = c.Ethash
 	enc.TxPool = c.TxPool
 	enc.GPO = c.GPO
+	enc.LogsBlockRange = c.LogsBlockRange
 	enc.EnablePreimageRecording = c.EnablePreimageRecording
+	enc.SaveRevertReason = c.SaveRevertReason
+	enc.PrivateStateChecksums = c.PrivateStateChecksums
+	enc.PrivateSimulation = c.PrivateSimulation
+	enc.PrivateSimulationTimeout = c.PrivateSimulationTimeout
+	enc.PrivateTxManagerCheckInterval = c.PrivateTxManagerCheckInterval
+	enc.PrivateTxManagerPauseImport = c.PrivateTxManagerPauseImport
+	enc.PrivateTxManagerRejectPrivate = c.PrivateTxManagerRejectPrivate
 	enc.Istanbul = c.Istanbul
 	enc.DocRoot = c.DocRoot
 	return &enc, nil
@@ -78,31 +94,39 @@ func (c Config) MarshalTOML() (interface{}, error) {
 // UnmarshalTOML unmarshals from TOML.
 func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 	type Config struct {
-		Genesis                 *core.Genesis `toml:",omitempty"`
-		NetworkId               *uint64
-		SyncMode                *downloader.SyncMode
-		NoPruning               *bool
-		LightServ               *int  `toml:",omitempty"`
-		LightPeers              *int  `toml:",omitempty"`
-		SkipBcVersionCheck      *bool `toml:"-"`
-		DatabaseHandles         *int  `toml:"-"`
-		DatabaseCache           *int
-		TrieCache               *int
-		TrieTimeout             *time.Duration
-		Etherbase               *common.Address `toml:",omitempty"`
-		MinerNotify             []string        `toml:",omitempty"`
-		MinerExtraData          *hexutil.Bytes  `toml:",omitempty"`
-		MinerGasFloor           *uint64
-		MinerGasCeil            *uint64
-		MinerGasPrice           *big.Int
-		MinerRecommit           *time.Duration
-		MinerNoverify           *bool
-		Ethash                  *ethash.Config
-		TxPool                  *core.TxPoolConfig
-		GPO                     *gasprice.Config
-		EnablePreimageRecording *bool
-		Istanbul                *istanbul.Config
-		DocRoot                 *string `toml:"-"`
+		Genesis                       *core.Genesis `toml:",omitempty"`
+		NetworkId                     *uint64
+		SyncMode                      *downloader.SyncMode
+		NoPruning                     *bool
+		LightServ                     *int  `toml:",omitempty"`
+		LightPeers                    *int  `toml:",omitempty"`
+		SkipBcVersionCheck            *bool `toml:"-"`
+		DatabaseHandles               *int  `toml:"-"`
+		DatabaseCache                 *int
+		TrieCache                     *int
+		TrieTimeout                   *time.Duration
+		Etherbase                     *common.Address `toml:",omitempty"`
+		MinerNotify                   []string        `toml:",omitempty"`
+		MinerExtraData                *hexutil.Bytes  `toml:",omitempty"`
+		MinerGasFloor                 *uint64
+		MinerGasCeil                  *uint64
+		MinerGasPrice                 *big.Int
+		MinerRecommit                 *time.Duration
+		MinerNoverify                 *bool
+		Ethash                        *ethash.Config
+		TxPool                        *core.TxPoolConfig
+		GPO                           *gasprice.Config
+		LogsBlockRange                *uint64
+		EnablePreimageRecording       *bool
+		SaveRevertReason              *bool
+		PrivateStateChecksums         *bool
+		PrivateSimulation             *bool
+		PrivateSimulationTimeout      *time.Duration
+		PrivateTxManagerCheckInterval *time.Duration
+		PrivateTxManagerPauseImport   *bool
+		PrivateTxManagerRejectPrivate *bool
+		Istanbul                      *istanbul.Config
+		DocRoot                       *string `toml:"-"`
 	}
 	var dec Config
 	if err := unmarshal(&dec); err != nil {
@@ -174,9 +198,33 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 	if dec.GPO != nil {
 		c.GPO = *dec.GPO
 	}
+	if dec.LogsBlockRange != nil {
+		c.LogsBlockRange = *dec.LogsBlockRange
+	}
 	if dec.EnablePreimageRecording != nil {
 		c.EnablePreimageRecording = *dec.EnablePreimageRecording
 	}
+	if dec.SaveRevertReason != nil {
+		c.SaveRevertReason = *dec.SaveRevertReason
+	}
+	if dec.PrivateStateChecksums != nil {
+		c.PrivateStateChecksums = *dec.PrivateStateChecksums
+	}
+	if dec.PrivateSimulation != nil {
+		c.PrivateSimulation = *dec.PrivateSimulation
+	}
+	if dec.PrivateSimulationTimeout != nil {
+		c.PrivateSimulationTimeout = *dec.PrivateSimulationTimeout
+	}
+	if dec.PrivateTxManagerCheckInterval != nil {
+		c.PrivateTxManagerCheckInterval = *dec.PrivateTxManagerCheckInterval
+	}
+	if dec.PrivateTxManagerPauseImport != nil {
+		c.PrivateTxManagerPauseImport = *dec.PrivateTxManagerPauseImport
+	}
+	if dec.PrivateTxManagerRejectPrivate != nil {
+		c.PrivateTxManagerRejectPrivate = *dec.PrivateTxManagerRejectPrivate
+	}
 	if dec.Istanbul != nil {
 		c.Istanbul = *dec.Istanbul
 	}
@@ -722,7 +722,14 @@ func (pm *ProtocolManager) handleMsg(p *peer) error {
 			}
 			p.MarkTransaction(tx.Hash())
 		}
-		pm.txpool.AddRemotes(txs)
+		// Let the pool enforce its ingress limits on the peer, if it has any
+		if pool, ok := pm.txpool.(interface {
+			AddRemotesFromPeer(peer string, txs []*types.Transaction) []error
+		}); ok {
+			pool.AddRemotesFromPeer(p.id, txs)
+		} else {
+			pm.txpool.AddRemotes(txs)
+		}
 
 	default:
 		return errResp(ErrInvalidMsgCode, "%v", msg.Code)
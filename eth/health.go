@@ -0,0 +1,94 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package eth
+
+import (
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/consensus"
+	istanbulBackend "github.com/ethereum/go-ethereum/consensus/istanbul/backend"
+	"github.com/ethereum/go-ethereum/node"
+)
+
+// participationReporter is implemented by consensus engines able to report how
+// their validators took part in committing recent blocks.
+type participationReporter interface {
+	Participation(chain consensus.ChainReader, blocks uint64) (*istanbulBackend.Participation, error)
+}
+
+// HealthChecks implements node.HealthChecker, reporting the synchronisation of
+// the chain, the reachability of the private transaction manager and the
+// participation of Istanbul validators.
+func (s *Ethereum) HealthChecks(config *node.HealthConfig) map[string]*node.HealthCheck {
+	checks := map[string]*node.HealthCheck{
+		"sync": s.syncCheck(config),
+	}
+	if s.ptmMonitor != nil {
+		if status := s.ptmMonitor.Status(); status.Configured {
+			checks["txmanager"] = &node.HealthCheck{Healthy: status.Up, Ready: status.Up, Message: status.Error, Details: status}
+		}
+	}
+	if reporter, ok := s.engine.(participationReporter); ok {
+		checks["istanbul"] = participationCheck(reporter, s.blockchain, config)
+	}
+	return checks
+}
+
+// syncCheck reports the node ready unless it is more than the configured number
+// of blocks behind the best chain known from its peers.
+func (s *Ethereum) syncCheck(config *node.HealthConfig) *node.HealthCheck {
+	var (
+		progress = s.Downloader().Progress()
+		current  = s.blockchain.CurrentBlock().NumberU64()
+		behind   uint64
+	)
+	if progress.HighestBlock > current {
+		behind = progress.HighestBlock - current
+	}
+	check := &node.HealthCheck{
+		Healthy: true,
+		Ready:   behind <= config.MaxBlocksBehind,
+		Details: map[string]interface{}{
+			"syncing":      s.Downloader().Synchronising(),
+			"currentBlock": current,
+			"highestBlock": progress.HighestBlock,
+			"behind":       behind,
+		},
+	}
+	if !check.Ready {
+		check.Message = fmt.Sprintf("%d blocks behind, want at most %d", behind, config.MaxBlocksBehind)
+	}
+	return check
+}
+
+// participationCheck reports the node ready if enough Istanbul validators took
+// part in committing the recent blocks.
+func participationCheck(reporter participationReporter, chain consensus.ChainReader, config *node.HealthConfig) *node.HealthCheck {
+	participation, err := reporter.Participation(chain, config.ParticipationBlocks)
+	if err != nil {
+		return &node.HealthCheck{Message: err.Error()}
+	}
+	check := &node.HealthCheck{Healthy: true, Ready: true, Details: participation}
+	if participation.Blocks > 0 && participation.Validators > 0 {
+		ratio := float64(participation.Committers) / float64(participation.Validators)
+		if ratio < config.MinParticipation {
+			check.Ready = false
+			check.Message = fmt.Sprintf("%d of %d validators committed the last %d blocks, want at least %.0f%%", participation.Committers, participation.Validators, participation.Blocks, config.MinParticipation*100)
+		}
+	}
+	return check
+}
@@ -400,6 +400,9 @@ func toFilterArg(q ethereum.FilterQuery) (interface{}, error) {
 		}
 		arg["toBlock"] = toBlockNumArg(q.ToBlock)
 	}
+	if q.Privacy != "" {
+		arg["privacy"] = q.Privacy
+	}
 	return arg, nil
 }
 
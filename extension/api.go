@@ -0,0 +1,157 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package extension
+
+import (
+	"context"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/internal/ethapi"
+)
+
+// PrivateExtensionAPI offers the contract extension workflow to local accounts.
+type PrivateExtensionAPI struct {
+	service *Service
+}
+
+// NewPrivateExtensionAPI creates the RPC API of a contract extension service.
+func NewPrivateExtensionAPI(service *Service) *PrivateExtensionAPI {
+	return &PrivateExtensionAPI{service}
+}
+
+// ExtendContract proposes to share the private contract toExtend with the party
+// holding the given transaction manager key. Every current party has to approve
+// before the contract state is shared: voters holds the approving account of
+// each party in txa.PrivateFor, in the same order, next to the new party's
+// account. The proposal is sent from txa.From to txa.PrivateFrom, txa.PrivateFor
+// and the new party, which are the parties all further management transactions
+// go to.
+func (api *PrivateExtensionAPI) ExtendContract(ctx context.Context, toExtend common.Address, newRecipientPtmPublicKey string, recipientAddress common.Address, voters []common.Address, txa ethapi.SendTxArgs) (common.Hash, error) {
+	if txa.PrivateFrom == "" {
+		return common.Hash{}, errNoPrivateFrom
+	}
+	_, privateState, err := api.service.chain.StateAt(api.service.chain.CurrentBlock().Root())
+	if err != nil {
+		return common.Hash{}, err
+	}
+	if privateState.GetCodeSize(toExtend) == 0 {
+		return common.Hash{}, errUnknownContract
+	}
+	if ext := core.ReadContractExtension(privateState, toExtend); ext != nil && ext.Active() {
+		return common.Hash{}, errExtensionExists
+	}
+	if len(voters) != len(txa.PrivateFor) {
+		return common.Hash{}, errVotersMismatch
+	}
+	parties, partyVoters := []string{txa.PrivateFrom}, []common.Address{txa.From}
+	for i, party := range txa.PrivateFor {
+		if party != newRecipientPtmPublicKey && !containsString(parties, party) {
+			parties, partyVoters = append(parties, party), append(partyVoters, voters[i])
+		}
+	}
+	parties, partyVoters = append(parties, newRecipientPtmPublicKey), append(partyVoters, recipientAddress)
+
+	action := &core.ExtensionAction{
+		Op:           core.ExtensionPropose,
+		Contract:     toExtend,
+		Recipient:    recipientAddress,
+		RecipientKey: newRecipientPtmPublicKey,
+		ProposerKey:  txa.PrivateFrom,
+		Parties:      parties,
+		Voters:       partyVoters,
+	}
+	return api.service.sendAction(ctx, txa, parties, action)
+}
+
+// ApproveExtension votes from txa.From on the active extension of a contract.
+// A single rejection closes the extension.
+func (api *PrivateExtensionAPI) ApproveExtension(ctx context.Context, addressToVoteOn common.Address, vote bool, txa ethapi.SendTxArgs) (common.Hash, error) {
+	ext, err := api.activeExtension(addressToVoteOn)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	action := &core.ExtensionAction{
+		Op:       core.ExtensionVote,
+		Contract: addressToVoteOn,
+		Approve:  vote,
+	}
+	return api.service.sendAction(ctx, txa, ext.Parties, action)
+}
+
+// CancelExtension withdraws the active extension of a contract. Only the
+// proposer may cancel an extension.
+func (api *PrivateExtensionAPI) CancelExtension(ctx context.Context, extensionContract common.Address, txa ethapi.SendTxArgs) (common.Hash, error) {
+	ext, err := api.activeExtension(extensionContract)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	action := &core.ExtensionAction{
+		Op:       core.ExtensionCancel,
+		Contract: extensionContract,
+	}
+	return api.service.sendAction(ctx, txa, ext.Parties, action)
+}
+
+// ActiveExtension is an extension still waiting for votes or for the contract
+// state to be shared, along with the reason the last attempt of this node to
+// share the state failed, if it did.
+type ActiveExtension struct {
+	*core.ContractExtension
+	ShareError string `json:"shareError,omitempty"`
+}
+
+// ActiveExtensionContracts returns the extensions this node is party to that are
+// still waiting for votes or for the contract state to be shared.
+func (api *PrivateExtensionAPI) ActiveExtensionContracts() ([]*ActiveExtension, error) {
+	_, privateState, err := api.service.chain.StateAt(api.service.chain.CurrentBlock().Root())
+	if err != nil {
+		return nil, err
+	}
+	active := make([]*ActiveExtension, 0)
+	for _, ext := range core.ReadContractExtensions(privateState) {
+		if !ext.Active() {
+			continue
+		}
+		entry := &ActiveExtension{ContractExtension: ext}
+		if err := api.service.shareError(ext.Contract); err != nil {
+			entry.ShareError = err.Error()
+		}
+		active = append(active, entry)
+	}
+	return active, nil
+}
+
+func (api *PrivateExtensionAPI) activeExtension(contract common.Address) (*core.ContractExtension, error) {
+	ext, err := api.service.latestExtension(contract)
+	if err != nil {
+		return nil, err
+	}
+	if ext == nil || !ext.Active() {
+		return nil, errNoExtension
+	}
+	return ext, nil
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
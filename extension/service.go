@@ -0,0 +1,280 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package extension implements the workflow for sharing an existing private
+// contract with a new party.
+//
+// An extension is proposed, voted on and cancelled with private transactions to
+// the native management contract at core.ContractExtensionAddress, shared with
+// all current parties and the new one. Once every voter approved, the node of
+// the proposer sends a snapshot of the contract's private state at the approval
+// block to the new party through the transaction manager, and records its hash
+// in a final management transaction. Processing that transaction applies the
+// snapshot to the private state of the new party.
+package extension
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+	"github.com/ethereum/go-ethereum/internal/ethapi"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/private"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+const chainEventChanSize = 10
+
+var (
+	errNoTransactionManager = errors.New("no private transaction manager configured")
+	errUnknownContract      = errors.New("contract not found in private state")
+	errNoExtension          = errors.New("no active extension for contract")
+	errExtensionExists      = errors.New("contract extension already in progress")
+	errNoPrivateFrom        = errors.New("privateFrom is required to propose an extension")
+	errVotersMismatch       = errors.New("one voter required per privateFor party")
+)
+
+// Service shares the state of extended contracts proposed by local accounts
+// once their extension has been approved. Failed attempts are retried on every
+// imported block until the state is shared.
+type Service struct {
+	chain   *core.BlockChain
+	backend ethapi.Backend
+	txapi   *ethapi.PublicTransactionPoolAPI
+
+	unshared map[common.Address]error // Approved local extensions yet to be shared, with the last error
+	lock     sync.Mutex
+
+	chainCh  chan core.ChainEvent
+	chainSub event.Subscription
+	wg       sync.WaitGroup
+}
+
+// New creates a contract extension service on top of the given chain, sending
+// transactions under the nonce lock of the node's RPC APIs.
+func New(chain *core.BlockChain, backend ethapi.Backend, nonceLock *ethapi.AddrLocker) *Service {
+	return &Service{
+		chain:    chain,
+		backend:  backend,
+		txapi:    ethapi.NewPublicTransactionPoolAPI(backend, nonceLock),
+		unshared: make(map[common.Address]error),
+		chainCh:  make(chan core.ChainEvent, chainEventChanSize),
+	}
+}
+
+// Start begins watching imported blocks for approved extensions, picking up
+// the ones left unshared when the node was last stopped.
+func (s *Service) Start() {
+	s.chainSub = s.chain.SubscribeChainEvent(s.chainCh)
+
+	if _, privateState, err := s.chain.StateAt(s.chain.CurrentBlock().Root()); err != nil {
+		log.Warn("Failed to look up approved contract extensions", "err", err)
+	} else {
+		for _, ext := range core.ReadContractExtensions(privateState) {
+			if ext.Status == core.ExtensionApproved && s.isLocal(ext.Proposer) {
+				s.unshared[ext.Contract] = nil
+			}
+		}
+	}
+
+	s.wg.Add(1)
+	go s.loop()
+}
+
+// Stop terminates the service.
+func (s *Service) Stop() {
+	s.chainSub.Unsubscribe()
+	s.wg.Wait()
+}
+
+// APIs returns the RPC services offered by the contract extension service.
+func (s *Service) APIs() []rpc.API {
+	return []rpc.API{
+		{
+			Namespace: "quorumExtension",
+			Version:   "1.0",
+			Service:   NewPrivateExtensionAPI(s),
+		},
+	}
+}
+
+func (s *Service) loop() {
+	defer s.wg.Done()
+
+	s.retry(nil)
+	for {
+		select {
+		case ev := <-s.chainCh:
+			approved := make(map[common.Address]bool)
+			for _, l := range ev.Logs {
+				if l.Address != core.ContractExtensionAddress || len(l.Topics) != 2 || l.Topics[0] != core.ExtensionApprovedTopic {
+					continue
+				}
+				contract := common.BytesToAddress(l.Topics[1].Bytes())
+				approved[contract] = true
+				s.share(ev.Block, contract)
+			}
+			s.retry(approved)
+		case <-s.chainSub.Err():
+			return
+		}
+	}
+}
+
+// share sends the state snapshot of an approved extension, keeping track of it
+// for a retry if that fails.
+func (s *Service) share(block *types.Block, contract common.Address) {
+	err := s.shareSnapshot(block, contract)
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if err != nil {
+		log.Error("Failed to share extended contract state", "contract", contract, "err", err)
+		s.unshared[contract] = err
+		return
+	}
+	delete(s.unshared, contract)
+}
+
+// retry attempts to share the state of all approved extensions that failed
+// before, apart from the given ones that were just attempted. Extensions no
+// longer waiting for their state, e.g. because they got cancelled, are dropped.
+func (s *Service) retry(skip map[common.Address]bool) {
+	s.lock.Lock()
+	contracts := make([]common.Address, 0, len(s.unshared))
+	for contract := range s.unshared {
+		if !skip[contract] {
+			contracts = append(contracts, contract)
+		}
+	}
+	s.lock.Unlock()
+
+	for _, contract := range contracts {
+		ext, err := s.latestExtension(contract)
+		if err != nil {
+			continue
+		}
+		var block *types.Block
+		if ext != nil && ext.Status == core.ExtensionApproved {
+			block = s.chain.GetBlockByNumber(ext.ApprovedBlock)
+		}
+		if block == nil {
+			s.lock.Lock()
+			delete(s.unshared, contract)
+			s.lock.Unlock()
+			continue
+		}
+		s.share(block, contract)
+	}
+}
+
+// shareError returns the error of the last failed attempt to share the state
+// of an extended contract, if any.
+func (s *Service) shareError(contract common.Address) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return s.unshared[contract]
+}
+
+// shareSnapshot sends the private state of an approved extension's contract as
+// of the given block to the new party, if the extension was proposed locally.
+func (s *Service) shareSnapshot(block *types.Block, contract common.Address) error {
+	_, privateState, err := s.chain.StateAt(block.Root())
+	if err != nil {
+		return err
+	}
+	ext := core.ReadContractExtension(privateState, contract)
+	if ext == nil || ext.Status != core.ExtensionApproved || !s.isLocal(ext.Proposer) {
+		return nil
+	}
+	if private.P == nil {
+		return errNoTransactionManager
+	}
+	account, ok := privateState.DumpAddress(contract)
+	if !ok {
+		return errUnknownContract
+	}
+	snapshot, err := json.Marshal(account)
+	if err != nil {
+		return err
+	}
+	hash, err := private.P.Send(snapshot, ext.ProposerKey, []string{ext.RecipientKey})
+	if err != nil {
+		return err
+	}
+	action := &core.ExtensionAction{
+		Op:           core.ExtensionShare,
+		Contract:     contract,
+		SnapshotHash: hash,
+	}
+	args := ethapi.SendTxArgs{From: ext.Proposer, PrivateFrom: ext.ProposerKey}
+	txHash, err := s.sendAction(context.Background(), args, ext.Parties, action)
+	if err != nil {
+		return err
+	}
+	log.Info("Shared extended contract state", "contract", contract, "block", block.Number(), "recipient", ext.RecipientKey, "tx", txHash)
+	return nil
+}
+
+// isLocal reports whether the given account is managed by this node.
+func (s *Service) isLocal(addr common.Address) bool {
+	_, err := s.backend.AccountManager().Find(accounts.Account{Address: addr})
+	return err == nil
+}
+
+// latestExtension returns the extension record of a contract as of the current
+// head, or nil if there is none.
+func (s *Service) latestExtension(contract common.Address) (*core.ContractExtension, error) {
+	_, privateState, err := s.chain.StateAt(s.chain.CurrentBlock().Root())
+	if err != nil {
+		return nil, err
+	}
+	return core.ReadContractExtension(privateState, contract), nil
+}
+
+// sendAction submits a private transaction carrying the given management
+// action, shared with the given parties.
+func (s *Service) sendAction(ctx context.Context, args ethapi.SendTxArgs, parties []string, action *core.ExtensionAction) (common.Hash, error) {
+	if private.P == nil {
+		return common.Hash{}, errNoTransactionManager
+	}
+	data, err := rlp.EncodeToBytes(action)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	to := core.ContractExtensionAddress
+	input := hexutil.Bytes(data)
+
+	args.To, args.Data, args.Input = &to, &input, nil
+	args.PrivateFor = nil
+	for _, party := range parties {
+		if party != args.PrivateFrom {
+			args.PrivateFor = append(args.PrivateFor, party)
+		}
+	}
+	return s.txapi.SendTransaction(ctx, args)
+}
@@ -0,0 +1,808 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package graphql provides a GraphQL interface to Ethereum node data.
+package graphql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/internal/ethapi"
+	"github.com/ethereum/go-ethereum/private"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// States accounts may be read from, as named by the State enum of the schema.
+const (
+	statePublic  = "PUBLIC"
+	statePrivate = "PRIVATE"
+)
+
+var (
+	errBlockNotFound    = errors.New("block not found")
+	errNoSeparateStates = errors.New("public and private states can't be selected on this node")
+	errPrivateNotServed = errors.New("private state and payloads are not served over GraphQL")
+)
+
+// Long is a 64 bit unsigned integer.
+type Long uint64
+
+// ImplementsGraphQLType returns true if Long implements the provided GraphQL type.
+func (b Long) ImplementsGraphQLType(name string) bool { return name == "Long" }
+
+// UnmarshalGraphQL unmarshals the provided GraphQL query data.
+func (b *Long) UnmarshalGraphQL(input interface{}) error {
+	switch input := input.(type) {
+	case string:
+		value, err := hexutil.DecodeUint64(input)
+		*b = Long(value)
+		return err
+	case int32:
+		if input < 0 {
+			return fmt.Errorf("invalid Long %d", input)
+		}
+		*b = Long(input)
+		return nil
+	case float64:
+		if input < 0 || input != float64(uint64(input)) {
+			return fmt.Errorf("invalid Long %v", input)
+		}
+		*b = Long(input)
+		return nil
+	}
+	return fmt.Errorf("unexpected type %T for Long", input)
+}
+
+// splitState is implemented by the API states of full nodes, combining the
+// public and the private state.
+type splitState interface {
+	PublicState() vm.MinimalApiState
+	PrivateState() vm.MinimalApiState
+}
+
+// publicBackend serves the public state alone, for nodes not exposing their
+// private state and payloads over GraphQL.
+type publicBackend struct {
+	ethapi.Backend
+}
+
+func (b publicBackend) StateAndHeaderByNumber(ctx context.Context, blockNr rpc.BlockNumber) (vm.MinimalApiState, *types.Header, error) {
+	state, header, err := b.Backend.StateAndHeaderByNumber(ctx, blockNr)
+	if split, ok := state.(splitState); ok {
+		return split.PublicState(), header, err
+	}
+	return state, header, err
+}
+
+// GetReceipts replaces the receipts of private transactions, which carry the
+// outcome of the private execution, with stubs free of logs, created contract,
+// revert data and private state checksum.
+func (b publicBackend) GetReceipts(ctx context.Context, blockHash common.Hash) (types.Receipts, error) {
+	receipts, err := b.Backend.GetReceipts(ctx, blockHash)
+	if err != nil || len(receipts) == 0 {
+		return receipts, err
+	}
+	block, err := b.Backend.GetBlock(ctx, blockHash)
+	if err != nil {
+		return nil, err
+	}
+	if block == nil {
+		return nil, errBlockNotFound
+	}
+	private := make(map[common.Hash]bool)
+	for _, tx := range block.Transactions() {
+		if tx.IsPrivate() {
+			private[tx.Hash()] = true
+		}
+	}
+	public := make(types.Receipts, len(receipts))
+	for i, receipt := range receipts {
+		if !private[receipt.TxHash] {
+			public[i] = receipt
+			continue
+		}
+		public[i] = &types.Receipt{
+			PostState:         receipt.PostState,
+			Status:            receipt.Status,
+			CumulativeGasUsed: receipt.CumulativeGasUsed,
+			Logs:              []*types.Log{},
+			TxHash:            receipt.TxHash,
+			GasUsed:           receipt.GasUsed,
+		}
+	}
+	return public, nil
+}
+
+// servesPrivate returns whether the private state and payloads may be read
+// through a backend.
+func servesPrivate(backend ethapi.Backend) bool {
+	_, public := backend.(publicBackend)
+	return !public
+}
+
+// Account represents an Ethereum account at a particular block, read from the
+// public or private state if selected.
+type Account struct {
+	backend     ethapi.Backend
+	address     common.Address
+	blockNumber rpc.BlockNumber
+	state       *string
+}
+
+// getState fetches the state the account is read from.
+func (a *Account) getState(ctx context.Context) (vm.MinimalApiState, error) {
+	state, _, err := a.backend.StateAndHeaderByNumber(ctx, a.blockNumber)
+	if err != nil {
+		return nil, err
+	}
+	if state == nil {
+		return nil, errBlockNotFound
+	}
+	if a.state == nil {
+		return state, nil
+	}
+	if !servesPrivate(a.backend) {
+		if *a.state == statePrivate {
+			return nil, errPrivateNotServed
+		}
+		return state, nil
+	}
+	split, ok := state.(splitState)
+	if !ok {
+		return nil, errNoSeparateStates
+	}
+	if *a.state == statePrivate {
+		return split.PrivateState(), nil
+	}
+	return split.PublicState(), nil
+}
+
+func (a *Account) Address(ctx context.Context) (common.Address, error) {
+	return a.address, nil
+}
+
+func (a *Account) Balance(ctx context.Context) (hexutil.Big, error) {
+	state, err := a.getState(ctx)
+	if err != nil {
+		return hexutil.Big{}, err
+	}
+	return hexutil.Big(*state.GetBalance(a.address)), state.Error()
+}
+
+func (a *Account) TransactionCount(ctx context.Context) (Long, error) {
+	state, err := a.getState(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return Long(state.GetNonce(a.address)), state.Error()
+}
+
+func (a *Account) Code(ctx context.Context) (hexutil.Bytes, error) {
+	state, err := a.getState(ctx)
+	if err != nil {
+		return hexutil.Bytes{}, err
+	}
+	return hexutil.Bytes(state.GetCode(a.address)), state.Error()
+}
+
+func (a *Account) Storage(ctx context.Context, args struct{ Slot common.Hash }) (common.Hash, error) {
+	state, err := a.getState(ctx)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return state.GetState(a.address, args.Slot), state.Error()
+}
+
+// Log represents an individual log message. All arguments are mandatory.
+type Log struct {
+	backend     ethapi.Backend
+	transaction *Transaction
+	log         *types.Log
+}
+
+func (l *Log) Transaction(ctx context.Context) *Transaction {
+	return l.transaction
+}
+
+func (l *Log) Account(ctx context.Context, args BlockNumberArgs) *Account {
+	account := &Account{
+		backend:     l.backend,
+		address:     l.log.Address,
+		blockNumber: args.Number(),
+	}
+	if l.log.IsPrivate {
+		state := statePrivate
+		account.state = &state
+	}
+	return account
+}
+
+func (l *Log) Index(ctx context.Context) int32 {
+	return int32(l.log.Index)
+}
+
+func (l *Log) Topics(ctx context.Context) []common.Hash {
+	return l.log.Topics
+}
+
+func (l *Log) Data(ctx context.Context) hexutil.Bytes {
+	return hexutil.Bytes(l.log.Data)
+}
+
+func (l *Log) IsPrivate(ctx context.Context) bool {
+	return l.log.IsPrivate
+}
+
+// Transaction represents an Ethereum transaction. backend and hash are
+// mandatory; all others will be fetched when required.
+type Transaction struct {
+	backend ethapi.Backend
+	hash    common.Hash
+	tx      *types.Transaction
+	block   *Block
+	index   uint64
+}
+
+// resolve returns the internal transaction object, fetching it if needed.
+func (t *Transaction) resolve(ctx context.Context) (*types.Transaction, error) {
+	if t.tx == nil {
+		tx, blockHash, _, index := rawdb.ReadTransaction(t.backend.ChainDb(), t.hash)
+		if tx != nil {
+			t.tx = tx
+			t.block = &Block{backend: t.backend, hash: blockHash}
+			t.index = index
+		} else {
+			t.tx = t.backend.GetPoolTransaction(t.hash)
+		}
+	}
+	return t.tx, nil
+}
+
+func (t *Transaction) Hash(ctx context.Context) common.Hash {
+	return t.hash
+}
+
+func (t *Transaction) InputData(ctx context.Context) (hexutil.Bytes, error) {
+	tx, err := t.resolve(ctx)
+	if err != nil || tx == nil {
+		return hexutil.Bytes{}, err
+	}
+	return hexutil.Bytes(tx.Data()), nil
+}
+
+func (t *Transaction) PrivateInputData(ctx context.Context) (*hexutil.Bytes, error) {
+	if !servesPrivate(t.backend) {
+		return nil, errPrivateNotServed
+	}
+	tx, err := t.resolve(ctx)
+	if err != nil || tx == nil || !tx.IsPrivate() || private.P == nil {
+		return nil, err
+	}
+	payload, err := private.P.Receive(tx.Data())
+	if err != nil || len(payload) == 0 {
+		return nil, err
+	}
+	data := hexutil.Bytes(payload)
+	return &data, nil
+}
+
+func (t *Transaction) IsPrivate(ctx context.Context) (bool, error) {
+	tx, err := t.resolve(ctx)
+	if err != nil || tx == nil {
+		return false, err
+	}
+	return tx.IsPrivate(), nil
+}
+
+func (t *Transaction) Gas(ctx context.Context) (Long, error) {
+	tx, err := t.resolve(ctx)
+	if err != nil || tx == nil {
+		return 0, err
+	}
+	return Long(tx.Gas()), nil
+}
+
+func (t *Transaction) GasPrice(ctx context.Context) (hexutil.Big, error) {
+	tx, err := t.resolve(ctx)
+	if err != nil || tx == nil {
+		return hexutil.Big{}, err
+	}
+	return hexutil.Big(*tx.GasPrice()), nil
+}
+
+func (t *Transaction) Value(ctx context.Context) (hexutil.Big, error) {
+	tx, err := t.resolve(ctx)
+	if err != nil || tx == nil {
+		return hexutil.Big{}, err
+	}
+	return hexutil.Big(*tx.Value()), nil
+}
+
+func (t *Transaction) Nonce(ctx context.Context) (Long, error) {
+	tx, err := t.resolve(ctx)
+	if err != nil || tx == nil {
+		return 0, err
+	}
+	return Long(tx.Nonce()), nil
+}
+
+func (t *Transaction) To(ctx context.Context, args BlockNumberArgs) (*Account, error) {
+	tx, err := t.resolve(ctx)
+	if err != nil || tx == nil {
+		return nil, err
+	}
+	to := tx.To()
+	if to == nil {
+		return nil, nil
+	}
+	return &Account{
+		backend:     t.backend,
+		address:     *to,
+		blockNumber: args.Number(),
+	}, nil
+}
+
+func (t *Transaction) From(ctx context.Context, args BlockNumberArgs) (*Account, error) {
+	tx, err := t.resolve(ctx)
+	if err != nil || tx == nil {
+		return nil, err
+	}
+	var signer types.Signer = types.HomesteadSigner{}
+	if tx.Protected() && !tx.IsPrivate() {
+		signer = types.NewEIP155Signer(tx.ChainId())
+	}
+	from, _ := types.Sender(signer, tx)
+
+	return &Account{
+		backend:     t.backend,
+		address:     from,
+		blockNumber: args.Number(),
+	}, nil
+}
+
+func (t *Transaction) Block(ctx context.Context) (*Block, error) {
+	if _, err := t.resolve(ctx); err != nil {
+		return nil, err
+	}
+	return t.block, nil
+}
+
+func (t *Transaction) Index(ctx context.Context) (*int32, error) {
+	if _, err := t.resolve(ctx); err != nil {
+		return nil, err
+	}
+	if t.block == nil {
+		return nil, nil
+	}
+	index := int32(t.index)
+	return &index, nil
+}
+
+func (t *Transaction) Receipt(ctx context.Context) (*Receipt, error) {
+	if _, err := t.resolve(ctx); err != nil || t.block == nil {
+		return nil, err
+	}
+	receipts, err := t.block.resolveReceipts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if int(t.index) >= len(receipts) {
+		return nil, nil
+	}
+	return &Receipt{backend: t.backend, transaction: t, receipt: receipts[t.index]}, nil
+}
+
+// Receipt represents the receipt of a mined transaction, the private one for
+// private transactions. All arguments are mandatory.
+type Receipt struct {
+	backend     ethapi.Backend
+	transaction *Transaction
+	receipt     *types.Receipt
+}
+
+func (r *Receipt) Transaction(ctx context.Context) *Transaction {
+	return r.transaction
+}
+
+func (r *Receipt) Status(ctx context.Context) *Long {
+	if len(r.receipt.PostState) != 0 {
+		return nil
+	}
+	status := Long(r.receipt.Status)
+	return &status
+}
+
+func (r *Receipt) GasUsed(ctx context.Context) Long {
+	return Long(r.receipt.GasUsed)
+}
+
+func (r *Receipt) CumulativeGasUsed(ctx context.Context) Long {
+	return Long(r.receipt.CumulativeGasUsed)
+}
+
+func (r *Receipt) CreatedContract(ctx context.Context, args BlockNumberArgs) *Account {
+	if r.receipt.ContractAddress == (common.Address{}) {
+		return nil
+	}
+	account := &Account{
+		backend:     r.backend,
+		address:     r.receipt.ContractAddress,
+		blockNumber: args.Number(),
+	}
+	if r.transaction.tx.IsPrivate() {
+		state := statePrivate
+		account.state = &state
+	}
+	return account
+}
+
+func (r *Receipt) Logs(ctx context.Context) []*Log {
+	logs := make([]*Log, 0, len(r.receipt.Logs))
+	for _, log := range r.receipt.Logs {
+		logs = append(logs, &Log{
+			backend:     r.backend,
+			transaction: r.transaction,
+			log:         log,
+		})
+	}
+	return logs
+}
+
+func (r *Receipt) LogsBloom(ctx context.Context) hexutil.Bytes {
+	return hexutil.Bytes(r.receipt.Bloom.Bytes())
+}
+
+func (r *Receipt) IsPrivate(ctx context.Context) bool {
+	return r.transaction.tx.IsPrivate()
+}
+
+func (r *Receipt) PrivateStateChecksum(ctx context.Context) *common.Hash {
+	return r.receipt.PrivateStateChecksum
+}
+
+func (r *Receipt) RevertReason(ctx context.Context) *string {
+	if len(r.receipt.RevertData) == 0 {
+		return nil
+	}
+	reason, err := abi.UnpackRevert(r.receipt.RevertData)
+	if err != nil {
+		return nil
+	}
+	return &reason
+}
+
+// Block represents an Ethereum block. backend, and either num or hash are
+// mandatory. All other fields are lazily fetched when required.
+type Block struct {
+	backend  ethapi.Backend
+	num      *rpc.BlockNumber
+	hash     common.Hash
+	block    *types.Block
+	receipts []*types.Receipt
+}
+
+// resolve returns the internal Block object representing this block, fetching
+// it if necessary.
+func (b *Block) resolve(ctx context.Context) (*types.Block, error) {
+	if b.block != nil {
+		return b.block, nil
+	}
+	var err error
+	if b.num != nil {
+		b.block, err = b.backend.BlockByNumber(ctx, *b.num)
+	} else {
+		b.block, err = b.backend.GetBlock(ctx, b.hash)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if b.block == nil {
+		return nil, errBlockNotFound
+	}
+	b.hash = b.block.Hash()
+	return b.block, nil
+}
+
+// resolveReceipts returns the list of receipts for this block, fetching them
+// if necessary.
+func (b *Block) resolveReceipts(ctx context.Context) ([]*types.Receipt, error) {
+	if b.receipts == nil {
+		block, err := b.resolve(ctx)
+		if err != nil {
+			return nil, err
+		}
+		receipts, err := b.backend.GetReceipts(ctx, block.Hash())
+		if err != nil {
+			return nil, err
+		}
+		b.receipts = receipts
+	}
+	return b.receipts, nil
+}
+
+func (b *Block) Number(ctx context.Context) (Long, error) {
+	block, err := b.resolve(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return Long(block.NumberU64()), nil
+}
+
+func (b *Block) Hash(ctx context.Context) (common.Hash, error) {
+	if _, err := b.resolve(ctx); err != nil {
+		return common.Hash{}, err
+	}
+	return b.hash, nil
+}
+
+func (b *Block) Parent(ctx context.Context) (*Block, error) {
+	block, err := b.resolve(ctx)
+	if err != nil || block.NumberU64() == 0 {
+		return nil, err
+	}
+	return &Block{backend: b.backend, hash: block.ParentHash()}, nil
+}
+
+func (b *Block) Nonce(ctx context.Context) (hexutil.Bytes, error) {
+	block, err := b.resolve(ctx)
+	if err != nil {
+		return hexutil.Bytes{}, err
+	}
+	nonce := block.Header().Nonce
+	return hexutil.Bytes(nonce[:]), nil
+}
+
+func (b *Block) TransactionsRoot(ctx context.Context) (common.Hash, error) {
+	block, err := b.resolve(ctx)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return block.TxHash(), nil
+}
+
+func (b *Block) TransactionCount(ctx context.Context) (int32, error) {
+	block, err := b.resolve(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return int32(len(block.Transactions())), nil
+}
+
+func (b *Block) StateRoot(ctx context.Context) (common.Hash, error) {
+	block, err := b.resolve(ctx)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return block.Root(), nil
+}
+
+func (b *Block) ReceiptsRoot(ctx context.Context) (common.Hash, error) {
+	block, err := b.resolve(ctx)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return block.ReceiptHash(), nil
+}
+
+func (b *Block) Miner(ctx context.Context, args BlockNumberArgs) (*Account, error) {
+	block, err := b.resolve(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &Account{
+		backend:     b.backend,
+		address:     block.Coinbase(),
+		blockNumber: args.Number(),
+	}, nil
+}
+
+func (b *Block) ExtraData(ctx context.Context) (hexutil.Bytes, error) {
+	block, err := b.resolve(ctx)
+	if err != nil {
+		return hexutil.Bytes{}, err
+	}
+	return hexutil.Bytes(block.Extra()), nil
+}
+
+func (b *Block) GasLimit(ctx context.Context) (Long, error) {
+	block, err := b.resolve(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return Long(block.GasLimit()), nil
+}
+
+func (b *Block) GasUsed(ctx context.Context) (Long, error) {
+	block, err := b.resolve(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return Long(block.GasUsed()), nil
+}
+
+func (b *Block) Timestamp(ctx context.Context) (hexutil.Big, error) {
+	block, err := b.resolve(ctx)
+	if err != nil {
+		return hexutil.Big{}, err
+	}
+	return hexutil.Big(*block.Time()), nil
+}
+
+func (b *Block) LogsBloom(ctx context.Context) (hexutil.Bytes, error) {
+	block, err := b.resolve(ctx)
+	if err != nil {
+		return hexutil.Bytes{}, err
+	}
+	return hexutil.Bytes(block.Bloom().Bytes()), nil
+}
+
+func (b *Block) MixHash(ctx context.Context) (common.Hash, error) {
+	block, err := b.resolve(ctx)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return block.MixDigest(), nil
+}
+
+func (b *Block) Difficulty(ctx context.Context) (hexutil.Big, error) {
+	block, err := b.resolve(ctx)
+	if err != nil {
+		return hexutil.Big{}, err
+	}
+	return hexutil.Big(*block.Difficulty()), nil
+}
+
+func (b *Block) TotalDifficulty(ctx context.Context) (hexutil.Big, error) {
+	if _, err := b.resolve(ctx); err != nil {
+		return hexutil.Big{}, err
+	}
+	td := b.backend.GetTd(b.hash)
+	if td == nil {
+		return hexutil.Big{}, fmt.Errorf("total difficulty not found %x", b.hash)
+	}
+	return hexutil.Big(*td), nil
+}
+
+func (b *Block) Transactions(ctx context.Context) ([]*Transaction, error) {
+	block, err := b.resolve(ctx)
+	if err != nil {
+		return nil, err
+	}
+	txs := make([]*Transaction, 0, len(block.Transactions()))
+	for i, tx := range block.Transactions() {
+		txs = append(txs, &Transaction{
+			backend: b.backend,
+			hash:    tx.Hash(),
+			tx:      tx,
+			block:   b,
+			index:   uint64(i),
+		})
+	}
+	return txs, nil
+}
+
+func (b *Block) TransactionAt(ctx context.Context, args struct{ Index int32 }) (*Transaction, error) {
+	block, err := b.resolve(ctx)
+	if err != nil {
+		return nil, err
+	}
+	txs := block.Transactions()
+	if args.Index < 0 || int(args.Index) >= len(txs) {
+		return nil, nil
+	}
+	tx := txs[args.Index]
+	return &Transaction{
+		backend: b.backend,
+		hash:    tx.Hash(),
+		tx:      tx,
+		block:   b,
+		index:   uint64(args.Index),
+	}, nil
+}
+
+func (b *Block) Account(ctx context.Context, args struct {
+	Address common.Address
+	State   *string
+}) (*Account, error) {
+	block, err := b.resolve(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &Account{
+		backend:     b.backend,
+		address:     args.Address,
+		blockNumber: rpc.BlockNumber(block.NumberU64()),
+		state:       args.State,
+	}, nil
+}
+
+// BlockNumberArgs encapsulates arguments to accessors that specify a block number.
+type BlockNumberArgs struct {
+	Block *Long
+}
+
+// Number returns the provided block number, or rpc.LatestBlockNumber if none
+// was provided.
+func (a BlockNumberArgs) Number() rpc.BlockNumber {
+	if a.Block != nil {
+		return rpc.BlockNumber(*a.Block)
+	}
+	return rpc.LatestBlockNumber
+}
+
+// Resolver is the top-level object in the GraphQL hierarchy.
+type Resolver struct {
+	backend ethapi.Backend
+}
+
+func (r *Resolver) Block(ctx context.Context, args struct {
+	Number *Long
+	Hash   *common.Hash
+}) (*Block, error) {
+	var block *Block
+	switch {
+	case args.Number != nil:
+		num := rpc.BlockNumber(*args.Number)
+		block = &Block{backend: r.backend, num: &num}
+	case args.Hash != nil:
+		block = &Block{backend: r.backend, hash: *args.Hash}
+	default:
+		num := rpc.LatestBlockNumber
+		block = &Block{backend: r.backend, num: &num}
+	}
+	// Resolve the block, returning nil for missing ones
+	if _, err := block.resolve(ctx); err != nil {
+		if err == errBlockNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return block, nil
+}
+
+func (r *Resolver) Account(ctx context.Context, args struct {
+	Address     common.Address
+	BlockNumber *Long
+	State       *string
+}) *Account {
+	return &Account{
+		backend:     r.backend,
+		address:     args.Address,
+		blockNumber: BlockNumberArgs{Block: args.BlockNumber}.Number(),
+		state:       args.State,
+	}
+}
+
+func (r *Resolver) Transaction(ctx context.Context, args struct{ Hash common.Hash }) (*Transaction, error) {
+	tx := &Transaction{
+		backend: r.backend,
+		hash:    args.Hash,
+	}
+	// Resolve the transaction, returning nil for unknown ones
+	t, err := tx.resolve(ctx)
+	if err != nil || t == nil {
+		return nil, err
+	}
+	return tx, nil
+}
@@ -0,0 +1,405 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package graphql
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/internal/ethapi"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+var (
+	testKey, _  = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+	testAddress = crypto.PubkeyToAddress(testKey.PublicKey)
+	testFunds   = big.NewInt(1000000000000000000)
+	testTo      = common.HexToAddress("0x0000000000000000000000000000000000000100")
+)
+
+// testState is a combined API state exposing its public and private parts.
+type testState struct {
+	*state.StateDB
+	private *state.StateDB
+}
+
+func (s testState) PublicState() vm.MinimalApiState  { return s.StateDB }
+func (s testState) PrivateState() vm.MinimalApiState { return s.private }
+
+// testBackend serves the data of a local chain, leaving all methods unused by
+// the resolvers unimplemented.
+type testBackend struct {
+	ethapi.Backend
+	db    ethdb.Database
+	chain *core.BlockChain
+}
+
+func (b *testBackend) ChainDb() ethdb.Database { return b.db }
+
+func (b *testBackend) BlockByNumber(ctx context.Context, number rpc.BlockNumber) (*types.Block, error) {
+	if number == rpc.LatestBlockNumber {
+		return b.chain.CurrentBlock(), nil
+	}
+	return b.chain.GetBlockByNumber(uint64(number)), nil
+}
+
+func (b *testBackend) GetBlock(ctx context.Context, hash common.Hash) (*types.Block, error) {
+	return b.chain.GetBlockByHash(hash), nil
+}
+
+func (b *testBackend) GetReceipts(ctx context.Context, hash common.Hash) (types.Receipts, error) {
+	return b.chain.GetReceiptsByHash(hash), nil
+}
+
+func (b *testBackend) GetTd(hash common.Hash) *big.Int {
+	return b.chain.GetTdByHash(hash)
+}
+
+func (b *testBackend) GetPoolTransaction(hash common.Hash) *types.Transaction {
+	return nil
+}
+
+func (b *testBackend) StateAndHeaderByNumber(ctx context.Context, number rpc.BlockNumber) (vm.MinimalApiState, *types.Header, error) {
+	block, _ := b.BlockByNumber(ctx, number)
+	if block == nil {
+		return nil, nil, nil
+	}
+	public, private, err := b.chain.StateAt(block.Root())
+	return testState{public, private}, block.Header(), err
+}
+
+// newTestBackend creates a backend with a chain of a single block, transferring
+// funds from the test account.
+func newTestBackend(t *testing.T) (*testBackend, *types.Transaction) {
+	var (
+		db    = ethdb.NewMemDatabase()
+		gspec = core.Genesis{
+			Config: params.TestChainConfig,
+			Alloc:  core.GenesisAlloc{testAddress: {Balance: testFunds}},
+		}
+		genesis = gspec.MustCommit(db)
+	)
+	tx, err := types.SignTx(types.NewTransaction(0, testTo, big.NewInt(1000), params.TxGas, big.NewInt(1), nil), types.HomesteadSigner{}, testKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	blocks, _ := core.GenerateChain(gspec.Config, genesis, ethash.NewFaker(), db, 1, func(i int, gen *core.BlockGen) {
+		gen.AddTx(tx)
+	})
+	chain, err := core.NewBlockChain(db, nil, gspec.Config, ethash.NewFaker(), vm.Config{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	return &testBackend{db: db, chain: chain}, tx
+}
+
+// privateBackend extends the test chain with a block holding a private
+// transaction, whose receipt carries the outcome of the private execution.
+type privateBackend struct {
+	*testBackend
+	block    *types.Block
+	receipts types.Receipts
+}
+
+func newPrivateBackend(t *testing.T) *privateBackend {
+	backend, _ := newTestBackend(t)
+	tx, err := types.SignTx(types.NewContractCreation(1, nil, params.TxGas, big.NewInt(1), common.Hex2Bytes("01")), types.HomesteadSigner{}, testKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx.SetPrivate()
+	receipts := types.Receipts{{
+		Status:               types.ReceiptStatusSuccessful,
+		CumulativeGasUsed:    params.TxGas,
+		Logs:                 []*types.Log{{Address: testTo, TxHash: tx.Hash()}},
+		TxHash:               tx.Hash(),
+		ContractAddress:      testTo,
+		GasUsed:              params.TxGas,
+		PrivateStateChecksum: &common.Hash{0x01},
+	}}
+	parent := backend.chain.CurrentBlock()
+	header := &types.Header{ParentHash: parent.Hash(), Number: big.NewInt(2)}
+	return &privateBackend{testBackend: backend, block: types.NewBlock(header, types.Transactions{tx}, nil, receipts), receipts: receipts}
+}
+
+func (b *privateBackend) BlockByNumber(ctx context.Context, number rpc.BlockNumber) (*types.Block, error) {
+	if number == rpc.LatestBlockNumber || uint64(number) == b.block.NumberU64() {
+		return b.block, nil
+	}
+	return b.testBackend.BlockByNumber(ctx, number)
+}
+
+func (b *privateBackend) GetBlock(ctx context.Context, hash common.Hash) (*types.Block, error) {
+	if hash == b.block.Hash() {
+		return b.block, nil
+	}
+	return b.testBackend.GetBlock(ctx, hash)
+}
+
+func (b *privateBackend) GetReceipts(ctx context.Context, hash common.Hash) (types.Receipts, error) {
+	if hash == b.block.Hash() {
+		return b.receipts, nil
+	}
+	return b.testBackend.GetReceipts(ctx, hash)
+}
+
+// post posts a GraphQL query to the handler and returns the data and errors
+// answered.
+func post(t *testing.T, handler http.Handler, q string) (json.RawMessage, []interface{}) {
+	body, _ := json.Marshal(map[string]string{"query": q})
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/graphql", bytes.NewReader(body)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("query failed with status %d: %s", rec.Code, rec.Body)
+	}
+	var response struct {
+		Data   json.RawMessage
+		Errors []interface{}
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("invalid response %s: %v", rec.Body, err)
+	}
+	return response.Data, response.Errors
+}
+
+// query posts a GraphQL query to the handler and decodes the data answered.
+func query(t *testing.T, handler http.Handler, q string, data interface{}) {
+	raw, errs := post(t, handler, q)
+	if len(errs) > 0 {
+		t.Fatalf("query answered with errors: %v", errs)
+	}
+	if err := json.Unmarshal(raw, data); err != nil {
+		t.Fatalf("invalid data %s: %v", raw, err)
+	}
+}
+
+func TestBlockQuery(t *testing.T) {
+	backend, tx := newTestBackend(t)
+	handler, err := NewHandler(backend, true)
+	if err != nil {
+		t.Fatalf("failed to create handler: %v", err)
+	}
+	var result struct {
+		Block struct {
+			Number           uint64
+			TransactionCount int
+			Transactions     []struct {
+				Hash      common.Hash
+				IsPrivate bool
+				From      struct{ Address common.Address }
+				Receipt   struct {
+					Status    uint64
+					GasUsed   uint64
+					IsPrivate bool
+					Logs      []interface{}
+				}
+			}
+		}
+		Missing *struct{ Number uint64 }
+	}
+	query(t, handler, `{
+		block(number: 1) {
+			number
+			transactionCount
+			transactions {
+				hash
+				isPrivate
+				from { address }
+				receipt { status gasUsed isPrivate logs { index } }
+			}
+		}
+		missing: block(number: 5) { number }
+	}`, &result)
+
+	if result.Block.Number != 1 || result.Block.TransactionCount != 1 || len(result.Block.Transactions) != 1 {
+		t.Fatalf("block mismatch: %+v", result.Block)
+	}
+	got := result.Block.Transactions[0]
+	if got.Hash != tx.Hash() || got.IsPrivate || got.From.Address != testAddress {
+		t.Errorf("transaction mismatch: %+v", got)
+	}
+	if got.Receipt.Status != types.ReceiptStatusSuccessful || got.Receipt.GasUsed != params.TxGas || got.Receipt.IsPrivate || len(got.Receipt.Logs) != 0 {
+		t.Errorf("receipt mismatch: %+v", got.Receipt)
+	}
+	if result.Missing != nil {
+		t.Errorf("unknown block returned: %+v", result.Missing)
+	}
+}
+
+func TestTransactionQuery(t *testing.T) {
+	backend, tx := newTestBackend(t)
+	handler, err := NewHandler(backend, true)
+	if err != nil {
+		t.Fatalf("failed to create handler: %v", err)
+	}
+	var result struct {
+		Transaction struct {
+			Index int
+			Gas   uint64
+			Block struct{ Number uint64 }
+			To    struct{ Balance string }
+		}
+		Unknown *struct{ Index int }
+	}
+	query(t, handler, fmt.Sprintf(`{
+		transaction(hash: "%s") { index gas block { number } to { balance } }
+		unknown: transaction(hash: "%s") { index }
+	}`, tx.Hash().Hex(), common.Hash{}.Hex()), &result)
+
+	if result.Transaction.Index != 0 || result.Transaction.Gas != params.TxGas || result.Transaction.Block.Number != 1 {
+		t.Errorf("transaction mismatch: %+v", result.Transaction)
+	}
+	if result.Transaction.To.Balance != "0x3e8" {
+		t.Errorf("recipient balance mismatch: have %s, want 0x3e8", result.Transaction.To.Balance)
+	}
+	if result.Unknown != nil {
+		t.Errorf("unknown transaction returned: %+v", result.Unknown)
+	}
+}
+
+func TestAccountStateSelection(t *testing.T) {
+	backend, _ := newTestBackend(t)
+	handler, err := NewHandler(backend, true)
+	if err != nil {
+		t.Fatalf("failed to create handler: %v", err)
+	}
+	var result struct {
+		Combined struct{ TransactionCount uint64 }
+		Public   struct{ TransactionCount uint64 }
+		Private  struct{ TransactionCount uint64 }
+		Genesis  struct{ TransactionCount uint64 }
+	}
+	query(t, handler, fmt.Sprintf(`{
+		combined: account(address: "%[1]s") { transactionCount }
+		public: account(address: "%[1]s", state: PUBLIC) { transactionCount }
+		private: account(address: "%[1]s", state: PRIVATE) { transactionCount }
+		genesis: account(address: "%[1]s", blockNumber: 0) { transactionCount }
+	}`, testAddress.Hex()), &result)
+
+	if result.Combined.TransactionCount != 1 || result.Public.TransactionCount != 1 {
+		t.Errorf("public nonce mismatch: combined %d, public %d, want 1", result.Combined.TransactionCount, result.Public.TransactionCount)
+	}
+	if result.Private.TransactionCount != 0 {
+		t.Errorf("private nonce mismatch: have %d, want 0", result.Private.TransactionCount)
+	}
+	if result.Genesis.TransactionCount != 0 {
+		t.Errorf("genesis nonce mismatch: have %d, want 0", result.Genesis.TransactionCount)
+	}
+}
+
+func TestPrivateStateNotServed(t *testing.T) {
+	backend, tx := newTestBackend(t)
+	handler, err := NewHandler(backend, false)
+	if err != nil {
+		t.Fatalf("failed to create handler: %v", err)
+	}
+	var result struct {
+		Combined struct{ TransactionCount uint64 }
+		Public   struct{ TransactionCount uint64 }
+	}
+	query(t, handler, fmt.Sprintf(`{
+		combined: account(address: "%[1]s") { transactionCount }
+		public: account(address: "%[1]s", state: PUBLIC) { transactionCount }
+	}`, testAddress.Hex()), &result)
+
+	if result.Combined.TransactionCount != 1 || result.Public.TransactionCount != 1 {
+		t.Errorf("public nonce mismatch: combined %d, public %d, want 1", result.Combined.TransactionCount, result.Public.TransactionCount)
+	}
+	if _, errs := post(t, handler, fmt.Sprintf(`{ account(address: "%s", state: PRIVATE) { transactionCount } }`, testAddress.Hex())); len(errs) == 0 {
+		t.Errorf("private state served")
+	}
+	if _, errs := post(t, handler, fmt.Sprintf(`{ transaction(hash: "%s") { privateInputData } }`, tx.Hash().Hex())); len(errs) == 0 {
+		t.Errorf("private payload served")
+	}
+}
+
+func TestPrivateReceiptNotServed(t *testing.T) {
+	const receiptQuery = `{ block(number: 2) { transactions { isPrivate receipt { isPrivate logs { index } createdContract { address } privateStateChecksum } } } }`
+
+	type receipt struct {
+		IsPrivate            bool
+		Logs                 []interface{}
+		CreatedContract      *struct{ Address common.Address }
+		PrivateStateChecksum *string
+	}
+	var result struct {
+		Block struct {
+			Transactions []struct {
+				IsPrivate bool
+				Receipt   receipt
+			}
+		}
+	}
+	for _, private := range []bool{true, false} {
+		handler, err := NewHandler(newPrivateBackend(t), private)
+		if err != nil {
+			t.Fatalf("failed to create handler: %v", err)
+		}
+		query(t, handler, receiptQuery, &result)
+		if len(result.Block.Transactions) != 1 || !result.Block.Transactions[0].IsPrivate {
+			t.Fatalf("private %t: block mismatch: %+v", private, result.Block)
+		}
+		got := result.Block.Transactions[0].Receipt
+		served := len(got.Logs) != 0 || got.CreatedContract != nil || got.PrivateStateChecksum != nil
+		if !got.IsPrivate || served != private {
+			t.Errorf("private %t: receipt mismatch: %+v", private, got)
+		}
+	}
+}
+
+func TestQueryDepthLimit(t *testing.T) {
+	backend, _ := newTestBackend(t)
+	handler, err := NewHandler(backend, true)
+	if err != nil {
+		t.Fatalf("failed to create handler: %v", err)
+	}
+	nested := func(depth int) string {
+		q := "number"
+		for i := 2; i < depth; i++ {
+			q = "parent { " + q + " }"
+		}
+		return "{ block { " + q + " } }"
+	}
+	exceeded := func(errs []interface{}) bool {
+		return strings.Contains(fmt.Sprint(errs), "exceeds max depth")
+	}
+	if _, errs := post(t, handler, nested(maxQueryDepth)); exceeded(errs) {
+		t.Errorf("query within the depth limit refused: %v", errs)
+	}
+	if _, errs := post(t, handler, nested(maxQueryDepth+1)); !exceeded(errs) {
+		t.Errorf("query beyond the depth limit not refused: %v", errs)
+	}
+}
@@ -0,0 +1,216 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package graphql
+
+const schema string = `
+    # Bytes32 is a 32 byte binary string, represented as 0x-prefixed hexadecimal.
+    scalar Bytes32
+    # Address is a 20 byte Ethereum address, represented as 0x-prefixed hexadecimal.
+    scalar Address
+    # Bytes is an arbitrary length binary string, represented as 0x-prefixed hexadecimal.
+    # An empty byte string is represented as '0x'. Byte strings must have an even number of hexadecimal nybbles.
+    scalar Bytes
+    # BigInt is a large integer. Input is accepted as either a JSON number or as a string.
+    # Strings may be either decimal or 0x-prefixed hexadecimal. Output values are all
+    # 0x-prefixed hexadecimal.
+    scalar BigInt
+    # Long is a 64 bit unsigned integer.
+    scalar Long
+
+    schema {
+        query: Query
+    }
+
+    # State selects the state accounts are read from. Without a selection,
+    # accounts existing in the private state are read from it, and all others
+    # from the public state. Nodes not serving their private state read all
+    # accounts from the public state and refuse PRIVATE.
+    enum State {
+        # PUBLIC reads accounts from the public state alone.
+        PUBLIC
+        # PRIVATE reads accounts from the private state of this node alone.
+        PRIVATE
+    }
+
+    # Account is an Ethereum account at a particular block.
+    type Account {
+        # Address is the address owning the account.
+        address: Address!
+        # Balance is the balance of the account, in wei.
+        balance: BigInt!
+        # TransactionCount is the number of transactions sent from this account,
+        # or in the case of a contract, the number of contracts created. Otherwise
+        # known as the nonce.
+        transactionCount: Long!
+        # Code contains the smart contract code for this account, if the account
+        # is a (non-self-destructed) contract.
+        code: Bytes!
+        # Storage provides access to the storage of a contract account, indexed
+        # by its 32 byte slot identifier.
+        storage(slot: Bytes32!): Bytes32!
+    }
+
+    # Log is an Ethereum event log.
+    type Log {
+        # Index is the index of this log in the block.
+        index: Int!
+        # Account is the account which generated this log - this will always
+        # be a contract account, read from the private state for private logs.
+        account(block: Long): Account!
+        # Topics is a list of 0-4 indexed topics for the log.
+        topics: [Bytes32!]!
+        # Data is unindexed data for this log.
+        data: Bytes!
+        # Transaction is the transaction that generated this log entry.
+        transaction: Transaction!
+        # IsPrivate is true if the log was emitted into the private state.
+        isPrivate: Boolean!
+    }
+
+    # Transaction is an Ethereum transaction.
+    type Transaction {
+        # Hash is the hash of this transaction.
+        hash: Bytes32!
+        # Nonce is the nonce of the account this transaction was generated with.
+        nonce: Long!
+        # Index is the index of this transaction in the parent block. This will
+        # be null if the transaction has not yet been mined.
+        index: Int
+        # From is the account that sent this transaction - this will always be
+        # an externally owned account.
+        from(block: Long): Account!
+        # To is the account the transaction was sent to. This is null for
+        # contract-creating transactions.
+        to(block: Long): Account
+        # Value is the value, in wei, sent along with this transaction.
+        value: BigInt!
+        # GasPrice is the price offered to miners for gas, in wei per unit.
+        gasPrice: BigInt!
+        # Gas is the maximum amount of gas this transaction can consume.
+        gas: Long!
+        # InputData is the data supplied to the target of the transaction. For
+        # private transactions, this is the hash of the encrypted payload.
+        inputData: Bytes!
+        # Block is the block this transaction was mined in. This will be null if
+        # the transaction has not yet been mined.
+        block: Block
+        # Receipt is the receipt of the transaction, the private one if this node
+        # is party to a private transaction. This will be null if the transaction
+        # has not yet been mined.
+        receipt: Receipt
+        # IsPrivate is true if this is a private transaction.
+        isPrivate: Boolean!
+        # PrivateInputData is the payload of a private transaction, retrieved from
+        # the transaction manager. This is null for public transactions and private
+        # transactions this node is not party to, and an error on nodes not
+        # serving private payloads.
+        privateInputData: Bytes
+    }
+
+    # Receipt is the receipt of a mined transaction.
+    type Receipt {
+        # Transaction is the transaction this receipt belongs to.
+        transaction: Transaction!
+        # Status is the return status of the transaction. This will be 1 if the
+        # transaction succeeded, or 0 if it failed (due to a revert, or due to
+        # running out of gas). This is null for receipts of blocks predating
+        # Byzantium, which carry a state root instead.
+        status: Long
+        # GasUsed is the amount of gas that was used processing this transaction.
+        gasUsed: Long!
+        # CumulativeGasUsed is the total gas used in the block up to and including
+        # this transaction.
+        cumulativeGasUsed: Long!
+        # CreatedContract is the account that was created by a contract creation
+        # transaction. If the transaction was not a contract creation transaction,
+        # or it has not yet been mined, this field will be null.
+        createdContract(block: Long): Account
+        # Logs is a list of log entries emitted by this transaction.
+        logs: [Log!]!
+        # LogsBloom is the bloom filter of the logs of this transaction.
+        logsBloom: Bytes!
+        # IsPrivate is true if this receipt results from executing a private
+        # transaction against the private state of this node.
+        isPrivate: Boolean!
+        # PrivateStateChecksum is the checksum of the private state after a private
+        # transaction, if private state checksums are enabled.
+        privateStateChecksum: Bytes32
+        # RevertReason is the reason a failed transaction was reverted with, if
+        # revert reasons are stored.
+        revertReason: String
+    }
+
+    # Block is an Ethereum block.
+    type Block {
+        # Number is the number of this block, starting at 0 for the genesis block.
+        number: Long!
+        # Hash is the block hash of this block.
+        hash: Bytes32!
+        # Parent is the parent block of this block.
+        parent: Block
+        # Nonce is the block nonce, an 8 byte sequence determined by the miner.
+        nonce: Bytes!
+        # TransactionsRoot is the keccak256 hash of the root of the trie of transactions in this block.
+        transactionsRoot: Bytes32!
+        # TransactionCount is the number of transactions in this block.
+        transactionCount: Int!
+        # StateRoot is the keccak256 hash of the public state trie after this block was processed.
+        stateRoot: Bytes32!
+        # ReceiptsRoot is the keccak256 hash of the trie of public transaction receipts in this block.
+        receiptsRoot: Bytes32!
+        # Miner is the account that mined this block.
+        miner(block: Long): Account!
+        # ExtraData is an arbitrary data field supplied by the miner.
+        extraData: Bytes!
+        # GasLimit is the maximum amount of gas that was available to transactions in this block.
+        gasLimit: Long!
+        # GasUsed is the amount of gas that was used executing transactions in this block.
+        gasUsed: Long!
+        # Timestamp is the unix timestamp at which this block was mined.
+        timestamp: BigInt!
+        # LogsBloom is a bloom filter that can be used to check if a block may
+        # contain log entries matching a filter.
+        logsBloom: Bytes!
+        # MixHash is the hash that was used as an input to the PoW process.
+        mixHash: Bytes32!
+        # Difficulty is a measure of the difficulty of mining this block.
+        difficulty: BigInt!
+        # TotalDifficulty is the sum of all difficulty values up to and including
+        # this block.
+        totalDifficulty: BigInt!
+        # Transactions is a list of transactions associated with this block.
+        transactions: [Transaction!]!
+        # TransactionAt returns the transaction at the specified index, or null
+        # if there is none.
+        transactionAt(index: Int!): Transaction
+        # Account fetches an Ethereum account at the current block's state, from
+        # the given state if any.
+        account(address: Address!, state: State): Account!
+    }
+
+    type Query {
+        # Account fetches an Ethereum account at the specified block number, from
+        # the given state if any. If blockNumber is not provided, it defaults to
+        # the most recent block.
+        account(address: Address!, blockNumber: Long, state: State): Account!
+        # Block fetches an Ethereum block by number or by hash. If neither is
+        # supplied, the most recent known block is returned.
+        block(number: Long, hash: Bytes32): Block
+        # Transaction returns a transaction specified by its hash.
+        transaction(hash: Bytes32!): Transaction
+    }
+`
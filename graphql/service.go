@@ -0,0 +1,86 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package graphql
+
+import (
+	"net/http"
+
+	"github.com/ethereum/go-ethereum/internal/ethapi"
+	"github.com/ethereum/go-ethereum/p2p"
+	"github.com/ethereum/go-ethereum/rpc"
+	graphqlgo "github.com/graph-gophers/graphql-go"
+	"github.com/graph-gophers/graphql-go/relay"
+)
+
+// maxQueryDepth is the deepest nesting of fields a query may select, bounding
+// the work of queries following the links between blocks and transactions.
+const maxQueryDepth = 10
+
+// Service encapsulates a GraphQL service, served on the HTTP RPC endpoint of
+// the node.
+type Service struct {
+	handler http.Handler // The `http.Handler` used to answer queries.
+}
+
+// New constructs a new GraphQL service instance, serving the private state and
+// payloads of the node if private is set.
+func New(backend ethapi.Backend, private bool) (*Service, error) {
+	handler, err := NewHandler(backend, private)
+	if err != nil {
+		return nil, err
+	}
+	return &Service{handler: handler}, nil
+}
+
+// Protocols returns the list of protocols exported by this service.
+func (s *Service) Protocols() []p2p.Protocol { return nil }
+
+// APIs returns the list of APIs exported by this service.
+func (s *Service) APIs() []rpc.API { return nil }
+
+// HTTPHandlers implements node.HTTPHandlerProvider, answering the queries posted
+// to /graphql on the HTTP RPC endpoint.
+func (s *Service) HTTPHandlers() map[string]http.Handler {
+	return map[string]http.Handler{
+		"/graphql":  s.handler,
+		"/graphql/": s.handler,
+	}
+}
+
+// Start is called after all services have been constructed and the networking
+// layer was also initialized to spawn any goroutines required by the service.
+func (s *Service) Start(server *p2p.Server) error { return nil }
+
+// NewHandler returns a new `http.Handler` that will answer GraphQL queries,
+// backed by the given Ethereum backend. The private state and payloads of the
+// node are only served if private is set.
+func NewHandler(backend ethapi.Backend, private bool) (http.Handler, error) {
+	if !private {
+		backend = publicBackend{backend}
+	}
+	q := Resolver{backend}
+
+	s, err := graphqlgo.ParseSchema(schema, &q, graphqlgo.MaxDepth(maxQueryDepth))
+	if err != nil {
+		return nil, err
+	}
+	return &relay.Handler{Schema: s}, nil
+}
+
+// Stop terminates all goroutines belonging to the service, blocking until they
+// are all terminated.
+func (s *Service) Stop() error { return nil }
@@ -148,6 +148,11 @@ type FilterQuery struct {
 	// {{A}, {B}}         matches topic A in first position, B in second position
 	// {{A, B}}, {C, D}}  matches topic (A OR B) in first position, (C OR D) in second position
 	Topics [][]common.Hash
+
+	// Quorum
+	// Privacy restricts matches to logs of public ("public") or private ("private")
+	// transactions. An empty string or "all" matches both.
+	Privacy string
 }
 
 // LogFilterer provides access to contract log events using a one-off query or continuous
@@ -25,6 +25,7 @@ import (
 	"strings"
 	"time"
 
+	"encoding/base64"
 	"encoding/hex"
 	"encoding/json"
 	"net/http"
@@ -33,6 +34,7 @@ import (
 	"sync"
 
 	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/accounts/keystore"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
@@ -392,11 +394,31 @@ func (s *PrivateAccountAPI) SendTransaction(ctx context.Context, args SendTxArgs
 		defer s.nonceLock.UnlockAddr(args.From)
 	}
 
+	// Set some sanity defaults and terminate on failure
+	if err := args.setDefaults(ctx, s.b); err != nil {
+		return common.Hash{}, err
+	}
+
 	isPrivate := args.PrivateFor != nil
 
 	if isPrivate {
 		data := []byte(*args.Data)
 		if len(data) > 0 {
+			if err := validatePrivateParties(s.b, args.PrivateFrom, args.PrivateFor); err != nil {
+				return common.Hash{}, err
+			}
+			if err := s.b.PrivateTxManagerAvailable(); err != nil {
+				return common.Hash{}, err
+			}
+			if s.b.SimulatePrivateTransactions() {
+				result, err := simulatePrivateTransaction(ctx, s.b, args, data)
+				if err != nil {
+					return common.Hash{}, err
+				}
+				if result.Failed {
+					return common.Hash{}, &privateSimulationError{result}
+				}
+			}
 			log.Info("sending private tx", "data", fmt.Sprintf("%x", data), "privatefrom", args.PrivateFrom, "privatefor", args.PrivateFor)
 			data, err = private.P.Send(data, args.PrivateFrom, args.PrivateFor)
 			log.Info("sent private tx", "data", fmt.Sprintf("%x", data), "privatefrom", args.PrivateFrom, "privatefor", args.PrivateFor)
@@ -409,10 +431,6 @@ func (s *PrivateAccountAPI) SendTransaction(ctx context.Context, args SendTxArgs
 		args.Data = &d
 	}
 
-	// Set some sanity defaults and terminate on failure
-	if err := args.setDefaults(ctx, s.b); err != nil {
-		return common.Hash{}, err
-	}
 	// Assemble the transaction and sign with the wallet
 	tx := args.toTransaction()
 
@@ -728,11 +746,45 @@ type CallArgs struct {
 	GasPrice hexutil.Big     `json:"gasPrice"`
 	Value    hexutil.Big     `json:"value"`
 	Data     hexutil.Bytes   `json:"data"`
+
+	// Quorum
+	PrivateFor []string `json:"privateFor"` // Parties of the private transaction, selecting the private state
+	Privacy    string   `json:"privacy"`    // State to execute against ("public" or "private"), empty to pick implicitly
+}
+
+// Privacy selectors of call and gas estimation requests.
+const (
+	callPrivacyPublic  = "public"  // Execute against the public state only
+	callPrivacyPrivate = "private" // Execute against the private state
+)
+
+// privacy returns the state a call explicitly selects, or an empty string if
+// the node picks it by where the called contract lives.
+func (args *CallArgs) privacy() (string, error) {
+	switch args.Privacy {
+	case "":
+		if len(args.PrivateFor) > 0 {
+			return callPrivacyPrivate, nil
+		}
+		return "", nil
+	case callPrivacyPublic:
+		if len(args.PrivateFor) > 0 {
+			return "", fmt.Errorf("privateFor can't be set on %q calls", callPrivacyPublic)
+		}
+		return callPrivacyPublic, nil
+	case callPrivacyPrivate:
+		return callPrivacyPrivate, nil
+	}
+	return "", fmt.Errorf("invalid privacy %q, expected %q or %q", args.Privacy, callPrivacyPublic, callPrivacyPrivate)
 }
 
 func (s *PublicBlockChainAPI) doCall(ctx context.Context, args CallArgs, blockNr rpc.BlockNumber, vmCfg vm.Config, timeout time.Duration) ([]byte, uint64, bool, error) {
 	defer func(start time.Time) { log.Debug("Executing EVM call finished", "runtime", time.Since(start)) }(time.Now())
 
+	privacy, err := args.privacy()
+	if err != nil {
+		return nil, 0, false, err
+	}
 	state, header, err := s.b.StateAndHeaderByNumber(ctx, blockNr)
 	if state == nil || err != nil {
 		return nil, 0, false, err
@@ -771,8 +823,15 @@ func (s *PublicBlockChainAPI) doCall(ctx context.Context, args CallArgs, blockNr
 	// this makes sure resources are cleaned up.
 	defer cancel()
 
-	// Get a new instance of the EVM.
-	evm, vmError, err := s.b.GetEVM(ctx, msg, state, header, vmCfg)
+	// Get a new instance of the EVM, on the explicitly selected state if any
+	var evmMsg core.Message = msg
+	switch privacy {
+	case callPrivacyPublic:
+		evmMsg = publicCallMsg{msg}
+	case callPrivacyPrivate:
+		evmMsg = privateCallMsg{msg}
+	}
+	evm, vmError, err := s.b.GetEVM(ctx, evmMsg, state, header, vmCfg)
 	if err != nil {
 		return nil, 0, false, err
 	}
@@ -802,7 +861,15 @@ func (s *PublicBlockChainAPI) Call(ctx context.Context, args CallArgs, blockNr r
 
 // EstimateGas returns an estimate of the amount of gas needed to execute the
 // given transaction against the current pending block.
+//
+// Quorum: calls selecting the private state are estimated by executing the real
+// payload against it, charging the intrinsic gas of the encrypted payload hash
+// the transaction will carry instead.
 func (s *PublicBlockChainAPI) EstimateGas(ctx context.Context, args CallArgs) (hexutil.Uint64, error) {
+	privacy, err := args.privacy()
+	if err != nil {
+		return 0, err
+	}
 	// Binary search the gas requirement, as it may be higher than the amount used
 	var (
 		lo  uint64 = params.TxGas - 1
@@ -849,6 +916,22 @@ func (s *PublicBlockChainAPI) EstimateGas(ctx context.Context, args CallArgs) (h
 
 	//QUORUM
 
+	isHomestead := s.b.ChainConfig().IsHomestead(new(big.Int).SetInt64(int64(rpc.PendingBlockNumber)))
+	intrinsicGasPublic, _ := core.IntrinsicGas(args.Data, args.To == nil, isHomestead)
+	intrinsicGasPrivate, _ := core.IntrinsicGas(common.Hex2Bytes(maxPrivateIntrinsicDataHex), args.To == nil, isHomestead)
+
+	switch privacy {
+	case callPrivacyPublic:
+		return hexutil.Uint64(hi), nil
+	case callPrivacyPrivate:
+		// The payload was executed above, but the transaction pays the intrinsic
+		// gas of the payload hash, assuming none of its bytes are zero
+		if math.MaxUint64-hi+intrinsicGasPublic < intrinsicGasPrivate {
+			return 0, fmt.Errorf("private intrinsic gas addition exceeds allowance")
+		}
+		return hexutil.Uint64(hi - intrinsicGasPublic + intrinsicGasPrivate), nil
+	}
+
 	//We don't know if this is going to be a private or public transaction
 	//It is possible to have a data field that has a lower intrinsic value than the PTM hash
 	//so this checks that if we were to place a PTM hash (with all non-zero values) here then the transaction would
@@ -857,11 +940,6 @@ func (s *PublicBlockChainAPI) EstimateGas(ctx context.Context, args CallArgs) (h
 
 	//if the transaction has a value then it cannot be private, so we can skip this check
 	if args.Value.ToInt().Cmp(big.NewInt(0)) == 0 {
-
-		isHomestead := s.b.ChainConfig().IsHomestead(new(big.Int).SetInt64(int64(rpc.PendingBlockNumber)))
-		intrinsicGasPublic, _ := core.IntrinsicGas(args.Data, args.To == nil, isHomestead)
-		intrinsicGasPrivate, _ := core.IntrinsicGas(common.Hex2Bytes(maxPrivateIntrinsicDataHex), args.To == nil, isHomestead)
-
 		if intrinsicGasPrivate > intrinsicGasPublic {
 			if math.MaxUint64-hi < intrinsicGasPrivate-intrinsicGasPublic {
 				return 0, fmt.Errorf("private intrinsic gas addition exceeds allowance")
@@ -1235,6 +1313,14 @@ func (s *PublicTransactionPoolAPI) GetTransactionReceipt(ctx context.Context, ha
 	if receipt.ContractAddress != (common.Address{}) {
 		fields["contractAddress"] = receipt.ContractAddress
 	}
+	if len(receipt.RevertData) > 0 {
+		if reason, err := abi.UnpackRevert(receipt.RevertData); err == nil {
+			fields["revertReason"] = reason
+		}
+	}
+	if receipt.PrivateStateChecksum != nil {
+		fields["privateStateChecksum"] = receipt.PrivateStateChecksum
+	}
 	return fields, nil
 }
 
@@ -1350,6 +1436,143 @@ func submitTransaction(ctx context.Context, b Backend, tx *types.Transaction, is
 	return tx.Hash(), nil
 }
 
+// validatePrivateParties checks that the sender and recipients of a private
+// transaction are valid transaction manager public keys, i.e. base64 encoded
+// 32 byte keys, before anything is sent to the transaction manager. The check
+// is only done if the backend checks private payloads (--txpool.checkprivate).
+func validatePrivateParties(b Backend, privateFrom string, privateFor []string) error {
+	if !b.CheckPrivatePayloads() {
+		return nil
+	}
+	if privateFrom != "" {
+		if key, err := base64.StdEncoding.DecodeString(privateFrom); err != nil || len(key) != 32 {
+			return fmt.Errorf("invalid privateFrom %q: not a base64 encoded 32 byte public key", privateFrom)
+		}
+	}
+	for _, recipient := range privateFor {
+		if key, err := base64.StdEncoding.DecodeString(recipient); err != nil || len(key) != 32 {
+			return fmt.Errorf("invalid privateFor recipient %q: not a base64 encoded 32 byte public key", recipient)
+		}
+	}
+	return nil
+}
+
+// PrivateSimulationResult is the outcome of executing a private payload against
+// the local private state.
+type PrivateSimulationResult struct {
+	GasUsed      hexutil.Uint64 `json:"gasUsed"`
+	Failed       bool           `json:"failed"`
+	RevertReason string         `json:"revertReason,omitempty"`
+	ReturnValue  hexutil.Bytes  `json:"returnValue"`
+}
+
+// privateSimulationError is returned if a private transaction is rejected because
+// its payload fails against the local private state.
+type privateSimulationError struct {
+	result *PrivateSimulationResult
+}
+
+func (e *privateSimulationError) Error() string {
+	if e.result.RevertReason != "" {
+		return fmt.Sprintf("private transaction simulation reverted: %s (gas used %d)", e.result.RevertReason, e.result.GasUsed)
+	}
+	return fmt.Sprintf("private transaction simulation failed (gas used %d)", e.result.GasUsed)
+}
+
+// privateCallMsg marks a call message as private, so the backend executes it
+// against the private state even when it creates a contract.
+type privateCallMsg struct {
+	types.Message
+}
+
+func (privateCallMsg) IsPrivate() bool { return true }
+
+// publicCallMsg marks a call message as public, so the backend executes it
+// against the public state even when the called address is a private contract.
+type publicCallMsg struct {
+	types.Message
+}
+
+func (publicCallMsg) IsPublic() bool { return true }
+
+// simulatePrivateTransaction executes the unencrypted payload of a private
+// transaction against the pending private state, without sending it anywhere.
+func simulatePrivateTransaction(ctx context.Context, b Backend, args SendTxArgs, data []byte) (*PrivateSimulationResult, error) {
+	defer func(start time.Time) { log.Debug("Simulating private transaction finished", "runtime", time.Since(start)) }(time.Now())
+
+	state, header, err := b.StateAndHeaderByNumber(ctx, rpc.PendingBlockNumber)
+	if state == nil || err != nil {
+		return nil, err
+	}
+	// The transaction pays the intrinsic gas of the payload hash rather than of
+	// the payload, assuming none of the hash bytes are zero, so the execution is
+	// given the same allowance as it gets once the transaction is mined
+	homestead := b.ChainConfig().IsHomestead(header.Number)
+	intrinsicGasPublic, err := core.IntrinsicGas(data, args.To == nil, homestead)
+	if err != nil {
+		return nil, err
+	}
+	intrinsicGasPrivate, err := core.IntrinsicGas(common.Hex2Bytes(maxPrivateIntrinsicDataHex), args.To == nil, homestead)
+	if err != nil {
+		return nil, err
+	}
+	if uint64(*args.Gas) < intrinsicGasPrivate {
+		return nil, core.ErrIntrinsicGas
+	}
+	gasLimit := uint64(*args.Gas) - intrinsicGasPrivate
+	if math.MaxUint64-gasLimit < intrinsicGasPublic {
+		return nil, fmt.Errorf("private intrinsic gas substitution exceeds allowance")
+	}
+	msg := types.NewMessage(args.From, args.To, uint64(*args.Nonce), args.Value.ToInt(), gasLimit+intrinsicGasPublic, args.GasPrice.ToInt(), data, false)
+
+	ctx, cancel := context.WithTimeout(ctx, b.PrivateSimulationTimeout())
+	defer cancel()
+
+	evm, vmError, err := b.GetEVM(ctx, privateCallMsg{msg}, state, header, vm.Config{})
+	if err != nil {
+		return nil, err
+	}
+	go func() {
+		<-ctx.Done()
+		evm.Cancel()
+	}()
+	gp := new(core.GasPool).AddGas(math.MaxUint64)
+	res, gas, failed, err := core.ApplyMessage(evm, msg, gp)
+	if err := vmError(); err != nil {
+		return nil, err
+	}
+	if err != nil {
+		return nil, err
+	}
+	result := &PrivateSimulationResult{
+		GasUsed:     hexutil.Uint64(gas - intrinsicGasPublic + intrinsicGasPrivate),
+		Failed:      failed,
+		ReturnValue: res,
+	}
+	if failed {
+		if reason, err := abi.UnpackRevert(res); err == nil {
+			result.RevertReason = reason
+		}
+	}
+	return result, nil
+}
+
+// SimulatePrivateTransaction executes the payload of a private transaction
+// against the pending private state of the node, returning the gas used and the
+// revert reason if it fails. Nothing is sent to the privacy manager.
+func (s *PublicTransactionPoolAPI) SimulatePrivateTransaction(ctx context.Context, args SendTxArgs) (*PrivateSimulationResult, error) {
+	if err := args.setDefaults(ctx, s.b); err != nil {
+		return nil, err
+	}
+	var data []byte
+	if args.Data != nil {
+		data = []byte(*args.Data)
+	} else if args.Input != nil {
+		data = []byte(*args.Input)
+	}
+	return simulatePrivateTransaction(ctx, s.b, args, data)
+}
+
 // SendTransaction creates a transaction for the given argument, sign it and submit it to the
 // transaction pool.
 func (s *PublicTransactionPoolAPI) SendTransaction(ctx context.Context, args SendTxArgs) (common.Hash, error) {
@@ -1369,6 +1592,11 @@ func (s *PublicTransactionPoolAPI) SendTransaction(ctx context.Context, args Sen
 		defer s.nonceLock.UnlockAddr(args.From)
 	}
 
+	// Set some sanity defaults and terminate on failure
+	if err := args.setDefaults(ctx, s.b); err != nil {
+		return common.Hash{}, err
+	}
+
 	isPrivate := args.PrivateFor != nil
 	var data []byte
 	if isPrivate {
@@ -1379,6 +1607,23 @@ func (s *PublicTransactionPoolAPI) SendTransaction(ctx context.Context, args Sen
 		}
 
 		if len(data) > 0 {
+			if err := validatePrivateParties(s.b, args.PrivateFrom, args.PrivateFor); err != nil {
+				return common.Hash{}, err
+			}
+			if err := s.b.PrivateTxManagerAvailable(); err != nil {
+				return common.Hash{}, err
+			}
+			// Reject payloads failing against the local private state before
+			// distributing them to the other participants
+			if s.b.SimulatePrivateTransactions() {
+				result, err := simulatePrivateTransaction(ctx, s.b, args, data)
+				if err != nil {
+					return common.Hash{}, err
+				}
+				if result.Failed {
+					return common.Hash{}, &privateSimulationError{result}
+				}
+			}
 			//Send private transaction to local Constellation node
 			log.Info("sending private tx", "data", fmt.Sprintf("%x", data), "privatefrom", args.PrivateFrom, "privatefor", args.PrivateFor)
 			data, err = private.P.Send(data, args.PrivateFrom, args.PrivateFor)
@@ -1392,11 +1637,6 @@ func (s *PublicTransactionPoolAPI) SendTransaction(ctx context.Context, args Sen
 		args.Data = &d
 	}
 
-	// Set some sanity defaults and terminate on failure
-	if err := args.setDefaults(ctx, s.b); err != nil {
-		return common.Hash{}, err
-	}
-
 	// Assemble the transaction and sign with the wallet
 	tx := args.toTransaction()
 
@@ -1436,6 +1676,12 @@ func (s *PublicTransactionPoolAPI) SendRawPrivateTransaction(ctx context.Context
 
 	if isPrivate {
 		if len(txHash) > 0 {
+			if err := validatePrivateParties(s.b, "", args.PrivateFor); err != nil {
+				return common.Hash{}, err
+			}
+			if err := s.b.PrivateTxManagerAvailable(); err != nil {
+				return common.Hash{}, err
+			}
 			//Send private transaction to privacy manager
 			log.Info("sending private tx", "data", fmt.Sprintf("%x", txHash), "privatefor", args.PrivateFor)
 			result, err := private.P.SendSignedTx(txHash, args.PrivateFor)
@@ -20,6 +20,7 @@ package ethapi
 import (
 	"context"
 	"math/big"
+	"time"
 
 	"github.com/ethereum/go-ethereum/accounts"
 	"github.com/ethereum/go-ethereum/common"
@@ -68,10 +69,17 @@ type Backend interface {
 
 	ChainConfig() *params.ChainConfig
 	CurrentBlock() *types.Block
+
+	// Quorum
+	PrivateTxManagerAvailable() error
+	CheckPrivatePayloads() bool
+	SimulatePrivateTransactions() bool
+	PrivateSimulationTimeout() time.Duration
 }
 
-func GetAPIs(apiBackend Backend) []rpc.API {
-	nonceLock := new(AddrLocker)
+// GetAPIs returns the common API services, assigning nonces under the given
+// lock so that other services sending transactions can share it.
+func GetAPIs(apiBackend Backend, nonceLock *AddrLocker) []rpc.API {
 	return []rpc.API{
 		{
 			Namespace: "eth",
@@ -18,21 +18,23 @@
 package web3ext
 
 var Modules = map[string]string{
-	"admin":      Admin_JS,
-	"chequebook": Chequebook_JS,
-	"clique":     Clique_JS,
-	"ethash":     Ethash_JS,
-	"debug":      Debug_JS,
-	"eth":        Eth_JS,
-	"miner":      Miner_JS,
-	"net":        Net_JS,
-	"personal":   Personal_JS,
-	"rpc":        RPC_JS,
-	"shh":        Shh_JS,
-	"swarmfs":    SWARMFS_JS,
-	"txpool":     TxPool_JS,
-	"raft":       Raft_JS,
-	"istanbul":   Istanbul_JS,
+	"admin":           Admin_JS,
+	"chequebook":      Chequebook_JS,
+	"clique":          Clique_JS,
+	"ethash":          Ethash_JS,
+	"debug":           Debug_JS,
+	"eth":             Eth_JS,
+	"miner":           Miner_JS,
+	"net":             Net_JS,
+	"personal":        Personal_JS,
+	"rpc":             RPC_JS,
+	"shh":             Shh_JS,
+	"swarmfs":         SWARMFS_JS,
+	"txpool":          TxPool_JS,
+	"raft":            Raft_JS,
+	"istanbul":        Istanbul_JS,
+	"quorumExtension": QuorumExtension_JS,
+	"quorum":          Quorum_JS,
 }
 
 const Chequebook_JS = `
@@ -164,6 +166,16 @@ web3._extend({
 			call: 'admin_removeTrustedPeer',
 			params: 1
 		}),
+		new web3._extend.Method({
+			name: 'addPermissionedNode',
+			call: 'admin_addPermissionedNode',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'removePermissionedNode',
+			call: 'admin_removePermissionedNode',
+			params: 1
+		}),
 		new web3._extend.Method({
 			name: 'exportChain',
 			call: 'admin_exportChain',
@@ -175,6 +187,11 @@ web3._extend({
 			call: 'admin_importChain',
 			params: 1
 		}),
+		new web3._extend.Method({
+			name: 'privateStateDivergences',
+			call: 'admin_privateStateDivergences',
+			params: 0
+		}),
 		new web3._extend.Method({
 			name: 'sleepBlocks',
 			call: 'admin_sleepBlocks',
@@ -206,6 +223,14 @@ web3._extend({
 			name: 'nodeInfo',
 			getter: 'admin_nodeInfo'
 		}),
+		new web3._extend.Property({
+			name: 'permissionedNodes',
+			getter: 'admin_permissionedNodes'
+		}),
+		new web3._extend.Property({
+			name: 'permissionAudit',
+			getter: 'admin_permissionAudit'
+		}),
 		new web3._extend.Property({
 			name: 'peers',
 			getter: 'admin_peers'
@@ -447,6 +472,12 @@ web3._extend({
 			params: 2,
 			inputFormatter: [null, null]
 		}),
+		new web3._extend.Method({
+			name: 'simulatePrivateTransaction',
+			call: 'eth_simulatePrivateTransaction',
+			params: 1,
+			inputFormatter: [web3._extend.formatters.inputTransactionFormatter]
+		}),
 		new web3._extend.Method({
 			name: 'chainId',
 			call: 'eth_chainId',
@@ -768,6 +799,11 @@ web3._extend({
 			call: 'istanbul_getValidatorsAtHash',
 			params: 1
 		}),
+		new web3._extend.Method({
+			name: 'getValidatorHistory',
+			call: 'istanbul_getValidatorHistory',
+			params: 0
+		}),
 		new web3._extend.Method({
 			name: 'propose',
 			call: 'istanbul_propose',
@@ -788,3 +824,51 @@ web3._extend({
 	]
 });
 `
+
+const QuorumExtension_JS = `
+web3._extend({
+	property: 'quorumExtension',
+	methods:
+	[
+		new web3._extend.Method({
+			name: 'extendContract',
+			call: 'quorumExtension_extendContract',
+			params: 5,
+			inputFormatter: [web3._extend.formatters.inputAddressFormatter, null, web3._extend.formatters.inputAddressFormatter, null, web3._extend.formatters.inputTransactionFormatter]
+		}),
+		new web3._extend.Method({
+			name: 'approveExtension',
+			call: 'quorumExtension_approveExtension',
+			params: 3,
+			inputFormatter: [web3._extend.formatters.inputAddressFormatter, null, web3._extend.formatters.inputTransactionFormatter]
+		}),
+		new web3._extend.Method({
+			name: 'cancelExtension',
+			call: 'quorumExtension_cancelExtension',
+			params: 2,
+			inputFormatter: [web3._extend.formatters.inputAddressFormatter, web3._extend.formatters.inputTransactionFormatter]
+		}),
+	],
+	properties:
+	[
+		new web3._extend.Property({
+			name: 'activeExtensionContracts',
+			getter: 'quorumExtension_activeExtensionContracts'
+		}),
+	]
+});
+`
+
+const Quorum_JS = `
+web3._extend({
+	property: 'quorum',
+	methods: [],
+	properties:
+	[
+		new web3._extend.Property({
+			name: 'transactionManagerStatus',
+			getter: 'quorum_transactionManagerStatus'
+		}),
+	]
+});
+`
@@ -19,6 +19,7 @@ package les
 import (
 	"context"
 	"math/big"
+	"time"
 
 	"github.com/ethereum/go-ethereum/accounts"
 	"github.com/ethereum/go-ethereum/common"
@@ -176,6 +177,22 @@ func (b *LesApiBackend) SuggestPrice(ctx context.Context) (*big.Int, error) {
 	return b.gpo.SuggestPrice(ctx)
 }
 
+func (b *LesApiBackend) PrivateTxManagerAvailable() error {
+	return nil
+}
+
+func (b *LesApiBackend) CheckPrivatePayloads() bool {
+	return b.eth.config.TxPool.CheckPrivatePayloads
+}
+
+func (b *LesApiBackend) SimulatePrivateTransactions() bool {
+	return b.eth.config.PrivateSimulation
+}
+
+func (b *LesApiBackend) PrivateSimulationTimeout() time.Duration {
+	return b.eth.config.PrivateSimulationTimeout
+}
+
 func (b *LesApiBackend) ChainDb() ethdb.Database {
 	return b.eth.chainDb
 }
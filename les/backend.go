@@ -186,7 +186,7 @@ func (s *LightDummyAPI) Mining() bool {
 // APIs returns the collection of RPC services the ethereum package offers.
 // NOTE, some of these services probably need to be moved to somewhere else.
 func (s *LightEthereum) APIs() []rpc.API {
-	return append(ethapi.GetAPIs(s.ApiBackend), []rpc.API{
+	return append(ethapi.GetAPIs(s.ApiBackend, new(ethapi.AddrLocker)), []rpc.API{
 		{
 			Namespace: "eth",
 			Version:   "1.0",
@@ -200,7 +200,7 @@ func (s *LightEthereum) APIs() []rpc.API {
 		}, {
 			Namespace: "eth",
 			Version:   "1.0",
-			Service:   filters.NewPublicFilterAPI(s.ApiBackend, true),
+			Service:   filters.NewPublicFilterAPI(s.ApiBackend, true, filters.WithRangeLimit(s.config.LogsBlockRange)),
 			Public:    true,
 		}, {
 			Namespace: "net",
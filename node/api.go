@@ -75,6 +75,66 @@ func (api *PrivateAdminAPI) RemovePeer(url string) (bool, error) {
 	return true, nil
 }
 
+// AddPermissionedNode permits a remote node to connect if node permissioning is
+// enabled, persisting the change to the permissioned nodes file.
+func (api *PrivateAdminAPI) AddPermissionedNode(url string) (bool, error) {
+	// Make sure the server is running, fail otherwise
+	server := api.node.Server()
+	if server == nil {
+		return false, ErrNodeStopped
+	}
+	node, err := enode.ParseV4(url)
+	if err != nil {
+		return false, fmt.Errorf("invalid enode: %v", err)
+	}
+	return server.AddPermissionedNode(node)
+}
+
+// RemovePermissionedNode revokes the permission of a remote node to connect,
+// persisting the change and disconnecting the node if it is connected.
+func (api *PrivateAdminAPI) RemovePermissionedNode(url string) (bool, error) {
+	// Make sure the server is running, fail otherwise
+	server := api.node.Server()
+	if server == nil {
+		return false, ErrNodeStopped
+	}
+	node, err := enode.ParseV4(url)
+	if err != nil {
+		return false, fmt.Errorf("invalid enode: %v", err)
+	}
+	return server.RemovePermissionedNode(node)
+}
+
+// PermissionedNodes returns the remote nodes permitted to connect if node
+// permissioning is enabled.
+func (api *PrivateAdminAPI) PermissionedNodes() ([]string, error) {
+	// Make sure the server is running, fail otherwise
+	server := api.node.Server()
+	if server == nil {
+		return nil, ErrNodeStopped
+	}
+	nodes, err := server.PermissionedNodes()
+	if err != nil {
+		return nil, err
+	}
+	urls := make([]string, len(nodes))
+	for i, node := range nodes {
+		urls[i] = node.String()
+	}
+	return urls, nil
+}
+
+// PermissionAudit returns the recent decisions of node permissioning on
+// connections of remote nodes, oldest first.
+func (api *PrivateAdminAPI) PermissionAudit() ([]p2p.PermissionDecision, error) {
+	// Make sure the server is running, fail otherwise
+	server := api.node.Server()
+	if server == nil {
+		return nil, ErrNodeStopped
+	}
+	return server.PermissionAudit()
+}
+
 // AddTrustedPeer allows a remote node to always connect, even if slots are full
 func (api *PrivateAdminAPI) AddTrustedPeer(url string) (bool, error) {
 	// Make sure the server is running, fail otherwise
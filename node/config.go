@@ -124,6 +124,24 @@ type Config struct {
 	// interface.
 	HTTPTimeouts rpc.HTTPTimeouts
 
+	// RPCTLS contains the certificates the HTTP and websocket RPC interfaces are
+	// served with over TLS. If no certificate is configured, they are served in
+	// cleartext.
+	RPCTLS rpc.TLSConfig
+
+	// RPCLimits are the limits imposed on the requests of the clients of the HTTP
+	// and websocket RPC interfaces.
+	RPCLimits rpc.Limits
+
+	// RPCAccessLogDir is the directory the requests served over the HTTP and
+	// websocket RPC interfaces are logged into as rotating JSON files, relative
+	// to the instance directory unless absolute. If empty, no access log is kept.
+	RPCAccessLogDir string `toml:",omitempty"`
+
+	// Health holds the thresholds of the health and readiness checks served on
+	// the /health and /ready paths of the HTTP RPC interface.
+	Health HealthConfig
+
 	// WSHost is the host interface on which to start the websocket RPC server. If
 	// this field is empty, no websocket API endpoint will be started.
 	WSHost string `toml:",omitempty"`
@@ -151,6 +169,12 @@ type Config struct {
 	WSExposeAll bool `toml:",omitempty"`
 
 	EnableNodePermission bool `toml:",omitempty"`
+
+	// PermissionAuditDir is the directory node permissioning decisions are logged
+	// into, relative to the instance directory unless absolute. If empty, the
+	// decisions are only kept in memory.
+	PermissionAuditDir string `toml:",omitempty"`
+
 	// Logger is a custom logger to use with the p2p.Server.
 	Logger log.Logger `toml:",omitempty"`
 }
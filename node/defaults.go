@@ -43,6 +43,12 @@ var DefaultConfig = Config{
 	HTTPTimeouts:     rpc.DefaultHTTPTimeouts,
 	WSPort:           DefaultWSPort,
 	WSModules:        []string{"net", "web3"},
+	Health: HealthConfig{
+		MinPeers:            1,
+		MaxBlocksBehind:     10,
+		ParticipationBlocks: 64,
+		MinParticipation:    0.67,
+	},
 	P2P: p2p.Config{
 		ListenAddr: ":30303",
 		MaxPeers:   25,
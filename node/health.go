@@ -0,0 +1,121 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package node
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// HealthConfig holds the thresholds the health and readiness checks of a node
+// judge it by.
+type HealthConfig struct {
+	MinPeers            int     `toml:",omitempty"` // Minimum number of peers to be ready
+	MaxBlocksBehind     uint64  `toml:",omitempty"` // Maximum number of blocks behind the best known peer to be ready
+	ParticipationBlocks uint64  `toml:",omitempty"` // Number of recent blocks inspected for Istanbul validator participation
+	MinParticipation    float64 `toml:",omitempty"` // Minimum fraction of Istanbul validators committing recent blocks to be ready
+}
+
+// HealthCheck is the outcome of checking a part of the node. A healthy part
+// works, though it may not be ready to serve requests yet, e.g. while syncing.
+type HealthCheck struct {
+	Healthy bool        `json:"healthy"`
+	Ready   bool        `json:"ready"`
+	Message string      `json:"message,omitempty"`
+	Details interface{} `json:"details,omitempty"`
+}
+
+// HealthChecker is implemented by services contributing checks to the health
+// and readiness reports of the node, by check name.
+type HealthChecker interface {
+	HealthChecks(config *HealthConfig) map[string]*HealthCheck
+}
+
+// HealthReport is the breakdown of the health of a node. The node is healthy
+// or ready if all its checks are.
+type HealthReport struct {
+	Healthy bool                    `json:"healthy"`
+	Ready   bool                    `json:"ready"`
+	Checks  map[string]*HealthCheck `json:"checks"`
+}
+
+// Health checks the peers of the node and all services implementing HealthChecker.
+// A node that isn't running is neither healthy nor ready.
+func (n *Node) Health() *HealthReport {
+	n.lock.RLock()
+	server, services := n.server, n.services
+	n.lock.RUnlock()
+
+	report := &HealthReport{Checks: make(map[string]*HealthCheck)}
+	if server == nil {
+		return report
+	}
+	config := n.config.Health
+
+	peers := &HealthCheck{Healthy: true, Details: map[string]int{"peers": server.PeerCount(), "minimum": config.MinPeers}}
+	if peers.Ready = server.PeerCount() >= config.MinPeers; !peers.Ready {
+		peers.Message = fmt.Sprintf("%d peers, want at least %d", server.PeerCount(), config.MinPeers)
+	}
+	report.Checks["peers"] = peers
+
+	for _, service := range services {
+		if checker, ok := service.(HealthChecker); ok {
+			for name, check := range checker.HealthChecks(&config) {
+				report.Checks[name] = check
+			}
+		}
+	}
+	report.Healthy, report.Ready = true, true
+	for _, check := range report.Checks {
+		report.Healthy = report.Healthy && check.Healthy
+		report.Ready = report.Ready && check.Healthy && check.Ready
+	}
+	return report
+}
+
+// healthHandlers returns the handlers serving the health report of the node on
+// the HTTP RPC interface.
+func (n *Node) healthHandlers() map[string]http.Handler {
+	return map[string]http.Handler{
+		"/health": &healthHandler{node: n},
+		"/ready":  &healthHandler{node: n, ready: true},
+	}
+}
+
+// healthHandler serves the health report of a node, with status 503 unless the
+// node is healthy, or ready if required.
+type healthHandler struct {
+	node  *Node
+	ready bool
+}
+
+func (h *healthHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	report := h.node.Health()
+
+	status := http.StatusOK
+	if !report.Healthy || (h.ready && !report.Ready) {
+		status = http.StatusServiceUnavailable
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(report)
+}
@@ -0,0 +1,99 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package node
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+// HealthTestService is a service contributing a configurable check to the
+// health report of the node.
+type HealthTestService struct {
+	NoopService
+	check HealthCheck
+}
+
+func (s *HealthTestService) HealthChecks(config *HealthConfig) map[string]*HealthCheck {
+	check := s.check
+	return map[string]*HealthCheck{"test": &check}
+}
+
+// Tests that the health and readiness endpoints report the checks of the node
+// and its services, probed by the IP address of the node.
+func TestHealthEndpoints(t *testing.T) {
+	config := testNodeConfig()
+	config.HTTPHost = "127.0.0.1"
+	config.HTTPVirtualHosts = []string{"localhost"}
+
+	stack, err := New(config)
+	if err != nil {
+		t.Fatalf("failed to create protocol stack: %v", err)
+	}
+	service := &HealthTestService{check: HealthCheck{Healthy: true, Ready: true}}
+	if err := stack.Register(func(*ServiceContext) (Service, error) { return service, nil }); err != nil {
+		t.Fatalf("failed to register service: %v", err)
+	}
+	if err := stack.Start(); err != nil {
+		t.Fatalf("failed to start protocol stack: %v", err)
+	}
+	defer stack.Stop()
+
+	probe := func(path string) (int, *HealthReport) {
+		resp, err := http.Get(fmt.Sprintf("http://%s%s", stack.httpListener.Addr(), path))
+		if err != nil {
+			t.Fatalf("failed to probe %s: %v", path, err)
+		}
+		defer resp.Body.Close()
+
+		report := new(HealthReport)
+		if err := json.NewDecoder(resp.Body).Decode(report); err != nil {
+			t.Fatalf("failed to decode %s report: %v", path, err)
+		}
+		return resp.StatusCode, report
+	}
+	tests := []struct {
+		minPeers      int
+		check         HealthCheck
+		health, ready int
+	}{
+		{0, HealthCheck{Healthy: true, Ready: true}, http.StatusOK, http.StatusOK},
+		{1, HealthCheck{Healthy: true, Ready: true}, http.StatusOK, http.StatusServiceUnavailable},
+		{0, HealthCheck{Healthy: true, Ready: false}, http.StatusOK, http.StatusServiceUnavailable},
+		{0, HealthCheck{Healthy: false, Ready: true}, http.StatusServiceUnavailable, http.StatusServiceUnavailable},
+	}
+	for i, tt := range tests {
+		stack.config.Health.MinPeers = tt.minPeers
+		service.check = tt.check
+
+		if status, report := probe("/health"); status != tt.health {
+			t.Errorf("test %d: /health status mismatch: have %d, want %d (%+v)", i, status, tt.health, report)
+		}
+		status, report := probe("/ready")
+		if status != tt.ready {
+			t.Errorf("test %d: /ready status mismatch: have %d, want %d (%+v)", i, status, tt.ready, report)
+		}
+		if report.Checks["peers"] == nil || report.Checks["test"] == nil {
+			t.Errorf("test %d: checks missing from report: %v", i, report.Checks)
+		}
+		if report.Checks["peers"].Ready != (tt.minPeers == 0) {
+			t.Errorf("test %d: peers readiness mismatch: %+v", i, report.Checks["peers"])
+		}
+	}
+}
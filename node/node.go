@@ -17,14 +17,18 @@
 package node
 
 import (
+	"crypto/tls"
 	"errors"
 	"fmt"
 	"net"
+	"net/http"
 	"os"
+	"os/signal"
 	"path/filepath"
 	"reflect"
 	"strings"
 	"sync"
+	"syscall"
 
 	"github.com/ethereum/go-ethereum/accounts"
 	"github.com/ethereum/go-ethereum/ethdb"
@@ -32,10 +36,14 @@ import (
 	"github.com/ethereum/go-ethereum/internal/debug"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/p2p"
+	"github.com/ethereum/go-ethereum/params"
 	"github.com/ethereum/go-ethereum/rpc"
 	"github.com/prometheus/prometheus/util/flock"
 )
 
+// rpcAccessLogLimit is the size of the RPC access log files before rotating them.
+const rpcAccessLogLimit = 4 * 1024 * 1024
+
 // Node is a container on which services can be registered.
 type Node struct {
 	eventmux *event.TypeMux // Event multiplexer used between the services of a stack
@@ -58,15 +66,19 @@ type Node struct {
 	ipcListener net.Listener // IPC RPC listener socket to serve API requests
 	ipcHandler  *rpc.Server  // IPC RPC request handler to process the API requests
 
-	httpEndpoint  string       // HTTP endpoint (interface + port) to listen at (empty = HTTP disabled)
-	httpWhitelist []string     // HTTP RPC modules to allow through this endpoint
-	httpListener  net.Listener // HTTP RPC listener socket to server API requests
-	httpHandler   *rpc.Server  // HTTP RPC request handler to process the API requests
+	httpEndpoint  string                  // HTTP endpoint (interface + port) to listen at (empty = HTTP disabled)
+	httpWhitelist []string                // HTTP RPC modules to allow through this endpoint
+	httpListener  net.Listener            // HTTP RPC listener socket to server API requests
+	httpHandler   *rpc.Server             // HTTP RPC request handler to process the API requests
+	httpHandlers  map[string]http.Handler // Handlers of the node and its services served next to the API
 
 	wsEndpoint string       // Websocket endpoint (interface + port) to listen at (empty = websocket disabled)
 	wsListener net.Listener // Websocket RPC listener socket to server API requests
 	wsHandler  *rpc.Server  // Websocket RPC request handler to process the API requests
 
+	tlsCredentials *rpc.TLSCredentials // Certificates of the HTTP and websocket endpoints (nil = cleartext)
+	rpcAccessLog   log.Logger          // Access log of the HTTP and websocket endpoints (nil = disabled)
+
 	stop chan struct{} // Channel to wait for termination notifications
 	lock sync.RWMutex
 
@@ -163,6 +175,9 @@ func (n *Node) Start() error {
 		n.serverConfig.NodeDatabase = n.config.NodeDB()
 	}
 	n.serverConfig.EnableNodePermission = n.config.EnableNodePermission
+	if n.config.PermissionAuditDir != "" {
+		n.serverConfig.PermissionAuditDir = n.config.ResolvePath(n.config.PermissionAuditDir)
+	}
 	n.serverConfig.DataDir = n.config.DataDir
 	running := &p2p.Server{Config: n.serverConfig}
 	n.log.Info("Starting peer-to-peer node", "instance", n.serverConfig.Name)
@@ -226,9 +241,33 @@ func (n *Node) Start() error {
 	n.server = running
 	n.stop = make(chan struct{})
 
+	if n.tlsCredentials != nil {
+		go n.reloadTLSCredentials(n.stop)
+	}
 	return nil
 }
 
+// reloadTLSCredentials reloads the certificates of the RPC endpoints whenever
+// the process receives SIGHUP, until the node is stopped.
+func (n *Node) reloadTLSCredentials(stop chan struct{}) {
+	sighup := make(chan os.Signal, 1)
+	signal.Notify(sighup, syscall.SIGHUP)
+	defer signal.Stop(sighup)
+
+	for {
+		select {
+		case <-sighup:
+			if err := n.tlsCredentials.Reload(); err != nil {
+				n.log.Error("Failed to reload RPC certificates", "err", err)
+				continue
+			}
+			n.log.Info("Reloaded RPC certificates", "cert", n.config.RPCTLS.CertFile)
+		case <-stop:
+			return
+		}
+	}
+}
+
 func (n *Node) openDataDir() error {
 	if n.config.DataDir == "" {
 		return nil // ephemeral
@@ -257,6 +296,34 @@ func (n *Node) startRPC(services map[reflect.Type]Service) error {
 	for _, service := range services {
 		apis = append(apis, service.APIs()...)
 	}
+	// Gather the handlers served next to the API on the HTTP endpoint
+	n.httpHandlers = n.healthHandlers()
+	for _, service := range services {
+		if provider, ok := service.(HTTPHandlerProvider); ok {
+			for path, handler := range provider.HTTPHandlers() {
+				n.httpHandlers[path] = handler
+			}
+		}
+	}
+	// Load the certificates of the HTTP and websocket endpoints, if any
+	n.tlsCredentials = nil
+	if n.config.RPCTLS.Enabled() {
+		creds, err := rpc.NewTLSCredentials(n.config.RPCTLS)
+		if err != nil {
+			return err
+		}
+		n.tlsCredentials = creds
+	}
+	// Open the access log of the HTTP and websocket endpoints, if any
+	n.rpcAccessLog = nil
+	if n.config.RPCAccessLogDir != "" {
+		handler, err := log.RotatingFileHandler(n.config.ResolvePath(n.config.RPCAccessLogDir), rpcAccessLogLimit, log.JSONFormatOrderedEx(false, true))
+		if err != nil {
+			return err
+		}
+		n.rpcAccessLog = log.New()
+		n.rpcAccessLog.SetHandler(handler)
+	}
 	// Start the various API endpoints, terminating all in case of errors
 	if err := n.startInProc(apis); err != nil {
 		return err
@@ -338,11 +405,13 @@ func (n *Node) startHTTP(endpoint string, apis []rpc.API, modules []string, cors
 	if endpoint == "" {
 		return nil
 	}
-	listener, handler, err := rpc.StartHTTPEndpoint(endpoint, apis, modules, cors, vhosts, timeouts)
+	options := n.rpcEndpointOptions()
+	options.Handlers = n.httpHandlers
+	listener, handler, err := rpc.StartHTTPEndpoint(endpoint, apis, modules, cors, vhosts, timeouts, options)
 	if err != nil {
 		return err
 	}
-	n.log.Info("HTTP endpoint opened", "url", fmt.Sprintf("http://%s", endpoint), "cors", strings.Join(cors, ","), "vhosts", strings.Join(vhosts, ","))
+	n.log.Info("HTTP endpoint opened", "url", fmt.Sprintf("%s://%s", n.rpcScheme("http"), endpoint), "cors", strings.Join(cors, ","), "vhosts", strings.Join(vhosts, ","))
 	// All listeners booted successfully
 	n.httpEndpoint = endpoint
 	n.httpListener = listener
@@ -357,7 +426,7 @@ func (n *Node) stopHTTP() {
 		n.httpListener.Close()
 		n.httpListener = nil
 
-		n.log.Info("HTTP endpoint closed", "url", fmt.Sprintf("http://%s", n.httpEndpoint))
+		n.log.Info("HTTP endpoint closed", "url", fmt.Sprintf("%s://%s", n.rpcScheme("http"), n.httpEndpoint))
 	}
 	if n.httpHandler != nil {
 		n.httpHandler.Stop()
@@ -371,11 +440,11 @@ func (n *Node) startWS(endpoint string, apis []rpc.API, modules []string, wsOrig
 	if endpoint == "" {
 		return nil
 	}
-	listener, handler, err := rpc.StartWSEndpoint(endpoint, apis, modules, wsOrigins, exposeAll)
+	listener, handler, err := rpc.StartWSEndpoint(endpoint, apis, modules, wsOrigins, exposeAll, n.rpcEndpointOptions())
 	if err != nil {
 		return err
 	}
-	n.log.Info("WebSocket endpoint opened", "url", fmt.Sprintf("ws://%s", listener.Addr()))
+	n.log.Info("WebSocket endpoint opened", "url", fmt.Sprintf("%s://%s", n.rpcScheme("ws"), listener.Addr()))
 	// All listeners booted successfully
 	n.wsEndpoint = endpoint
 	n.wsListener = listener
@@ -390,7 +459,7 @@ func (n *Node) stopWS() {
 		n.wsListener.Close()
 		n.wsListener = nil
 
-		n.log.Info("WebSocket endpoint closed", "url", fmt.Sprintf("ws://%s", n.wsEndpoint))
+		n.log.Info("WebSocket endpoint closed", "url", fmt.Sprintf("%s://%s", n.rpcScheme("ws"), n.wsEndpoint))
 	}
 	if n.wsHandler != nil {
 		n.wsHandler.Stop()
@@ -398,6 +467,34 @@ func (n *Node) stopWS() {
 	}
 }
 
+// rpcEndpointOptions returns the request limits, transport security and access
+// log shared by the HTTP and websocket endpoints.
+func (n *Node) rpcEndpointOptions() rpc.EndpointOptions {
+	return rpc.EndpointOptions{
+		Limits:    n.config.RPCLimits,
+		TLSConfig: n.rpcTLSConfig(),
+		AccessLog: n.rpcAccessLog,
+	}
+}
+
+// rpcTLSConfig returns the TLS configuration of the HTTP and websocket
+// endpoints, or nil if they are served in cleartext.
+func (n *Node) rpcTLSConfig() *tls.Config {
+	if n.tlsCredentials == nil {
+		return nil
+	}
+	return n.tlsCredentials.ServerConfig()
+}
+
+// rpcScheme returns the URL scheme of the HTTP or websocket endpoint, secured
+// if served over TLS.
+func (n *Node) rpcScheme(scheme string) string {
+	if n.tlsCredentials != nil {
+		return scheme + "s"
+	}
+	return scheme
+}
+
 // Stop terminates a running node along with all it's services. In the node was
 // not started, an error is returned.
 func (n *Node) Stop() error {
@@ -619,5 +716,10 @@ func (n *Node) apis() []rpc.API {
 			Service:   NewPublicWeb3API(n),
 			Public:    true,
 		},
+		rpc.NewDiscoverAPI(rpc.OpenRPCInfo{
+			Title:         "Quorum JSON-RPC API",
+			Version:       params.VersionWithMeta,
+			QuorumVersion: params.QuorumVersion,
+		}),
 	}
 }
@@ -18,6 +18,7 @@ package node
 
 import (
 	"crypto/ecdsa"
+	"net/http"
 	"reflect"
 
 	"github.com/ethereum/go-ethereum/accounts"
@@ -102,3 +103,11 @@ type Service interface {
 	// are all terminated.
 	Stop() error
 }
+
+// HTTPHandlerProvider is implemented by services answering requests of their own
+// on the HTTP RPC endpoint, next to the API. The handlers are subject to the same
+// checks, limits and access log as the API.
+type HTTPHandlerProvider interface {
+	// HTTPHandlers retrieves the handlers of the service by the path they serve.
+	HTTPHandlers() map[string]http.Handler
+}
@@ -1,77 +1,441 @@
 package p2p
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"io/ioutil"
+	"net"
 	"os"
 	"path/filepath"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/p2p/enode"
+	"github.com/ethereum/go-ethereum/p2p/netutil"
 )
 
 const (
 	NODE_NAME_LENGTH    = 32
 	PERMISSIONED_CONFIG = "permissioned-nodes.json"
+
+	// permissionsReloadInterval is the interval the permissioned nodes file is
+	// checked for changes at.
+	permissionsReloadInterval = 2 * time.Second
+)
+
+var (
+	errPermissioningDisabled = errors.New("node permissioning is disabled")
+	errNodeNotPermissioned   = errors.New("node not permissioned")
 )
 
-// check if a given node is permissioned to connect to the change
-func isNodePermissioned(nodename string, currentNode string, datadir string, direction string) bool {
+// permissionEntry is a node permitted to connect, optionally only from certain
+// addresses. In the permissioned nodes file, an entry is either the enode URL of
+// a node permitted to connect from anywhere, or an object restricting where it
+// may connect from:
+//
+//	{"enode": "enode://<id>@<ip>:<port>", "allowedIPs": ["10.0.1.0/24"], "endpoint": true}
+//
+// With endpoint set, the node may only connect from the IP of its enode URL and,
+// when dialed, only be reached at its port.
+type permissionEntry struct {
+	node       *enode.Node
+	allowedIPs []string         // Networks the node may connect from, as configured
+	allowed    *netutil.Netlist // Parsed allowed networks (nil = anywhere)
+	endpoint   bool             // Whether the node is bound to the endpoint of its URL
+}
+
+type permissionEntryJSON struct {
+	Enode      string   `json:"enode"`
+	AllowedIPs []string `json:"allowedIPs,omitempty"`
+	Endpoint   bool     `json:"endpoint,omitempty"`
+}
 
-	var permissionedList []string
-	nodes := parsePermissionedNodes(datadir)
-	for _, v := range nodes {
-		permissionedList = append(permissionedList, v.ID().String())
+// MarshalJSON encodes unrestricted entries as plain enode URLs.
+func (e *permissionEntry) MarshalJSON() ([]byte, error) {
+	if len(e.allowedIPs) == 0 && !e.endpoint {
+		return json.Marshal(e.node.String())
 	}
+	return json.Marshal(&permissionEntryJSON{e.node.String(), e.allowedIPs, e.endpoint})
+}
 
-	log.Debug("isNodePermissioned", "permissionedList", permissionedList)
-	for _, v := range permissionedList {
-		if v == nodename {
-			log.Debug("isNodePermissioned", "connection", direction, "nodename", nodename[:NODE_NAME_LENGTH], "ALLOWED-BY", currentNode[:NODE_NAME_LENGTH])
-			return true
+// UnmarshalJSON decodes an enode URL or an entry object.
+func (e *permissionEntry) UnmarshalJSON(input []byte) error {
+	var dec permissionEntryJSON
+	if err := json.Unmarshal(input, &dec.Enode); err != nil {
+		if err := json.Unmarshal(input, &dec); err != nil {
+			return err
 		}
 	}
-	log.Debug("isNodePermissioned", "connection", direction, "nodename", nodename[:NODE_NAME_LENGTH], "DENIED-BY", currentNode[:NODE_NAME_LENGTH])
-	return false
+	if dec.Enode == "" {
+		return errors.New("node URL blank")
+	}
+	node, err := enode.ParseV4(dec.Enode)
+	if err != nil {
+		return err
+	}
+	allowed, err := parseIPList(dec.AllowedIPs)
+	if err != nil {
+		return err
+	}
+	*e = permissionEntry{node: node, allowedIPs: dec.AllowedIPs, allowed: allowed, endpoint: dec.Endpoint}
+	return nil
 }
 
-//this is a shameless copy from the config.go. It is a duplication of the code
-//for the timebeing to allow reload of the permissioned nodes while the server is running
+// permits reports whether the entry's node may connect through a connection
+// with the given remote address.
+func (e *permissionEntry) permits(remote net.Addr, dialed bool) bool {
+	ip, port := addrEndpoint(remote)
+	if e.allowed != nil && !e.allowed.Contains(ip) {
+		return false
+	}
+	if e.endpoint {
+		if !ip.Equal(e.node.IP()) || (dialed && port != e.node.TCP()) {
+			return false
+		}
+	}
+	return true
+}
+
+// permissionList is the content of the permissioned nodes file: either a plain
+// list of entries, or an object also listing the networks no node may connect
+// from, which takes precedence over the entries:
+//
+//	{"nodes": [...], "deny": ["192.168.5.0/24", "10.0.0.13"]}
+type permissionList struct {
+	entries []*permissionEntry
+	deny    []string         // Denied networks, as configured
+	denied  *netutil.Netlist // Parsed denied networks
+}
 
-func parsePermissionedNodes(DataDir string) []*enode.Node {
+type permissionListJSON struct {
+	Nodes []json.RawMessage `json:"nodes"`
+	Deny  []string          `json:"deny,omitempty"`
+}
 
-	log.Debug("parsePermissionedNodes", "DataDir", DataDir, "file", PERMISSIONED_CONFIG)
+// MarshalJSON encodes lists without denied networks as a plain list of entries.
+func (l *permissionList) MarshalJSON() ([]byte, error) {
+	if len(l.deny) == 0 {
+		return json.Marshal(l.entries)
+	}
+	nodes := make([]json.RawMessage, len(l.entries))
+	for i, entry := range l.entries {
+		blob, err := json.Marshal(entry)
+		if err != nil {
+			return nil, err
+		}
+		nodes[i] = blob
+	}
+	return json.Marshal(&permissionListJSON{nodes, l.deny})
+}
 
-	path := filepath.Join(DataDir, PERMISSIONED_CONFIG)
-	if _, err := os.Stat(path); err != nil {
+// UnmarshalJSON decodes a permissioned nodes file. Invalid entries are skipped,
+// leaving their nodes without permission, but invalid denied networks are an
+// error.
+func (l *permissionList) UnmarshalJSON(input []byte) error {
+	var dec permissionListJSON
+	if bytes.HasPrefix(bytes.TrimSpace(input), []byte("[")) {
+		if err := json.Unmarshal(input, &dec.Nodes); err != nil {
+			return err
+		}
+	} else if err := json.Unmarshal(input, &dec); err != nil {
+		return err
+	}
+	denied, err := parseIPList(dec.Deny)
+	if err != nil {
+		return fmt.Errorf("invalid denied network: %v", err)
+	}
+	entries := make([]*permissionEntry, 0, len(dec.Nodes))
+	for _, raw := range dec.Nodes {
+		entry := new(permissionEntry)
+		if err := json.Unmarshal(raw, entry); err != nil {
+			log.Error("parsePermissionedNodes: Node URL", "entry", string(raw), "err", err)
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	*l = permissionList{entries: entries, deny: dec.Deny, denied: denied}
+	return nil
+}
+
+// nodePermissions is the in-memory list of the nodes permitted to connect,
+// kept in sync with the permissioned nodes file of the data directory.
+type nodePermissions struct {
+	path string
+
+	list    *permissionList               // Content of the permissioned nodes file
+	index   map[enode.ID]*permissionEntry // Permissioned nodes by id
+	modTime time.Time                     // Modification time of the file when last read or written
+	lock    sync.RWMutex
+}
+
+// newNodePermissions loads the permissioned nodes of the given data directory.
+// A missing file permits no node at all.
+func newNodePermissions(datadir string) *nodePermissions {
+	p := &nodePermissions{
+		path:  filepath.Join(datadir, PERMISSIONED_CONFIG),
+		list:  new(permissionList),
+		index: make(map[enode.ID]*permissionEntry),
+	}
+	if _, err := p.reload(); err != nil {
 		log.Error("Read Error for permissioned-nodes.json file. This is because 'permissioned' flag is specified but no permissioned-nodes.json file is present.", "err", err)
-		return nil
 	}
+	return p
+}
+
+// isPermissioned reports whether the node with the given id may connect through
+// a connection with the given remote address, dialed by this node or not.
+func (p *nodePermissions) isPermissioned(id enode.ID, remote net.Addr, dialed bool) bool {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+
+	if ip, _ := addrEndpoint(remote); p.list.denied.Contains(ip) {
+		return false
+	}
+	entry, ok := p.index[id]
+	return ok && entry.permits(remote, dialed)
+}
+
+// nodes returns the permissioned nodes.
+func (p *nodePermissions) nodes() []*enode.Node {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+
+	nodes := make([]*enode.Node, len(p.list.entries))
+	for i, entry := range p.list.entries {
+		nodes[i] = entry.node
+	}
+	return nodes
+}
+
+// add permits a node to connect from anywhere and persists the list, reporting
+// whether the node was added or already permitted.
+func (p *nodePermissions) add(node *enode.Node) (bool, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if _, ok := p.index[node.ID()]; ok {
+		return false, nil
+	}
+	list := *p.list
+	list.entries = append(list.entries[:len(list.entries):len(list.entries)], &permissionEntry{node: node})
+	if err := p.persist(&list); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// remove revokes the permission of a node and persists the list, reporting
+// whether the node was permitted before.
+func (p *nodePermissions) remove(id enode.ID) (bool, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if _, ok := p.index[id]; !ok {
+		return false, nil
+	}
+	list := *p.list
+	list.entries = make([]*permissionEntry, 0, len(p.list.entries)-1)
+	for _, entry := range p.list.entries {
+		if entry.node.ID() != id {
+			list.entries = append(list.entries, entry)
+		}
+	}
+	if err := p.persist(&list); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// reload reads the permissioned nodes file if it changed since it was last read
+// or written, reporting whether the list was replaced. The list is kept if the
+// file can't be parsed, e.g. while it is being edited.
+func (p *nodePermissions) reload() (bool, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	stat, err := os.Stat(p.path)
+	if err != nil {
+		if os.IsNotExist(err) && (len(p.list.entries) > 0 || len(p.list.deny) > 0) {
+			p.set(new(permissionList), time.Time{})
+			return true, err
+		}
+		return false, err
+	}
+	if stat.ModTime().Equal(p.modTime) {
+		return false, nil
+	}
+	list, err := parsePermissionedNodes(p.path)
+	if err != nil {
+		return false, err
+	}
+	p.set(list, stat.ModTime())
+	return true, nil
+}
+
+// persist atomically replaces the permissioned nodes file with the given list
+// and updates the permissions. The caller must hold the lock.
+func (p *nodePermissions) persist(list *permissionList) error {
+	blob, err := json.MarshalIndent(list, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := ioutil.TempFile(filepath.Dir(p.path), "."+PERMISSIONED_CONFIG+".tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(append(blob, '\n')); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	tmp.Close()
+	mode := os.FileMode(0644)
+	if stat, err := os.Stat(p.path); err == nil {
+		mode = stat.Mode()
+	}
+	if err := os.Chmod(tmp.Name(), mode); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Rename(tmp.Name(), p.path); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	var modTime time.Time
+	if stat, err := os.Stat(p.path); err == nil {
+		modTime = stat.ModTime()
+	}
+	p.set(list, modTime)
+	return nil
+}
+
+// set replaces the list. The caller must hold the lock.
+func (p *nodePermissions) set(list *permissionList, modTime time.Time) {
+	index := make(map[enode.ID]*permissionEntry, len(list.entries))
+	for _, entry := range list.entries {
+		index[entry.node.ID()] = entry
+	}
+	p.list, p.index, p.modTime = list, index, modTime
+}
+
+// parsePermissionedNodes reads a permissioned nodes file, skipping invalid
+// entries.
+func parsePermissionedNodes(path string) (*permissionList, error) {
+	log.Debug("parsePermissionedNodes", "file", path)
+
 	// Load the nodes from the config file
 	blob, err := ioutil.ReadFile(path)
 	if err != nil {
-		log.Error("parsePermissionedNodes: Failed to access nodes", "err", err)
-		return nil
+		return nil, err
 	}
-
-	nodelist := []string{}
-	if err := json.Unmarshal(blob, &nodelist); err != nil {
+	list := new(permissionList)
+	if err := json.Unmarshal(blob, list); err != nil {
 		log.Error("parsePermissionedNodes: Failed to load nodes", "err", err)
-		return nil
+		return nil, err
 	}
-	// Interpret the list as a discovery node array
-	var nodes []*enode.Node
-	for _, url := range nodelist {
-		if url == "" {
-			log.Error("parsePermissionedNodes: Node URL blank")
-			continue
+	return list, nil
+}
+
+// parseIPList parses a list of networks in CIDR notation or single IPs, or
+// returns nil for an empty list.
+func parseIPList(list []string) (*netutil.Netlist, error) {
+	if len(list) == 0 {
+		return nil, nil
+	}
+	masks := make([]string, len(list))
+	for i, mask := range list {
+		if !strings.Contains(mask, "/") {
+			ip := net.ParseIP(mask)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid IP %q", mask)
+			}
+			if ip.To4() != nil {
+				mask += "/32"
+			} else {
+				mask += "/128"
+			}
 		}
-		node, err := enode.ParseV4(url)
-		if err != nil {
-			log.Error("parsePermissionedNodes: Node URL", "url", url, "err", err)
-			continue
+		masks[i] = mask
+	}
+	return netutil.ParseNetlist(strings.Join(masks, ","))
+}
+
+// addrEndpoint returns the IP and port of a network address, or nil if it
+// isn't a TCP address.
+func addrEndpoint(addr net.Addr) (net.IP, int) {
+	if tcp, ok := addr.(*net.TCPAddr); ok {
+		return tcp.IP, tcp.Port
+	}
+	return nil, 0
+}
+
+// permissionsLoop reloads the permissioned nodes whenever their file changes,
+// disconnecting the peers no longer permitted.
+func (srv *Server) permissionsLoop() {
+	defer srv.loopWG.Done()
+
+	ticker := time.NewTicker(permissionsReloadInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			changed, err := srv.permissions.reload()
+			if err != nil && !os.IsNotExist(err) {
+				log.Warn("Failed to reload permissioned nodes", "err", err)
+			}
+			if changed {
+				log.Info("Reloaded permissioned nodes", "count", len(srv.permissions.nodes()))
+				srv.dropUnpermissioned()
+			}
+		case <-srv.quit:
+			return
 		}
-		nodes = append(nodes, node)
 	}
-	return nodes
+}
+
+// dropUnpermissioned disconnects all peers that aren't permissioned any more.
+func (srv *Server) dropUnpermissioned() {
+	for _, p := range srv.Peers() {
+		if !srv.permissions.isPermissioned(p.ID(), p.RemoteAddr(), !p.Inbound()) {
+			srv.audit.record(p.Node(), p.RemoteAddr(), !p.Inbound(), false)
+			log.Info("Disconnecting peer no longer permissioned", "id", p.ID(), "name", p.Name())
+			p.Disconnect(DiscRequested)
+		}
+	}
+}
+
+// PermissionedNodes returns the nodes permitted to connect.
+func (srv *Server) PermissionedNodes() ([]*enode.Node, error) {
+	if srv.permissions == nil {
+		return nil, errPermissioningDisabled
+	}
+	return srv.permissions.nodes(), nil
+}
+
+// AddPermissionedNode permits a node to connect, persisting the change to the
+// permissioned nodes file. It reports whether the node wasn't permitted yet.
+func (srv *Server) AddPermissionedNode(node *enode.Node) (bool, error) {
+	if srv.permissions == nil {
+		return false, errPermissioningDisabled
+	}
+	return srv.permissions.add(node)
+}
+
+// RemovePermissionedNode revokes the permission of a node, persisting the
+// change to the permissioned nodes file and disconnecting the node if it is
+// connected. It reports whether the node was permitted before.
+func (srv *Server) RemovePermissionedNode(node *enode.Node) (bool, error) {
+	if srv.permissions == nil {
+		return false, errPermissioningDisabled
+	}
+	removed, err := srv.permissions.remove(node.ID())
+	if removed {
+		srv.dropUnpermissioned()
+	}
+	return removed, err
 }
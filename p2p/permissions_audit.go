@@ -0,0 +1,141 @@
+package p2p
+
+import (
+	"net"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/ethereum/go-ethereum/p2p/enode"
+)
+
+const (
+	PermissionAllowed = "ALLOWED"
+	PermissionDenied  = "DENIED"
+
+	permissionAuditHistory   = 1024            // Number of recent decisions kept in memory
+	permissionAuditFileLimit = 4 * 1024 * 1024 // Size of the audit log files before rotating them
+)
+
+var (
+	permissionAllowedCounter = metrics.NewRegisteredCounter("p2p/permissions/allowed", nil)
+	permissionDeniedCounter  = metrics.NewRegisteredCounter("p2p/permissions/denied", nil)
+
+	// PermissionDeniedRegistry contains the number of rejected connections per
+	// remote node, for at most MeteredPeerLimit nodes.
+	PermissionDeniedRegistry = metrics.NewPrefixedChildRegistry(metrics.DefaultRegistry, "p2p/permissions/denied/")
+)
+
+// PermissionDecision is the outcome of checking whether a connection is
+// permitted by node permissioning.
+type PermissionDecision struct {
+	Time      time.Time `json:"time"`
+	Enode     string    `json:"enode"`
+	RemoteIP  string    `json:"remoteIP"`
+	Direction string    `json:"direction"` // "inbound" or "outbound"
+	Decision  string    `json:"decision"`  // PermissionAllowed or PermissionDenied
+}
+
+// permissionAudit records permission decisions in memory, in the metrics
+// registry and, if configured, in rotating JSON log files.
+type permissionAudit struct {
+	logger log.Logger // Audit log writer (nil = no audit log files)
+
+	recent  []PermissionDecision // Ring buffer of the recent decisions
+	next    int                  // Index of the next decision in the ring buffer
+	metered map[enode.ID]metrics.Counter
+	lock    sync.Mutex
+}
+
+// newPermissionAudit creates an audit trail writing its log files into the given
+// directory, or keeping it in memory only if dir is empty.
+func newPermissionAudit(dir string) (*permissionAudit, error) {
+	audit := &permissionAudit{
+		recent:  make([]PermissionDecision, 0, permissionAuditHistory),
+		metered: make(map[enode.ID]metrics.Counter),
+	}
+	if dir != "" {
+		handler, err := log.RotatingFileHandler(dir, permissionAuditFileLimit, log.JSONFormatOrderedEx(false, true))
+		if err != nil {
+			return nil, err
+		}
+		audit.logger = log.New()
+		audit.logger.SetHandler(handler)
+	}
+	return audit, nil
+}
+
+// record adds the decision on a connection of the given node to the trail.
+func (a *permissionAudit) record(node *enode.Node, remote net.Addr, dialed, allowed bool) {
+	decision := PermissionDecision{
+		Time:      time.Now(),
+		Enode:     node.String(),
+		Direction: "inbound",
+		Decision:  PermissionDenied,
+	}
+	if ip, _ := addrEndpoint(remote); ip != nil {
+		decision.RemoteIP = ip.String()
+	}
+	if dialed {
+		decision.Direction = "outbound"
+	}
+	if allowed {
+		decision.Decision = PermissionAllowed
+	}
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	if len(a.recent) < permissionAuditHistory {
+		a.recent = append(a.recent, decision)
+	} else {
+		a.recent[a.next] = decision
+	}
+	a.next = (a.next + 1) % permissionAuditHistory
+
+	if allowed {
+		permissionAllowedCounter.Inc(1)
+	} else {
+		permissionDeniedCounter.Inc(1)
+		if counter := a.deniedCounter(node.ID()); counter != nil {
+			counter.Inc(1)
+		}
+	}
+	if a.logger != nil {
+		a.logger.Info("Permission decision", "enode", decision.Enode, "remoteIP", decision.RemoteIP, "direction", decision.Direction, "decision", decision.Decision)
+	}
+}
+
+// deniedCounter returns the rejection counter of a node, or nil if too many
+// nodes are metered already. The caller must hold the lock.
+func (a *permissionAudit) deniedCounter(id enode.ID) metrics.Counter {
+	if !metrics.Enabled {
+		return nil
+	}
+	counter, ok := a.metered[id]
+	if !ok && len(a.metered) < MeteredPeerLimit {
+		counter = metrics.NewRegisteredCounter(id.String(), PermissionDeniedRegistry)
+		a.metered[id] = counter
+	}
+	return counter
+}
+
+// decisions returns the recent decisions, oldest first.
+func (a *permissionAudit) decisions() []PermissionDecision {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	if len(a.recent) < permissionAuditHistory {
+		return append([]PermissionDecision{}, a.recent...)
+	}
+	return append(append([]PermissionDecision{}, a.recent[a.next:]...), a.recent[:a.next]...)
+}
+
+// PermissionAudit returns the recent decisions of node permissioning on
+// connections, oldest first.
+func (srv *Server) PermissionAudit() ([]PermissionDecision, error) {
+	if srv.audit == nil {
+		return nil, errPermissioningDisabled
+	}
+	return srv.audit.decisions(), nil
+}
@@ -0,0 +1,179 @@
+package p2p
+
+import (
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/p2p/enode"
+)
+
+func TestNodePermissions(t *testing.T) {
+	datadir, err := ioutil.TempDir("", "permissions")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(datadir)
+
+	var (
+		node1    = enode.NewV4(&newkey().PublicKey, net.IP{127, 0, 0, 1}, 30301, 30301, 0)
+		node2    = enode.NewV4(&newkey().PublicKey, net.IP{127, 0, 0, 2}, 30302, 30302, 0)
+		anywhere = &net.TCPAddr{IP: net.IP{10, 0, 0, 1}, Port: 30303}
+	)
+	perms := newNodePermissions(datadir)
+	if perms.isPermissioned(node1.ID(), anywhere, false) {
+		t.Fatalf("node permitted without permissioned nodes file")
+	}
+	// Added and removed nodes are persisted and visible to a fresh list
+	if added, err := perms.add(node1); !added || err != nil {
+		t.Fatalf("failed to add node: %v", err)
+	}
+	if added, err := perms.add(node1); added || err != nil {
+		t.Fatalf("duplicate add mismatch: added %v, err %v", added, err)
+	}
+	if added, err := perms.add(node2); !added || err != nil {
+		t.Fatalf("failed to add node: %v", err)
+	}
+	if removed, err := perms.remove(node1.ID()); !removed || err != nil {
+		t.Fatalf("failed to remove node: %v", err)
+	}
+	reloaded := newNodePermissions(datadir)
+	if reloaded.isPermissioned(node1.ID(), anywhere, false) || !reloaded.isPermissioned(node2.ID(), anywhere, false) {
+		t.Fatalf("persisted permissions mismatch: have %v", reloaded.nodes())
+	}
+	// External changes of the file are picked up, broken files are ignored
+	path := filepath.Join(datadir, PERMISSIONED_CONFIG)
+	later := time.Now().Add(time.Minute)
+
+	if err := ioutil.WriteFile(path, []byte(`["`+node1.String()+`"]`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	os.Chtimes(path, later, later)
+	if changed, err := perms.reload(); !changed || err != nil {
+		t.Fatalf("failed to reload changed file: %v", err)
+	}
+	if !perms.isPermissioned(node1.ID(), anywhere, false) || perms.isPermissioned(node2.ID(), anywhere, false) {
+		t.Fatalf("reloaded permissions mismatch: have %v", perms.nodes())
+	}
+	if err := ioutil.WriteFile(path, []byte(`["`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	os.Chtimes(path, later.Add(time.Minute), later.Add(time.Minute))
+	if changed, err := perms.reload(); changed || err == nil {
+		t.Fatalf("broken file reload mismatch: changed %v, err %v", changed, err)
+	}
+	if !perms.isPermissioned(node1.ID(), anywhere, false) {
+		t.Fatalf("permissions lost on broken file")
+	}
+}
+
+func TestNodePermissionsAddresses(t *testing.T) {
+	datadir, err := ioutil.TempDir("", "permissions")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(datadir)
+
+	var (
+		node1 = enode.NewV4(&newkey().PublicKey, net.IP{10, 0, 1, 5}, 30301, 30301, 0)
+		node2 = enode.NewV4(&newkey().PublicKey, net.IP{10, 0, 2, 5}, 30302, 30302, 0)
+		node3 = enode.NewV4(&newkey().PublicKey, net.IP{10, 0, 3, 5}, 30303, 30303, 0)
+	)
+	file := `{
+		"nodes": [
+			{"enode": "` + node1.String() + `", "allowedIPs": ["10.0.1.0/24", "192.168.0.7"]},
+			{"enode": "` + node2.String() + `", "endpoint": true},
+			"` + node3.String() + `",
+			{"enode": "` + node3.String() + `", "allowedIPs": ["not-an-ip"]}
+		],
+		"deny": ["10.0.3.0/28"]
+	}`
+	path := filepath.Join(datadir, PERMISSIONED_CONFIG)
+	if err := ioutil.WriteFile(path, []byte(file), 0644); err != nil {
+		t.Fatal(err)
+	}
+	perms := newNodePermissions(datadir)
+
+	tests := []struct {
+		node    *enode.Node
+		ip      net.IP
+		port    int
+		dialed  bool
+		permits bool
+	}{
+		{node1, net.IP{10, 0, 1, 200}, 40000, false, true},
+		{node1, net.IP{192, 168, 0, 7}, 40000, true, true},
+		{node1, net.IP{10, 0, 2, 200}, 30301, false, false},
+		{node2, net.IP{10, 0, 2, 5}, 40000, false, true},
+		{node2, net.IP{10, 0, 2, 5}, 30302, true, true},
+		{node2, net.IP{10, 0, 2, 5}, 40000, true, false},
+		{node2, net.IP{10, 0, 2, 6}, 30302, false, false},
+		{node3, net.IP{10, 0, 9, 1}, 30303, false, true},
+		{node3, net.IP{10, 0, 3, 5}, 30303, true, false},
+	}
+	for i, test := range tests {
+		remote := &net.TCPAddr{IP: test.ip, Port: test.port}
+		if permits := perms.isPermissioned(test.node.ID(), remote, test.dialed); permits != test.permits {
+			t.Errorf("test %d: permission mismatch for %v: have %v, want %v", i, remote, permits, test.permits)
+		}
+	}
+	// Restrictions and denied networks survive changes through the API
+	if added, err := perms.add(enode.NewV4(&newkey().PublicKey, net.IP{10, 0, 4, 5}, 30304, 30304, 0)); !added || err != nil {
+		t.Fatalf("failed to add node: %v", err)
+	}
+	reloaded := newNodePermissions(datadir)
+	if len(reloaded.nodes()) != 4 {
+		t.Fatalf("persisted node count mismatch: have %d, want 4", len(reloaded.nodes()))
+	}
+	if reloaded.isPermissioned(node1.ID(), &net.TCPAddr{IP: net.IP{10, 0, 2, 200}}, false) {
+		t.Fatalf("persisted allowed IPs lost")
+	}
+	if reloaded.isPermissioned(node3.ID(), &net.TCPAddr{IP: net.IP{10, 0, 3, 5}}, false) {
+		t.Fatalf("persisted denied networks lost")
+	}
+	// Invalid denied networks reject the whole file
+	if err := ioutil.WriteFile(path, []byte(`{"nodes": [], "deny": ["10.0.0.0/33"]}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := parsePermissionedNodes(path); err == nil {
+		t.Fatalf("invalid denied network accepted")
+	}
+}
+
+func TestPermissionAudit(t *testing.T) {
+	dir, err := ioutil.TempDir("", "permission-audit")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	audit, err := newPermissionAudit(dir)
+	if err != nil {
+		t.Fatalf("failed to create audit trail: %v", err)
+	}
+	var (
+		node   = enode.NewV4(&newkey().PublicKey, net.IP{10, 0, 0, 1}, 30301, 30301, 0)
+		remote = &net.TCPAddr{IP: net.IP{10, 0, 0, 1}, Port: 40000}
+	)
+	for i := 0; i < permissionAuditHistory+2; i++ {
+		audit.record(node, remote, i%2 == 0, i == permissionAuditHistory+1)
+	}
+	decisions := audit.decisions()
+	if len(decisions) != permissionAuditHistory {
+		t.Fatalf("decision count mismatch: have %d, want %d", len(decisions), permissionAuditHistory)
+	}
+	last := decisions[len(decisions)-1]
+	if last.Decision != PermissionAllowed || last.Direction != "inbound" || last.RemoteIP != "10.0.0.1" || last.Enode != node.String() {
+		t.Fatalf("last decision mismatch: %+v", last)
+	}
+	if first := decisions[0]; first.Decision != PermissionDenied || first.Direction != "outbound" {
+		t.Fatalf("oldest decision mismatch: %+v", first)
+	}
+	files, err := ioutil.ReadDir(dir)
+	if err != nil || len(files) != 1 || files[0].Size() == 0 {
+		t.Fatalf("audit log not written: files %v, err %v", files, err)
+	}
+}
@@ -149,6 +149,10 @@ type Config struct {
 
 	EnableNodePermission bool `toml:",omitempty"`
 
+	// PermissionAuditDir is the directory the decisions of node permissioning are
+	// logged into as rotating JSON files. If empty, they are only kept in memory.
+	PermissionAuditDir string `toml:",omitempty"`
+
 	DataDir string `toml:",omitempty"`
 	// Logger is a custom logger to use with the p2p.Server.
 	Logger log.Logger `toml:",omitempty"`
@@ -187,7 +191,9 @@ type Server struct {
 	posthandshake chan *conn
 	addpeer       chan *conn
 	delpeer       chan peerDrop
-	loopWG        sync.WaitGroup // loop, listenLoop
+	loopWG        sync.WaitGroup   // loop, listenLoop, permissionsLoop
+	permissions   *nodePermissions // Quorum: nodes permitted to connect, if permissioning is enabled
+	audit         *permissionAudit // Quorum: trail of permission decisions, if permissioning is enabled
 	peerFeed      event.Feed
 	log           log.Logger
 }
@@ -447,6 +453,13 @@ func (srv *Server) Start() (err error) {
 	srv.peerOp = make(chan peerOpFunc)
 	srv.peerOpDone = make(chan struct{})
 
+	if srv.EnableNodePermission {
+		audit, err := newPermissionAudit(srv.PermissionAuditDir)
+		if err != nil {
+			return err
+		}
+		srv.permissions, srv.audit = newNodePermissions(srv.DataDir), audit
+	}
 	if err := srv.setupLocalNode(); err != nil {
 		return err
 	}
@@ -463,6 +476,11 @@ func (srv *Server) Start() (err error) {
 	dialer := newDialState(srv.localnode.ID(), srv.StaticNodes, srv.BootstrapNodes, srv.ntab, dynPeers, srv.NetRestrict)
 	srv.loopWG.Add(1)
 	go srv.run(dialer)
+
+	if srv.permissions != nil {
+		srv.loopWG.Add(1)
+		go srv.permissionsLoop()
+	}
 	return nil
 }
 
@@ -939,19 +957,21 @@ func (srv *Server) setupConn(c *conn, flags connFlag, dialDest *enode.Node) erro
 		"Connection ID", c.node.ID(),
 		"Connection String", c.node.ID().String())
 
-	if srv.EnableNodePermission {
+	if srv.permissions != nil {
 		clog.Trace("Node Permissioning is Enabled.")
 		node := c.node.ID().String()
 		direction := "INCOMING"
 		if dialDest != nil {
-			node = dialDest.ID().String()
 			direction = "OUTGOING"
 			log.Trace("Node Permissioning", "Connection Direction", direction)
 		}
-
-		if !isNodePermissioned(node, currentNode, srv.DataDir, direction) {
-			return nil
+		permitted := srv.permissions.isPermissioned(c.node.ID(), c.fd.RemoteAddr(), dialDest != nil)
+		srv.audit.record(c.node, c.fd.RemoteAddr(), dialDest != nil, permitted)
+		if !permitted {
+			log.Debug("isNodePermissioned", "connection", direction, "nodename", node[:NODE_NAME_LENGTH], "DENIED-BY", currentNode[:NODE_NAME_LENGTH])
+			return errNodeNotPermissioned
 		}
+		log.Debug("isNodePermissioned", "connection", direction, "nodename", node[:NODE_NAME_LENGTH], "ALLOWED-BY", currentNode[:NODE_NAME_LENGTH])
 	} else {
 		clog.Trace("Node Permissioning is Disabled.")
 	}
@@ -132,19 +132,19 @@ var (
 	//
 	// This configuration is intentionally not using keyed fields to force anyone
 	// adding flags to the config to also have to set these fields.
-	AllEthashProtocolChanges = &ChainConfig{big.NewInt(1337), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, new(EthashConfig), nil, nil, false, 32}
+	AllEthashProtocolChanges = &ChainConfig{big.NewInt(1337), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, new(EthashConfig), nil, nil, false, 32, nil, nil, false, nil}
 
 	// AllCliqueProtocolChanges contains every protocol change (EIPs) introduced
 	// and accepted by the Ethereum core developers into the Clique consensus.
 	//
 	// This configuration is intentionally not using keyed fields to force anyone
 	// adding flags to the config to also have to set these fields.
-	AllCliqueProtocolChanges = &ChainConfig{big.NewInt(1337), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, nil, &CliqueConfig{Period: 0, Epoch: 30000}, nil, false, 32}
+	AllCliqueProtocolChanges = &ChainConfig{big.NewInt(1337), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, nil, &CliqueConfig{Period: 0, Epoch: 30000}, nil, false, 32, nil, nil, false, nil}
 
-	TestChainConfig = &ChainConfig{big.NewInt(10), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, new(EthashConfig), nil, nil, false, 32}
+	TestChainConfig = &ChainConfig{big.NewInt(10), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, new(EthashConfig), nil, nil, false, 32, nil, nil, false, nil}
 	TestRules       = TestChainConfig.Rules(new(big.Int))
 
-	QuorumTestChainConfig = &ChainConfig{big.NewInt(10), big.NewInt(0), nil, false, nil, common.Hash{}, nil, nil, nil, nil, nil, new(EthashConfig), nil, nil, true, 64}
+	QuorumTestChainConfig = &ChainConfig{big.NewInt(10), big.NewInt(0), nil, false, nil, common.Hash{}, nil, nil, nil, nil, nil, new(EthashConfig), nil, nil, true, 64, nil, nil, false, nil}
 )
 
 // TrustedCheckpoint represents a set of post-processed trie roots (CHT and
@@ -190,6 +190,12 @@ type ChainConfig struct {
 
 	IsQuorum             bool   `json:"isQuorum"`
 	TransactionSizeLimit uint64 `json:"txnSizeLimit"`
+
+	// Gas-priced mode of Quorum networks, which otherwise require a zero gas price
+	GasPriceBlock          *big.Int        `json:"gasPriceBlock,omitempty"`          // Block from which non-zero gas prices are allowed (nil = never)
+	GasFeeBeneficiary      *common.Address `json:"gasFeeBeneficiary,omitempty"`      // Account receiving the fees (nil = block author, i.e. the validator)
+	GasFeeBurn             bool            `json:"gasFeeBurn,omitempty"`             // Whether fees are burnt instead of paid to anyone
+	GasFeeBeneficiaryBlock *big.Int        `json:"gasFeeBeneficiaryBlock,omitempty"` // Block from which the fees are paid to the beneficiary or burnt (nil = GasPriceBlock)
 }
 
 // EthashConfig is the consensus engine configs for proof-of-work based sealing.
@@ -213,8 +219,9 @@ func (c *CliqueConfig) String() string {
 
 // IstanbulConfig is the consensus engine configs for Istanbul based sealing.
 type IstanbulConfig struct {
-	Epoch          uint64 `json:"epoch"`  // Epoch length to reset votes and checkpoint
-	ProposerPolicy uint64 `json:"policy"` // The policy for proposer selection
+	Epoch            uint64 `json:"epoch"`                      // Epoch length to reset votes and checkpoint
+	ProposerPolicy   uint64 `json:"policy"`                     // The policy for proposer selection
+	EmptyBlockPeriod uint64 `json:"emptyblockperiod,omitempty"` // Maximum seconds between blocks while no transactions are pending (0 = disabled)
 }
 
 // String implements the stringer interface, returning the consensus engine details.
@@ -254,6 +261,12 @@ func (c *ChainConfig) IsValid() error {
 	if c.TransactionSizeLimit < 32 || c.TransactionSizeLimit > 128 {
 		return errors.New("Genesis transaction size limit must be between 32 and 128")
 	}
+	if c.GasFeeBurn && c.GasFeeBeneficiary != nil {
+		return errors.New("Genesis gas fees can either be burnt or paid to a beneficiary")
+	}
+	if c.GasFeeBeneficiaryBlock != nil && (c.GasPriceBlock == nil || c.GasFeeBeneficiaryBlock.Cmp(c.GasPriceBlock) < 0) {
+		return errors.New("Genesis gas fee beneficiary block must not precede the gas price block")
+	}
 
 	return nil
 }
@@ -298,6 +311,37 @@ func (c *ChainConfig) IsEWASM(num *big.Int) bool {
 	return isForked(c.EWASMBlock, num)
 }
 
+// IsGasPriceEnabled returns whether num is either equal to the gas price fork
+// block or greater, from which Quorum chains accept non-zero gas prices.
+func (c *ChainConfig) IsGasPriceEnabled(num *big.Int) bool {
+	return isForked(c.GasPriceBlock, num)
+}
+
+// gasFeeBeneficiaryBlock returns the block from which the gas fees are paid as
+// configured by GasFeeBeneficiary and GasFeeBurn.
+func (c *ChainConfig) gasFeeBeneficiaryBlock() *big.Int {
+	if c.GasFeeBeneficiaryBlock != nil {
+		return c.GasFeeBeneficiaryBlock
+	}
+	return c.GasPriceBlock
+}
+
+// GasFeeRecipient returns the account the gas fees of a transaction in block
+// num are paid to, given the block author, and false if the fees are burnt.
+func (c *ChainConfig) GasFeeRecipient(num *big.Int, author common.Address) (common.Address, bool) {
+	if !c.IsQuorum || !c.IsGasPriceEnabled(num) || !isForked(c.gasFeeBeneficiaryBlock(), num) {
+		return author, true
+	}
+	switch {
+	case c.GasFeeBurn:
+		return common.Address{}, false
+	case c.GasFeeBeneficiary != nil:
+		return *c.GasFeeBeneficiary, true
+	default:
+		return author, true
+	}
+}
+
 // GasTable returns the gas table corresponding to the current phase (homestead or homestead reprice).
 //
 // The returned GasTable's fields shouldn't, under any circumstances, be changed.
@@ -369,6 +413,15 @@ func (c *ChainConfig) checkCompatible(newcfg *ChainConfig, head *big.Int, isQuor
 	if isForkIncompatible(c.EWASMBlock, newcfg.EWASMBlock, head) {
 		return newCompatError("ewasm fork block", c.EWASMBlock, newcfg.EWASMBlock)
 	}
+	if isForkIncompatible(c.GasPriceBlock, newcfg.GasPriceBlock, head) {
+		return newCompatError("gas price fork block", c.GasPriceBlock, newcfg.GasPriceBlock)
+	}
+	if isForkIncompatible(c.gasFeeBeneficiaryBlock(), newcfg.gasFeeBeneficiaryBlock(), head) {
+		return newCompatError("gas fee beneficiary fork block", c.gasFeeBeneficiaryBlock(), newcfg.gasFeeBeneficiaryBlock())
+	}
+	if isForked(c.gasFeeBeneficiaryBlock(), head) && (c.GasFeeBurn != newcfg.GasFeeBurn || !configAddressEqual(c.GasFeeBeneficiary, newcfg.GasFeeBeneficiary)) {
+		return newCompatError("gas fee beneficiary", c.gasFeeBeneficiaryBlock(), newcfg.gasFeeBeneficiaryBlock())
+	}
 	return nil
 }
 
@@ -396,6 +449,13 @@ func configNumEqual(x, y *big.Int) bool {
 	return x.Cmp(y) == 0
 }
 
+func configAddressEqual(x, y *common.Address) bool {
+	if x == nil || y == nil {
+		return x == y
+	}
+	return *x == *y
+}
+
 // ConfigCompatError is raised if the locally-stored blockchain is initialised with a
 // ChainConfig that would alter the past.
 type ConfigCompatError struct {
@@ -70,6 +70,45 @@ func TestCheckCompatible(t *testing.T) {
 				RewindTo:     9,
 			},
 		},
+		{
+			stored: &ChainConfig{GasPriceBlock: big.NewInt(10)},
+			new:    &ChainConfig{GasPriceBlock: big.NewInt(10), GasFeeBurn: true},
+			head:   15,
+			wantErr: &ConfigCompatError{
+				What:         "gas fee beneficiary",
+				StoredConfig: big.NewInt(10),
+				NewConfig:    big.NewInt(10),
+				RewindTo:     9,
+			},
+		},
+		{
+			stored: &ChainConfig{GasPriceBlock: big.NewInt(10), GasFeeBeneficiaryBlock: big.NewInt(12)},
+			new:    &ChainConfig{GasPriceBlock: big.NewInt(10), GasFeeBeneficiaryBlock: big.NewInt(12), GasFeeBurn: true},
+			head:   15,
+			wantErr: &ConfigCompatError{
+				What:         "gas fee beneficiary",
+				StoredConfig: big.NewInt(12),
+				NewConfig:    big.NewInt(12),
+				RewindTo:     11,
+			},
+		},
+		{
+			stored: &ChainConfig{GasPriceBlock: big.NewInt(10)},
+			new:    &ChainConfig{GasPriceBlock: big.NewInt(10), GasFeeBeneficiaryBlock: big.NewInt(20), GasFeeBurn: true},
+			head:   15,
+			wantErr: &ConfigCompatError{
+				What:         "gas fee beneficiary fork block",
+				StoredConfig: big.NewInt(10),
+				NewConfig:    big.NewInt(20),
+				RewindTo:     9,
+			},
+		},
+		{
+			stored:  &ChainConfig{GasPriceBlock: big.NewInt(10)},
+			new:     &ChainConfig{GasPriceBlock: big.NewInt(10), GasFeeBeneficiaryBlock: big.NewInt(20), GasFeeBurn: true},
+			head:    9,
+			wantErr: nil,
+		},
 	}
 
 	for _, test := range tests {
@@ -51,20 +51,33 @@ func (g *Constellation) Receive(data []byte) ([]byte, error) {
 	if len(data) == 0 {
 		return data, nil
 	}
-	// Ignore this error since not being a recipient of
-	// a payload isn't an error.
-	// TODO: Return an error if it's anything OTHER than
-	// 'you are not a recipient.'
 	dataStr := string(data)
 	x, found := g.c.Get(dataStr)
 	if found {
 		return x.([]byte), nil
 	}
-	pl, _ := g.node.ReceivePayload(data)
+	// Ignore errors answered by the node, since not being a recipient of a
+	// payload isn't an error. Failing to reach the node is, and isn't cached
+	// so the payload is asked for again.
+	pl, err := g.node.ReceivePayload(data)
+	if err != nil {
+		if _, ok := err.(*statusError); !ok {
+			return nil, err
+		}
+		pl = nil
+	}
 	g.c.Set(dataStr, pl, cache.DefaultExpiration)
 	return pl, nil
 }
 
+// Upcheck returns an error if the Constellation node is unreachable.
+func (g *Constellation) Upcheck() error {
+	if g.isConstellationNotInUse {
+		return nil
+	}
+	return g.node.Upcheck()
+}
+
 func New(path string) (*Constellation, error) {
 	info, err := os.Lstat(path)
 	if err != nil {
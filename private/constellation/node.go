@@ -47,21 +47,26 @@ func unixClient(socketPath string) *http.Client {
 }
 
 func RunNode(socketPath string) error {
-	c := unixClient(socketPath)
-	res, err := c.Get("http+unix://c/upcheck")
+	return (&Client{httpClient: unixClient(socketPath)}).Upcheck()
+}
+
+type Client struct {
+	httpClient *http.Client
+}
+
+// Upcheck returns an error if the node doesn't respond to upcheck requests.
+func (c *Client) Upcheck() error {
+	res, err := c.httpClient.Get("http+unix://c/upcheck")
 	if err != nil {
 		return err
 	}
+	res.Body.Close()
 	if res.StatusCode == 200 {
 		return nil
 	}
 	return errors.New("Constellation Node API did not respond to upcheck request")
 }
 
-type Client struct {
-	httpClient *http.Client
-}
-
 func (c *Client) doJson(path string, apiReq interface{}) (*http.Response, error) {
 	buf := new(bytes.Buffer)
 	err := json.NewEncoder(buf).Encode(apiReq)
@@ -145,12 +150,22 @@ func (c *Client) ReceivePayload(key []byte) ([]byte, error) {
 		return nil, err
 	}
 	if res.StatusCode != 200 {
-		return nil, fmt.Errorf("Non-200 status code: %+v", res)
+		return nil, &statusError{res}
 	}
 
 	return ioutil.ReadAll(res.Body)
 }
 
+// statusError is returned for requests the node answered with a non-200 status
+// code, as opposed to requests failing to reach it.
+type statusError struct {
+	res *http.Response
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("Non-200 status code: %+v", e.res)
+}
+
 func NewClient(socketPath string) (*Client, error) {
 	return &Client{
 		httpClient: unixClient(socketPath),
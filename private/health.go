@@ -0,0 +1,173 @@
+package private
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+// ErrTransactionManagerDown is returned for requests refused while the private
+// transaction manager is unreachable.
+var ErrTransactionManagerDown = errors.New("private transaction manager is down")
+
+var (
+	upGauge      = metrics.NewRegisteredGauge("ptm/up", nil)
+	failureMeter = metrics.NewRegisteredMeter("ptm/upcheck/failures", nil)
+	upcheckTimer = metrics.NewRegisteredTimer("ptm/upcheck/duration", nil)
+)
+
+// Upchecker is implemented by transaction managers able to report whether they
+// are reachable.
+type Upchecker interface {
+	Upcheck() error
+}
+
+// TransactionManagerStatus is the health of the private transaction manager as
+// of the last check.
+type TransactionManagerStatus struct {
+	Configured bool      `json:"configured"`
+	Up         bool      `json:"up"`
+	LastCheck  time.Time `json:"lastCheck"`
+	LastUp     time.Time `json:"lastUp"`
+	Failures   uint64    `json:"consecutiveFailures"`
+	Error      string    `json:"error,omitempty"`
+}
+
+// HealthMonitor periodically checks whether the private transaction manager is
+// reachable, so the node can hold back work depending on it while it is down.
+type HealthMonitor struct {
+	ptm      PrivateTransactionManager
+	interval time.Duration
+
+	status TransactionManagerStatus
+	upCh   chan struct{} // Closed while the transaction manager is up
+	lock   sync.RWMutex
+
+	quit chan struct{}
+	wg   sync.WaitGroup
+}
+
+// NewHealthMonitor creates a monitor checking the given transaction manager,
+// which may be nil, at the given interval. The transaction manager is assumed
+// to be up until checked.
+func NewHealthMonitor(ptm PrivateTransactionManager, interval time.Duration) *HealthMonitor {
+	upCh := make(chan struct{})
+	close(upCh)
+
+	return &HealthMonitor{
+		ptm:      ptm,
+		interval: interval,
+		status:   TransactionManagerStatus{Configured: ptm != nil, Up: true, LastUp: time.Now()},
+		upCh:     upCh,
+		quit:     make(chan struct{}),
+	}
+}
+
+// Start checks the transaction manager and keeps checking it in the background.
+// Transaction managers unable to report their health are never checked.
+func (m *HealthMonitor) Start() {
+	upchecker, ok := m.ptm.(Upchecker)
+	if !ok || m.interval <= 0 {
+		return
+	}
+	m.check(upchecker)
+
+	m.wg.Add(1)
+	go m.loop(upchecker)
+}
+
+// Stop terminates the background checks.
+func (m *HealthMonitor) Stop() {
+	close(m.quit)
+	m.wg.Wait()
+}
+
+// Status returns the health of the transaction manager as of the last check.
+func (m *HealthMonitor) Status() TransactionManagerStatus {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	return m.status
+}
+
+// Up reports whether the transaction manager was reachable at the last check.
+func (m *HealthMonitor) Up() bool {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	return m.status.Up
+}
+
+// Available returns ErrTransactionManagerDown if the transaction manager was
+// unreachable at the last check.
+func (m *HealthMonitor) Available() error {
+	if !m.Up() {
+		return ErrTransactionManagerDown
+	}
+	return nil
+}
+
+// WaitUp blocks until the transaction manager is up, returning false if the
+// abort channel is closed first.
+func (m *HealthMonitor) WaitUp(abort <-chan struct{}) bool {
+	m.lock.RLock()
+	upCh := m.upCh
+	m.lock.RUnlock()
+
+	select {
+	case <-upCh:
+		return true
+	case <-abort:
+		return false
+	}
+}
+
+func (m *HealthMonitor) loop(upchecker Upchecker) {
+	defer m.wg.Done()
+
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			m.check(upchecker)
+		case <-m.quit:
+			return
+		}
+	}
+}
+
+// check upchecks the transaction manager and records the outcome.
+func (m *HealthMonitor) check(upchecker Upchecker) {
+	start := time.Now()
+	err := upchecker.Upcheck()
+	upcheckTimer.UpdateSince(start)
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.status.LastCheck = start
+	if err == nil {
+		if !m.status.Up {
+			log.Info("Private transaction manager is up again", "downtime", common.PrettyDuration(start.Sub(m.status.LastUp)))
+			close(m.upCh)
+		}
+		m.status.Up, m.status.LastUp, m.status.Failures, m.status.Error = true, start, 0, ""
+		upGauge.Update(1)
+		return
+	}
+	failureMeter.Mark(1)
+	if m.status.Up {
+		log.Error("Private transaction manager is down", "err", err)
+		m.upCh = make(chan struct{})
+	}
+	m.status.Up = false
+	m.status.Failures++
+	m.status.Error = err.Error()
+	upGauge.Update(0)
+}
@@ -0,0 +1,45 @@
+package private
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+type stubTransactionManager struct {
+	PrivateTransactionManager
+	err error
+}
+
+func (s *stubTransactionManager) Upcheck() error { return s.err }
+
+func TestHealthMonitor(t *testing.T) {
+	ptm := &stubTransactionManager{err: errors.New("connection refused")}
+	monitor := NewHealthMonitor(ptm, time.Hour)
+
+	monitor.check(ptm)
+	if status := monitor.Status(); status.Up || status.Failures != 1 || status.Error != "connection refused" {
+		t.Fatalf("status mismatch after failed check: %+v", status)
+	}
+	abort := make(chan struct{})
+	close(abort)
+	if monitor.WaitUp(abort) {
+		t.Fatalf("wait succeeded while down")
+	}
+	done := make(chan bool)
+	go func() { done <- monitor.WaitUp(nil) }()
+
+	ptm.err = nil
+	monitor.check(ptm)
+	select {
+	case up := <-done:
+		if !up {
+			t.Fatalf("wait aborted")
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("wait not released once up")
+	}
+	if status := monitor.Status(); !status.Up || status.Failures != 0 || status.Error != "" {
+		t.Fatalf("status mismatch after successful check: %+v", status)
+	}
+}
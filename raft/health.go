@@ -0,0 +1,37 @@
+package raft
+
+import (
+	"github.com/ethereum/go-ethereum/node"
+)
+
+// raftHealth is the state of the local raft node reported by its health check.
+type raftHealth struct {
+	Role         string `json:"role"`
+	Leader       string `json:"leader,omitempty"`
+	ClusterSize  int    `json:"clusterSize"`
+	AppliedIndex uint64 `json:"appliedIndex"`
+}
+
+// HealthChecks implements node.HealthChecker, reporting the node healthy while it
+// is a member of the raft cluster, and ready while it knows the leader.
+func (service *RaftService) HealthChecks(config *node.HealthConfig) map[string]*node.HealthCheck {
+	pm := service.raftProtocolManager
+
+	info := pm.NodeInfo()
+	health := &raftHealth{
+		Role:         info.Role,
+		ClusterSize:  info.ClusterSize,
+		AppliedIndex: info.AppliedIndex,
+	}
+	check := &node.HealthCheck{Healthy: true, Ready: true, Details: health}
+
+	if leader, err := pm.LeaderAddress(); err != nil {
+		check.Ready, check.Message = false, err.Error()
+	} else {
+		health.Leader = leader.NodeId.String()
+	}
+	if pm.isRaftIdRemoved(pm.raftId) {
+		check.Healthy, check.Message = false, "removed from the raft cluster"
+	}
+	return map[string]*node.HealthCheck{"raft": check}
+}
@@ -18,6 +18,7 @@ package rpc
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"math/rand"
 	"net"
@@ -398,6 +399,41 @@ func TestClientNotificationStorm(t *testing.T) {
 	doTest(10000, true)
 }
 
+func TestClientSubscribeLagged(t *testing.T) {
+	clientConn, serverConn := net.Pipe()
+	defer serverConn.Close()
+
+	// Answer the subscription request and drop the subscription straight away.
+	go func() {
+		var req jsonrpcMessage
+		if err := json.NewDecoder(serverConn).Decode(&req); err != nil {
+			return
+		}
+		enc := json.NewEncoder(serverConn)
+		enc.Encode(&jsonSuccessResponse{Version: jsonrpcVersion, Id: req.ID, Result: "0x1"})
+		codec := NewJSONCodec(serverConn)
+		enc.Encode(codec.CreateErrorNotification("0x1", "eth", &subscriptionLaggedError{"lagged"}))
+	}()
+	client, err := newClient(context.Background(), func(context.Context) (net.Conn, error) { return clientConn, nil })
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	sub, err := client.EthSubscribe(context.Background(), make(chan int), "someSubscription")
+	if err != nil {
+		t.Fatal("can't subscribe:", err)
+	}
+	select {
+	case err := <-sub.Err():
+		if coded, ok := err.(Error); !ok || coded.ErrorCode() != -32006 {
+			t.Fatalf("error mismatch: have %v, want lagged error", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("subscription not ended by lagged notification")
+	}
+}
+
 func TestClientHTTP(t *testing.T) {
 	server := newTestServer("service", new(Service))
 	defer server.Stop()
@@ -0,0 +1,261 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// openRPCVersion is the version of the OpenRPC specification the documents
+// returned by rpc_discover follow.
+const openRPCVersion = "1.2.6"
+
+var (
+	jsonMarshalerType   = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+	textMarshalerType   = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// OpenRPCDocument describes the methods offered by a server, following the
+// OpenRPC specification. Subscriptions, which OpenRPC has no notion of, are
+// listed separately with the name they are subscribed to with.
+type OpenRPCDocument struct {
+	OpenRPC       string            `json:"openrpc"`
+	Info          OpenRPCInfo       `json:"info"`
+	Methods       []*OpenRPCMethod  `json:"methods"`
+	Subscriptions []*OpenRPCMethod  `json:"x-subscriptions,omitempty"`
+	Components    OpenRPCComponents `json:"components"`
+}
+
+// OpenRPCInfo identifies the node version a document describes.
+type OpenRPCInfo struct {
+	Title         string `json:"title"`
+	Version       string `json:"version"`
+	QuorumVersion string `json:"x-quorumVersion"`
+}
+
+// OpenRPCMethod describes a method and its parameters and result.
+type OpenRPCMethod struct {
+	Name   string          `json:"name"`
+	Params []*OpenRPCParam `json:"params"`
+	Result *OpenRPCParam   `json:"result,omitempty"`
+}
+
+// OpenRPCParam describes a parameter or the result of a method.
+type OpenRPCParam struct {
+	Name     string      `json:"name"`
+	Required bool        `json:"required,omitempty"`
+	Schema   *JSONSchema `json:"schema"`
+}
+
+// OpenRPCComponents contains the schemas of the named types referred to by the
+// methods.
+type OpenRPCComponents struct {
+	Schemas map[string]*JSONSchema `json:"schemas"`
+}
+
+// JSONSchema is the subset of JSON schema needed to describe Go types.
+type JSONSchema struct {
+	Ref                  string                 `json:"$ref,omitempty"`
+	Title                string                 `json:"title,omitempty"`
+	Type                 string                 `json:"type,omitempty"`
+	Items                *JSONSchema            `json:"items,omitempty"`
+	Properties           map[string]*JSONSchema `json:"properties,omitempty"`
+	AdditionalProperties *JSONSchema            `json:"additionalProperties,omitempty"`
+}
+
+// DiscoverService serves rpc_discover, describing the methods of the server it
+// is registered with.
+type DiscoverService struct {
+	server *Server
+	info   OpenRPCInfo
+}
+
+// NewDiscoverAPI returns the API serving rpc_discover, identifying the node by
+// info in the documents. Like rpc_modules, it is served by every endpoint
+// regardless of the modules exposed.
+func NewDiscoverAPI(info OpenRPCInfo) API {
+	return API{
+		Namespace: MetadataApi,
+		Version:   "1.0",
+		Service:   &DiscoverService{info: info},
+		Public:    true,
+	}
+}
+
+// Discover returns an OpenRPC document describing the methods of all services
+// registered on the server.
+func (s *DiscoverService) Discover() *OpenRPCDocument {
+	gen := &schemaGenerator{schemas: make(map[string]*JSONSchema), names: make(map[reflect.Type]string)}
+	doc := &OpenRPCDocument{
+		OpenRPC: openRPCVersion,
+		Info:    s.info,
+		Methods: []*OpenRPCMethod{},
+	}
+	for name, svc := range s.server.services {
+		for method, callb := range svc.callbacks {
+			doc.Methods = append(doc.Methods, gen.method(name+serviceMethodSeparator+method, callb))
+		}
+		for method, callb := range svc.subscriptions {
+			doc.Subscriptions = append(doc.Subscriptions, gen.method(name+serviceMethodSeparator+method, callb))
+		}
+	}
+	sort.Slice(doc.Methods, func(i, j int) bool { return doc.Methods[i].Name < doc.Methods[j].Name })
+	sort.Slice(doc.Subscriptions, func(i, j int) bool { return doc.Subscriptions[i].Name < doc.Subscriptions[j].Name })
+	doc.Components.Schemas = gen.schemas
+	return doc
+}
+
+// schemaGenerator derives JSON schemas from Go types, collecting the schemas of
+// named struct types as components.
+type schemaGenerator struct {
+	schemas map[string]*JSONSchema // Schemas of named struct types by component name
+	names   map[reflect.Type]string
+}
+
+// method describes a callback.
+func (g *schemaGenerator) method(name string, callb *callback) *OpenRPCMethod {
+	method := &OpenRPCMethod{Name: name, Params: []*OpenRPCParam{}}
+	for i, typ := range callb.argTypes {
+		method.Params = append(method.Params, &OpenRPCParam{
+			Name:     fmt.Sprintf("arg%d", i),
+			Required: typ.Kind() != reflect.Ptr,
+			Schema:   g.schema(typ),
+		})
+	}
+	// Callbacks return a result, an error, or a result followed by an error,
+	// except for subscriptions returning the subscription id
+	switch {
+	case callb.isSubscribe:
+		method.Result = &OpenRPCParam{Name: "subscription", Schema: g.schema(reflect.TypeOf(ID("")))}
+	case callb.method.Type.NumOut() > 0 && callb.errPos != 0:
+		method.Result = &OpenRPCParam{Name: "result", Schema: g.schema(callb.method.Type.Out(0))}
+	}
+	return method
+}
+
+// schema derives the JSON schema of the JSON encoding of a Go type.
+func (g *schemaGenerator) schema(typ reflect.Type) *JSONSchema {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	title := typ.Name()
+
+	// Types encoding themselves are opaque, apart from those encoding as text
+	ptr := reflect.PtrTo(typ)
+	if typ.Implements(textMarshalerType) || ptr.Implements(textUnmarshalerType) {
+		if !typ.Implements(jsonMarshalerType) && !ptr.Implements(jsonUnmarshalerType) {
+			return &JSONSchema{Title: title, Type: "string"}
+		}
+	}
+	if typ.Implements(jsonMarshalerType) || ptr.Implements(jsonUnmarshalerType) {
+		return &JSONSchema{Title: title}
+	}
+	switch typ.Kind() {
+	case reflect.Bool:
+		return &JSONSchema{Title: title, Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return &JSONSchema{Title: title, Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &JSONSchema{Title: title, Type: "number"}
+	case reflect.String:
+		return &JSONSchema{Title: title, Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if typ.Elem().Kind() == reflect.Uint8 && typ.Kind() == reflect.Slice {
+			return &JSONSchema{Title: title, Type: "string"} // base64 encoded
+		}
+		return &JSONSchema{Title: title, Type: "array", Items: g.schema(typ.Elem())}
+	case reflect.Map:
+		return &JSONSchema{Title: title, Type: "object", AdditionalProperties: g.schema(typ.Elem())}
+	case reflect.Struct:
+		if title == "" {
+			return g.structSchema(typ)
+		}
+		name, ok := g.names[typ]
+		if !ok {
+			name = g.componentName(typ)
+			g.names[typ] = name
+			g.schemas[name] = nil // Reserve the name for recursive types
+			g.schemas[name] = g.structSchema(typ)
+		}
+		return &JSONSchema{Ref: "#/components/schemas/" + name}
+	default:
+		return &JSONSchema{Title: title} // interfaces, accepting anything
+	}
+}
+
+// componentName returns a unique component name for a named type.
+func (g *schemaGenerator) componentName(typ reflect.Type) string {
+	name := typ.Name()
+	if _, taken := g.schemas[name]; !taken {
+		return name
+	}
+	pkg := typ.PkgPath()
+	if i := strings.LastIndex(pkg, "/"); i >= 0 {
+		pkg = pkg[i+1:]
+	}
+	name = pkg + "." + typ.Name()
+	for i := 2; ; i++ {
+		if _, taken := g.schemas[name]; !taken {
+			return name
+		}
+		name = fmt.Sprintf("%s.%s%d", pkg, typ.Name(), i)
+	}
+}
+
+// structSchema derives the schema of a struct from the JSON names of its
+// exported fields, flattening embedded structs like encoding/json does.
+func (g *schemaGenerator) structSchema(typ reflect.Type) *JSONSchema {
+	schema := &JSONSchema{Title: typ.Name(), Type: "object", Properties: make(map[string]*JSONSchema)}
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name := strings.Split(tag, ",")[0]
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Ptr {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				for prop, sub := range g.structSchema(embedded).Properties {
+					if _, ok := schema.Properties[prop]; !ok {
+						schema.Properties[prop] = sub
+					}
+				}
+				continue
+			}
+		}
+		if field.PkgPath != "" {
+			continue // unexported
+		}
+		if name == "" {
+			name = field.Name
+		}
+		schema.Properties[name] = g.schema(field.Type)
+	}
+	return schema
+}
@@ -0,0 +1,100 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"testing"
+)
+
+type DiscoverTestArgs struct {
+	From       string   `json:"from"`
+	Value      *int     `json:"value"`
+	PrivateFor []string `json:"privateFor"`
+	Ignored    string   `json:"-"`
+	internal   string
+}
+
+type DiscoverTestService struct{}
+
+func (s *DiscoverTestService) Send(ctx context.Context, args DiscoverTestArgs, gas *int) (string, error) {
+	return "", nil
+}
+
+func (s *DiscoverTestService) Check(n int) error { return nil }
+
+func (s *DiscoverTestService) Events(ctx context.Context) (*Subscription, error) { return nil, nil }
+
+func TestDiscover(t *testing.T) {
+	server := NewServer()
+	defer server.Stop()
+
+	info := OpenRPCInfo{Title: "Test API", Version: "1.0.0", QuorumVersion: "2.0.0"}
+	discover := NewDiscoverAPI(info)
+	if err := server.RegisterName(discover.Namespace, discover.Service); err != nil {
+		t.Fatalf("failed to register discover service: %v", err)
+	}
+	if err := server.RegisterName("test", new(DiscoverTestService)); err != nil {
+		t.Fatalf("failed to register service: %v", err)
+	}
+	client := DialInProc(server)
+	defer client.Close()
+
+	var doc OpenRPCDocument
+	if err := client.Call(&doc, "rpc_discover"); err != nil {
+		t.Fatalf("discover failed: %v", err)
+	}
+	if doc.Info != info {
+		t.Errorf("info mismatch: have %+v, want %+v", doc.Info, info)
+	}
+	methods := make(map[string]*OpenRPCMethod)
+	for _, method := range doc.Methods {
+		methods[method.Name] = method
+	}
+	if _, ok := methods["rpc_discover"]; !ok {
+		t.Errorf("rpc_discover missing from its own document")
+	}
+	send := methods["test_send"]
+	if send == nil {
+		t.Fatalf("test_send missing from document")
+	}
+	if len(send.Params) != 2 || !send.Params[0].Required || send.Params[1].Required {
+		t.Fatalf("test_send parameters mismatch: %+v", send.Params)
+	}
+	if ref := send.Params[0].Schema.Ref; ref != "#/components/schemas/DiscoverTestArgs" {
+		t.Errorf("argument reference mismatch: have %q", ref)
+	}
+	if send.Result == nil || send.Result.Schema.Type != "string" {
+		t.Errorf("test_send result mismatch: %+v", send.Result)
+	}
+	if check := methods["test_check"]; check == nil || check.Result != nil || check.Params[0].Schema.Type != "integer" {
+		t.Errorf("test_check mismatch: %+v", check)
+	}
+	args := doc.Components.Schemas["DiscoverTestArgs"]
+	if args == nil {
+		t.Fatalf("argument schema missing from components")
+	}
+	if len(args.Properties) != 3 {
+		t.Errorf("argument properties mismatch: have %d, want 3", len(args.Properties))
+	}
+	if p := args.Properties["privateFor"]; p == nil || p.Type != "array" || p.Items.Type != "string" {
+		t.Errorf("privateFor schema mismatch: %+v", p)
+	}
+	if len(doc.Subscriptions) != 1 || doc.Subscriptions[0].Name != "test_events" {
+		t.Errorf("subscriptions mismatch: %+v", doc.Subscriptions)
+	}
+}
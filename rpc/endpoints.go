@@ -17,13 +17,27 @@
 package rpc
 
 import (
+	"crypto/tls"
 	"net"
+	"net/http"
 
 	"github.com/ethereum/go-ethereum/log"
 )
 
+// EndpointOptions configures the request limits, transport security and access
+// logging of the HTTP and websocket endpoints. The zero value serves requests in
+// cleartext, without limits or logging.
+type EndpointOptions struct {
+	Limits    Limits                  // Limits imposed on the requests of clients
+	TLSConfig *tls.Config             // Configuration serving requests over TLS if non-nil
+	AccessLog log.Logger              // Logger of the requests served if non-nil
+	Handlers  map[string]http.Handler // Handlers serving the requests for their paths instead of the API (HTTP only)
+}
+
 // StartHTTPEndpoint starts the HTTP RPC endpoint, configured with cors/vhosts/modules
-func StartHTTPEndpoint(endpoint string, apis []API, modules []string, cors []string, vhosts []string, timeouts HTTPTimeouts) (net.Listener, *Server, error) {
+// and options. Requests for the paths of the handlers in options are subject to
+// the same checks, rate limit and access log as API calls.
+func StartHTTPEndpoint(endpoint string, apis []API, modules []string, cors []string, vhosts []string, timeouts HTTPTimeouts, options EndpointOptions) (net.Listener, *Server, error) {
 	// Generate the whitelist based on the allowed modules
 	whitelist := make(map[string]bool)
 	for _, module := range modules {
@@ -31,8 +45,10 @@ func StartHTTPEndpoint(endpoint string, apis []API, modules []string, cors []str
 	}
 	// Register all the APIs exposed by the services
 	handler := NewServer()
+	handler.SetLimits(options.Limits)
+	handler.SetAccessLog(options.AccessLog)
 	for _, api := range apis {
-		if whitelist[api.Namespace] || (len(whitelist) == 0 && api.Public) {
+		if whitelist[api.Namespace] || api.Namespace == MetadataApi || (len(whitelist) == 0 && api.Public) {
 			if err := handler.RegisterName(api.Namespace, api.Service); err != nil {
 				return nil, nil, err
 			}
@@ -47,12 +63,24 @@ func StartHTTPEndpoint(endpoint string, apis []API, modules []string, cors []str
 	if listener, err = net.Listen("tcp", endpoint); err != nil {
 		return nil, nil, err
 	}
-	go NewHTTPServer(cors, vhosts, timeouts, handler).Serve(listener)
+	if options.TLSConfig != nil {
+		listener = tls.NewListener(listener, options.TLSConfig)
+	}
+	var srv http.Handler = handler
+	if len(options.Handlers) > 0 {
+		mux := http.NewServeMux()
+		for path, h := range options.Handlers {
+			mux.Handle(path, handler.observeHTTP(path, h))
+		}
+		mux.Handle("/", handler)
+		srv = mux
+	}
+	go NewHTTPServer(cors, vhosts, timeouts, srv).Serve(listener)
 	return listener, handler, err
 }
 
-// StartWSEndpoint starts a websocket endpoint
-func StartWSEndpoint(endpoint string, apis []API, modules []string, wsOrigins []string, exposeAll bool) (net.Listener, *Server, error) {
+// StartWSEndpoint starts a websocket endpoint configured with options.
+func StartWSEndpoint(endpoint string, apis []API, modules []string, wsOrigins []string, exposeAll bool, options EndpointOptions) (net.Listener, *Server, error) {
 
 	// Generate the whitelist based on the allowed modules
 	whitelist := make(map[string]bool)
@@ -61,8 +89,10 @@ func StartWSEndpoint(endpoint string, apis []API, modules []string, wsOrigins []
 	}
 	// Register all the APIs exposed by the services
 	handler := NewServer()
+	handler.SetLimits(options.Limits)
+	handler.SetAccessLog(options.AccessLog)
 	for _, api := range apis {
-		if exposeAll || whitelist[api.Namespace] || (len(whitelist) == 0 && api.Public) {
+		if exposeAll || whitelist[api.Namespace] || api.Namespace == MetadataApi || (len(whitelist) == 0 && api.Public) {
 			if err := handler.RegisterName(api.Namespace, api.Service); err != nil {
 				return nil, nil, err
 			}
@@ -77,6 +107,9 @@ func StartWSEndpoint(endpoint string, apis []API, modules []string, wsOrigins []
 	if listener, err = net.Listen("tcp", endpoint); err != nil {
 		return nil, nil, err
 	}
+	if options.TLSConfig != nil {
+		listener = tls.NewListener(listener, options.TLSConfig)
+	}
 	go NewWSServer(wsOrigins, handler).Serve(listener)
 	return listener, handler, err
 
@@ -65,6 +65,7 @@ type jsonErrResponse struct {
 type jsonSubscription struct {
 	Subscription string      `json:"subscription"`
 	Result       interface{} `json:"result,omitempty"`
+	Error        *jsonError  `json:"error,omitempty"`
 }
 
 type jsonNotification struct {
@@ -341,6 +342,12 @@ func (c *jsonCodec) CreateNotification(subid, namespace string, event interface{
 		Params: jsonSubscription{Subscription: subid, Result: event}}
 }
 
+// CreateErrorNotification will create a JSON-RPC notification ending the subscription with the given id with err.
+func (c *jsonCodec) CreateErrorNotification(subid, namespace string, err Error) interface{} {
+	return &jsonNotification{Version: jsonrpcVersion, Method: namespace + notificationMethodSuffix,
+		Params: jsonSubscription{Subscription: subid, Error: &jsonError{Code: err.ErrorCode(), Message: err.Error()}}}
+}
+
 // Write message to client
 func (c *jsonCodec) Write(res interface{}) error {
 	c.encMu.Lock()
@@ -0,0 +1,196 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+// rateLimitIdle is the time after which the token bucket of an idle client is
+// dropped.
+const rateLimitIdle = 10 * time.Minute
+
+var (
+	batchLimitMeter       = metrics.NewRegisteredMeter("rpc/limits/batch", nil)
+	responseLimitMeter    = metrics.NewRegisteredMeter("rpc/limits/response", nil)
+	concurrencyLimitMeter = metrics.NewRegisteredMeter("rpc/limits/concurrency", nil)
+	rateLimitMeter        = metrics.NewRegisteredMeter("rpc/limits/rate", nil)
+)
+
+// Limits protect a server from clients exhausting the resources of the node.
+// The zero value imposes no limits, besides the default subscription buffer.
+type Limits struct {
+	MaxBatchLength     int            `toml:",omitempty"` // Maximum number of requests in a batch (0 = unlimited)
+	MaxResponseSize    int            `toml:",omitempty"` // Maximum size of a response in bytes (0 = unlimited)
+	MethodConcurrency  map[string]int `toml:",omitempty"` // Maximum number of concurrent calls by method name, e.g. "eth_getLogs"
+	RateLimit          float64        `toml:",omitempty"` // Requests per second permitted to every client (0 = unlimited)
+	RateBurst          int            `toml:",omitempty"` // Requests a client may burst above the rate limit
+	SubscriptionBuffer int            `toml:",omitempty"` // Notifications queued by subscription before it lags (0 = default)
+}
+
+// limitExceededError is returned for requests refused due to the limits of
+// the server.
+type limitExceededError struct{ message string }
+
+func (e *limitExceededError) ErrorCode() int { return -32005 }
+
+func (e *limitExceededError) Error() string { return e.message }
+
+// NewLimitExceededError returns an error for requests a service refuses due to
+// the limits of the node. Returned by a callback, it is reported to the client
+// with its own error code rather than as a generic callback error.
+func NewLimitExceededError(message string) error {
+	return &limitExceededError{message}
+}
+
+// tokenBucket is the request allowance of a client.
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// limiter enforces the limits of a server.
+type limiter struct {
+	limits Limits
+	slots  map[string]chan struct{} // Concurrency slots by method name
+
+	buckets   map[string]*tokenBucket // Rate limit allowance by client
+	lastSweep time.Time
+	lock      sync.Mutex
+}
+
+func newLimiter(limits Limits) *limiter {
+	l := &limiter{
+		limits:  limits,
+		slots:   make(map[string]chan struct{}),
+		buckets: make(map[string]*tokenBucket),
+	}
+	for method, max := range limits.MethodConcurrency {
+		if max > 0 {
+			l.slots[method] = make(chan struct{}, max)
+		}
+	}
+	return l
+}
+
+// checkBatch returns an error if a batch of the given length is too long.
+func (l *limiter) checkBatch(length int) Error {
+	if l.limits.MaxBatchLength > 0 && length > l.limits.MaxBatchLength {
+		batchLimitMeter.Mark(1)
+		return &limitExceededError{fmt.Sprintf("batch of %d requests exceeds limit of %d", length, l.limits.MaxBatchLength)}
+	}
+	return nil
+}
+
+// checkResponse returns an error if a response of the given size is too large.
+func (l *limiter) checkResponse(size int) Error {
+	if l.limits.MaxResponseSize > 0 && size > l.limits.MaxResponseSize {
+		responseLimitMeter.Mark(1)
+		return &limitExceededError{fmt.Sprintf("response of %d bytes exceeds limit of %d", size, l.limits.MaxResponseSize)}
+	}
+	return nil
+}
+
+// acquire reserves a concurrency slot for a call of the given method, returning
+// the function releasing it.
+func (l *limiter) acquire(method string) (func(), Error) {
+	slots, ok := l.slots[method]
+	if !ok {
+		return func() {}, nil
+	}
+	select {
+	case slots <- struct{}{}:
+		return func() { <-slots }, nil
+	default:
+		concurrencyLimitMeter.Mark(1)
+		return nil, &limitExceededError{fmt.Sprintf("too many concurrent %s calls", method)}
+	}
+}
+
+// allow takes a token from the bucket of the client making the request, returning
+// an error if the client exceeded its rate. Requests of unidentified clients, i.e.
+// those connected over IPC or in-process, are not limited.
+func (l *limiter) allow(ctx context.Context) Error {
+	if l.limits.RateLimit <= 0 {
+		return nil
+	}
+	client := clientIdentity(ctx)
+	if client == "" {
+		return nil
+	}
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	now := time.Now()
+	if now.Sub(l.lastSweep) > rateLimitIdle {
+		for id, bucket := range l.buckets {
+			if now.Sub(bucket.last) > rateLimitIdle {
+				delete(l.buckets, id)
+			}
+		}
+		l.lastSweep = now
+	}
+	burst := float64(l.limits.RateBurst)
+	if burst < 1 {
+		burst = 1
+	}
+	bucket := l.buckets[client]
+	if bucket == nil {
+		bucket = &tokenBucket{tokens: burst, last: now}
+		l.buckets[client] = bucket
+	}
+	bucket.tokens += now.Sub(bucket.last).Seconds() * l.limits.RateLimit
+	if bucket.tokens > burst {
+		bucket.tokens = burst
+	}
+	bucket.last = now
+
+	if bucket.tokens < 1 {
+		rateLimitMeter.Mark(1)
+		return &limitExceededError{"request rate limit exceeded"}
+	}
+	bucket.tokens--
+	return nil
+}
+
+// clientIdentity returns the identity of the client of a request: the subject
+// of its TLS client certificate if it presented one, otherwise its IP address.
+func clientIdentity(ctx context.Context) string {
+	if identity, ok := ctx.Value("identity").(string); ok && identity != "" {
+		return identity
+	}
+	remote, ok := ctx.Value("remote").(string)
+	if !ok || remote == "" {
+		return ""
+	}
+	if host, _, err := net.SplitHostPort(remote); err == nil {
+		return host
+	}
+	return remote
+}
+
+// SetLimits configures the limits imposed on the requests of clients. It must
+// be called before the server starts serving requests.
+func (s *Server) SetLimits(limits Limits) {
+	s.limiter = newLimiter(limits)
+}
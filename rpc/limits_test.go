@@ -0,0 +1,140 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+type LimitTestService struct{}
+
+func (s *LimitTestService) Echo(str string) string { return str }
+
+// startLimitedEndpoint starts an HTTP endpoint imposing the given limits and
+// returns a client connected to it.
+func startLimitedEndpoint(t *testing.T, limits Limits) (*Client, func()) {
+	apis := []API{{Namespace: "test", Service: new(LimitTestService), Public: true}}
+	listener, handler, err := StartHTTPEndpoint("127.0.0.1:0", apis, nil, nil, []string{"*"}, DefaultHTTPTimeouts, EndpointOptions{Limits: limits})
+	if err != nil {
+		t.Fatalf("failed to start endpoint: %v", err)
+	}
+	client, err := DialHTTP("http://" + listener.Addr().String())
+	if err != nil {
+		t.Fatalf("failed to dial endpoint: %v", err)
+	}
+	return client, func() {
+		client.Close()
+		listener.Close()
+		handler.Stop()
+	}
+}
+
+func checkLimitExceeded(t *testing.T, err error) {
+	t.Helper()
+	if coded, ok := err.(Error); !ok || coded.ErrorCode() != -32005 {
+		t.Fatalf("error mismatch: have %v, want limit exceeded error", err)
+	}
+}
+
+func TestBatchLengthLimit(t *testing.T) {
+	client, stop := startLimitedEndpoint(t, Limits{MaxBatchLength: 2})
+	defer stop()
+
+	batch := make([]BatchElem, 3)
+	for i := range batch {
+		batch[i] = BatchElem{Method: "test_echo", Args: []interface{}{"x"}, Result: new(string)}
+	}
+	if err := client.BatchCall(batch[:2]); err != nil || batch[0].Error != nil {
+		t.Fatalf("batch within limit failed: %v %v", err, batch[0].Error)
+	}
+	if err := client.BatchCall(batch); err == nil {
+		t.Fatalf("batch exceeding limit succeeded")
+	}
+}
+
+func TestResponseSizeLimit(t *testing.T) {
+	client, stop := startLimitedEndpoint(t, Limits{MaxResponseSize: 16})
+	defer stop()
+
+	var result string
+	if err := client.Call(&result, "test_echo", "short"); err != nil || result != "short" {
+		t.Fatalf("small response mismatch: have %q, err %v", result, err)
+	}
+	checkLimitExceeded(t, client.Call(&result, "test_echo", strings.Repeat("x", 32)))
+}
+
+func TestRateLimit(t *testing.T) {
+	client, stop := startLimitedEndpoint(t, Limits{RateLimit: 0.001, RateBurst: 2})
+	defer stop()
+
+	var result string
+	for i := 0; i < 2; i++ {
+		if err := client.Call(&result, "test_echo", "x"); err != nil {
+			t.Fatalf("call %d within burst failed: %v", i, err)
+		}
+	}
+	checkLimitExceeded(t, client.Call(&result, "test_echo", "x"))
+}
+
+// Tests that the requests of handlers served next to the API count against the
+// rate limit of the client, like API calls.
+func TestHandlerRateLimit(t *testing.T) {
+	options := EndpointOptions{
+		Limits: Limits{RateLimit: 0.001, RateBurst: 1},
+		Handlers: map[string]http.Handler{
+			"/probe": http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}),
+		},
+	}
+	listener, handler, err := StartHTTPEndpoint("127.0.0.1:0", nil, nil, nil, []string{"*"}, DefaultHTTPTimeouts, options)
+	if err != nil {
+		t.Fatalf("failed to start endpoint: %v", err)
+	}
+	defer listener.Close()
+	defer handler.Stop()
+
+	for i, want := range []int{http.StatusOK, http.StatusTooManyRequests} {
+		resp, err := http.Get("http://" + listener.Addr().String() + "/probe")
+		if err != nil {
+			t.Fatalf("request %d failed: %v", i, err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != want {
+			t.Errorf("request %d status mismatch: have %d, want %d", i, resp.StatusCode, want)
+		}
+	}
+}
+
+func TestMethodConcurrencyLimit(t *testing.T) {
+	l := newLimiter(Limits{MethodConcurrency: map[string]int{"eth_getLogs": 1}})
+
+	release, err := l.acquire("eth_getLogs")
+	if err != nil {
+		t.Fatalf("failed to acquire free slot: %v", err)
+	}
+	if _, err := l.acquire("eth_getLogs"); err == nil {
+		t.Fatalf("acquired slot beyond limit")
+	}
+	if _, err := l.acquire("eth_call"); err != nil {
+		t.Fatalf("unlimited method refused: %v", err)
+	}
+	release()
+	if _, err := l.acquire("eth_getLogs"); err != nil {
+		t.Fatalf("failed to acquire released slot: %v", err)
+	}
+}
@@ -0,0 +1,146 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+var (
+	rpcRequestMeter = metrics.NewRegisteredMeter("rpc/requests", nil) // Requests of all methods, known or not
+	rpcFailureMeter = metrics.NewRegisteredMeter("rpc/failures", nil) // Requests answered with an error
+)
+
+// methodMetrics are the metrics of a registered method, shared by all servers
+// exposing it.
+type methodMetrics struct {
+	calls   metrics.Meter
+	errors  metrics.Meter
+	latency metrics.Timer
+}
+
+func newMethodMetrics(method string) *methodMetrics {
+	return &methodMetrics{
+		calls:   metrics.GetOrRegisterMeter("rpc/calls/"+method, nil),
+		errors:  metrics.GetOrRegisterMeter("rpc/errors/"+method, nil),
+		latency: metrics.GetOrRegisterTimer("rpc/duration/"+method, nil),
+	}
+}
+
+// SetAccessLog configures a logger every request served is logged to, with the
+// method, the identity of the caller, the duration, the error code and the size
+// of the result. It must be called before the server starts serving requests.
+func (s *Server) SetAccessLog(logger log.Logger) {
+	s.accessLog = logger
+}
+
+// handleObserved executes a request like handle, recording its outcome in the
+// metrics and the access log.
+func (s *Server) handleObserved(ctx context.Context, codec ServerCodec, req *serverRequest) (interface{}, func()) {
+	start := time.Now()
+	response, callback := s.handle(ctx, codec, req)
+	duration := time.Since(start)
+
+	code := 0
+	if res, ok := response.(*jsonErrResponse); ok {
+		code = res.Error.Code
+	}
+	rpcRequestMeter.Mark(1)
+	if code != 0 {
+		rpcFailureMeter.Mark(1)
+	}
+	if req.callb != nil {
+		req.callb.metrics.calls.Mark(1)
+		req.callb.metrics.latency.Update(duration)
+		if code != 0 {
+			req.callb.metrics.errors.Mark(1)
+		}
+	}
+	if s.accessLog != nil {
+		identity := clientIdentity(ctx)
+		if identity == "" {
+			identity = "local"
+		}
+		size := 0
+		if res, ok := response.(*jsonSuccessResponse); ok {
+			if blob, ok := res.Result.(json.RawMessage); ok {
+				size = len(blob)
+			}
+		}
+		s.accessLog.Info("RPC call", "method", req.method, "caller", identity, "duration", common.PrettyDuration(duration), "code", code, "size", size)
+	}
+	return response, callback
+}
+
+// observeHTTP wraps a handler served next to the API over HTTP, subjecting its
+// requests to the rate limit of the server and recording them in the metrics
+// and the access log like API calls, by the path of the handler.
+func (s *Server) observeHTTP(path string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		ctx := httpContext(r)
+		res := &observedResponseWriter{ResponseWriter: w, status: http.StatusOK}
+
+		var err Error
+		if s.limiter != nil {
+			err = s.limiter.allow(ctx)
+		}
+		if err != nil {
+			http.Error(res, err.Error(), http.StatusTooManyRequests)
+		} else {
+			next.ServeHTTP(res, r.WithContext(ctx))
+		}
+		duration := time.Since(start)
+
+		rpcRequestMeter.Mark(1)
+		if res.status >= http.StatusBadRequest {
+			rpcFailureMeter.Mark(1)
+		}
+		if s.accessLog != nil {
+			identity := clientIdentity(ctx)
+			if identity == "" {
+				identity = "local"
+			}
+			s.accessLog.Info("HTTP request", "path", path, "caller", identity, "duration", common.PrettyDuration(duration), "status", res.status, "size", res.size)
+		}
+	})
+}
+
+// observedResponseWriter records the status and size of a response.
+type observedResponseWriter struct {
+	http.ResponseWriter
+	status int
+	size   int
+}
+
+func (w *observedResponseWriter) WriteHeader(status int) {
+	w.status = status
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *observedResponseWriter) Write(b []byte) (int, error) {
+	n, err := w.ResponseWriter.Write(b)
+	w.size += n
+	return n, err
+}
@@ -0,0 +1,97 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+type AccessLogTestService struct{}
+
+func (s *AccessLogTestService) Echo(str string) string { return str }
+
+func (s *AccessLogTestService) Fail() error { return errors.New("failed") }
+
+func TestAccessLogAndMetrics(t *testing.T) {
+	enabled := metrics.Enabled
+	metrics.Enabled = true
+	defer func() { metrics.Enabled = enabled }()
+
+	var (
+		records []map[string]interface{}
+		lock    sync.Mutex
+	)
+	logger := log.New()
+	logger.SetHandler(log.FuncHandler(func(r *log.Record) error {
+		lock.Lock()
+		defer lock.Unlock()
+
+		record := make(map[string]interface{})
+		for i := 0; i < len(r.Ctx); i += 2 {
+			record[r.Ctx[i].(string)] = r.Ctx[i+1]
+		}
+		records = append(records, record)
+		return nil
+	}))
+	apis := []API{{Namespace: "accesslogtest", Service: new(AccessLogTestService), Public: true}}
+	listener, handler, err := StartHTTPEndpoint("127.0.0.1:0", apis, nil, nil, []string{"*"}, DefaultHTTPTimeouts, EndpointOptions{AccessLog: logger})
+	if err != nil {
+		t.Fatalf("failed to start endpoint: %v", err)
+	}
+	defer listener.Close()
+	defer handler.Stop()
+
+	client, err := DialHTTP("http://" + listener.Addr().String())
+	if err != nil {
+		t.Fatalf("failed to dial endpoint: %v", err)
+	}
+	defer client.Close()
+
+	var result string
+	if err := client.Call(&result, "accesslogtest_echo", "hello"); err != nil {
+		t.Fatalf("call failed: %v", err)
+	}
+	if err := client.Call(nil, "accesslogtest_fail"); err == nil {
+		t.Fatalf("failing call succeeded")
+	}
+	lock.Lock()
+	defer lock.Unlock()
+
+	if len(records) != 2 {
+		t.Fatalf("access log length mismatch: have %d, want 2", len(records))
+	}
+	if r := records[0]; r["method"] != "accesslogtest_echo" || r["caller"] != "127.0.0.1" || r["code"] != 0 || r["size"].(int) == 0 {
+		t.Errorf("successful call record mismatch: %v", r)
+	}
+	if r := records[1]; r["method"] != "accesslogtest_fail" || r["code"] != -32000 {
+		t.Errorf("failed call record mismatch: %v", r)
+	}
+	if calls := metrics.GetOrRegisterMeter("rpc/calls/accesslogtest_echo", nil).Count(); calls != 1 {
+		t.Errorf("call count mismatch: have %d, want 1", calls)
+	}
+	if failures := metrics.GetOrRegisterMeter("rpc/errors/accesslogtest_fail", nil).Count(); failures != 1 {
+		t.Errorf("error count mismatch: have %d, want 1", failures)
+	}
+	if latency := metrics.GetOrRegisterTimer("rpc/duration/accesslogtest_echo", nil).Count(); latency != 1 {
+		t.Errorf("latency sample count mismatch: have %d, want 1", latency)
+	}
+}
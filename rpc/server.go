@@ -18,6 +18,7 @@ package rpc
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"reflect"
 	"runtime"
@@ -81,6 +82,10 @@ func (s *Server) RegisterName(name string, rcvr interface{}) error {
 		s.services = make(serviceRegistry)
 	}
 
+	if discover, ok := rcvr.(*DiscoverService); ok {
+		// APIs are shared by all endpoints, describe this server only
+		rcvr = &DiscoverService{server: s, info: discover.info}
+	}
 	svc := new(service)
 	svc.typ = reflect.TypeOf(rcvr)
 	rcvrVal := reflect.ValueOf(rcvr)
@@ -97,6 +102,12 @@ func (s *Server) RegisterName(name string, rcvr interface{}) error {
 	if len(methods) == 0 && len(subscriptions) == 0 {
 		return fmt.Errorf("Service %T doesn't have any suitable methods/subscriptions to expose", rcvr)
 	}
+	for method, callb := range methods {
+		callb.metrics = newMethodMetrics(name + serviceMethodSeparator + method)
+	}
+	for method, callb := range subscriptions {
+		callb.metrics = newMethodMetrics(name + serviceMethodSeparator + method)
+	}
 
 	// already a previous service register under given name, merge methods/subscriptions
 	if regsvc, present := s.services[name]; present {
@@ -145,7 +156,11 @@ func (s *Server) serveRequest(ctx context.Context, codec ServerCodec, singleShot
 	// to send notification to clients. It is tied to the codec/connection. If the
 	// connection is closed the notifier will stop and cancels all active subscriptions.
 	if options&OptionSubscriptions == OptionSubscriptions {
-		ctx = context.WithValue(ctx, notifierKey{}, newNotifier(codec))
+		var buffer int
+		if s.limiter != nil {
+			buffer = s.limiter.limits.SubscriptionBuffer
+		}
+		ctx = context.WithValue(ctx, notifierKey{}, newNotifier(codec, buffer))
 	}
 	s.codecsMu.Lock()
 	if atomic.LoadInt32(&s.run) != 1 { // server stopped
@@ -184,6 +199,16 @@ func (s *Server) serveRequest(ctx context.Context, codec ServerCodec, singleShot
 			}
 			return nil
 		}
+		// Refuse batches exceeding the limit of the server as a whole
+		if batch && s.limiter != nil {
+			if err := s.limiter.checkBatch(len(reqs)); err != nil {
+				codec.Write(codec.CreateErrorResponse(nil, err))
+				if singleShot {
+					return nil
+				}
+				continue
+			}
+		}
 		// If a single shot request is executing, run and return immediately
 		if singleShot {
 			if batch {
@@ -256,6 +281,11 @@ func (s *Server) handle(ctx context.Context, codec ServerCodec, req *serverReque
 	if req.err != nil {
 		return codec.CreateErrorResponse(&req.id, req.err), nil
 	}
+	if s.limiter != nil {
+		if err := s.limiter.allow(ctx); err != nil {
+			return codec.CreateErrorResponse(&req.id, err), nil
+		}
+	}
 
 	if req.isUnsubscribe { // cancel subscription, first param must be the subscription id
 		if len(req.args) >= 1 && req.args[0].Kind() == reflect.String {
@@ -301,6 +331,13 @@ func (s *Server) handle(ctx context.Context, codec ServerCodec, req *serverReque
 	//Pass the request ID to the method as part of the context, in case the method needs it later
 	contextWithId := context.WithValue(ctx, "id", req.id)
 	//End-Quorum
+	if s.limiter != nil {
+		release, err := s.limiter.acquire(req.svcname + serviceMethodSeparator + formatName(req.callb.method.Name))
+		if err != nil {
+			return codec.CreateErrorResponse(&req.id, err), nil
+		}
+		defer release()
+	}
 	arguments := []reflect.Value{req.callb.rcvr}
 	if req.callb.hasCtx {
 		arguments = append(arguments, reflect.ValueOf(contextWithId))
@@ -317,22 +354,33 @@ func (s *Server) handle(ctx context.Context, codec ServerCodec, req *serverReque
 	if req.callb.errPos >= 0 { // test if method returned an error
 		if !reply[req.callb.errPos].IsNil() {
 			e := reply[req.callb.errPos].Interface().(error)
+			if limit, ok := e.(*limitExceededError); ok {
+				return codec.CreateErrorResponse(&req.id, limit), nil
+			}
 			res := codec.CreateErrorResponse(&req.id, &callbackError{e.Error()})
 			return res, nil
 		}
 	}
-	return codec.CreateResponse(req.id, reply[0].Interface()), nil
+	result := reply[0].Interface()
+	if s.accessLog != nil || (s.limiter != nil && s.limiter.limits.MaxResponseSize > 0) {
+		// Encode the result up front to measure it, the codec only copies the blob
+		blob, err := json.Marshal(result)
+		if err != nil {
+			return codec.CreateErrorResponse(&req.id, &callbackError{err.Error()}), nil
+		}
+		if s.limiter != nil {
+			if err := s.limiter.checkResponse(len(blob)); err != nil {
+				return codec.CreateErrorResponse(&req.id, err), nil
+			}
+		}
+		result = json.RawMessage(blob)
+	}
+	return codec.CreateResponse(req.id, result), nil
 }
 
 // exec executes the given request and writes the result back using the codec.
 func (s *Server) exec(ctx context.Context, codec ServerCodec, req *serverRequest) {
-	var response interface{}
-	var callback func()
-	if req.err != nil {
-		response = codec.CreateErrorResponse(&req.id, req.err)
-	} else {
-		response, callback = s.handle(ctx, codec, req)
-	}
+	response, callback := s.handleObserved(ctx, codec, req)
 
 	if err := codec.Write(response); err != nil {
 		log.Error(fmt.Sprintf("%v\n", err))
@@ -351,13 +399,9 @@ func (s *Server) execBatch(ctx context.Context, codec ServerCodec, requests []*s
 	responses := make([]interface{}, len(requests))
 	var callbacks []func()
 	for i, req := range requests {
-		if req.err != nil {
-			responses[i] = codec.CreateErrorResponse(&req.id, req.err)
-		} else {
-			var callback func()
-			if responses[i], callback = s.handle(ctx, codec, req); callback != nil {
-				callbacks = append(callbacks, callback)
-			}
+		var callback func()
+		if responses[i], callback = s.handleObserved(ctx, codec, req); callback != nil {
+			callbacks = append(callbacks, callback)
 		}
 	}
 
@@ -441,6 +485,10 @@ func (s *Server) readRequest(codec ServerCodec) ([]*serverRequest, bool, Error)
 
 		requests[i] = &serverRequest{id: r.id, err: &methodNotFoundError{r.service, r.method}}
 	}
-
+	for i, r := range reqs {
+		if r.service != "" {
+			requests[i].method = r.service + serviceMethodSeparator + r.method
+		}
+	}
 	return requests, batch, nil
 }
@@ -19,16 +19,36 @@ package rpc
 import (
 	"context"
 	"errors"
+	"fmt"
 	"sync"
+
+	"github.com/ethereum/go-ethereum/metrics"
 )
 
+// defaultSubscriptionBuffer is the number of notifications queued for a
+// subscription before it lags, unless configured otherwise.
+const defaultSubscriptionBuffer = 10000
+
 var (
 	// ErrNotificationsUnsupported is returned when the connection doesn't support notifications
 	ErrNotificationsUnsupported = errors.New("notifications not supported")
 	// ErrNotificationNotFound is returned when the notification for the given id is not found
 	ErrSubscriptionNotFound = errors.New("subscription not found")
+	// ErrSubscriptionLagged is returned when notifying a subscription that was
+	// dropped because its client didn't keep up with its notifications.
+	ErrSubscriptionLagged = errors.New("subscription lagged")
+
+	subscriptionLagMeter = metrics.NewRegisteredMeter("rpc/subscriptions/lagged", nil)
 )
 
+// subscriptionLaggedError is sent to clients whose subscription is dropped for
+// not keeping up with its notifications.
+type subscriptionLaggedError struct{ message string }
+
+func (e *subscriptionLaggedError) ErrorCode() int { return -32006 }
+
+func (e *subscriptionLaggedError) Error() string { return e.message }
+
 // ID defines a pseudo random number that is used to identify RPC subscriptions.
 type ID string
 
@@ -38,9 +58,15 @@ type Subscription struct {
 	ID        ID
 	namespace string
 	err       chan error // closed on unsubscribe
+
+	queue   []interface{} // notifications waiting to be written to the client
+	lagged  Error         // set when the subscription is dropped for lagging
+	wake    chan struct{} // signals the send loop about queued notifications
+	drained chan struct{} // closed and replaced whenever the queue was written
 }
 
-// Err returns a channel that is closed when the client send an unsubscribe request.
+// Err returns a channel that is closed when the client send an unsubscribe request,
+// or when the subscription lagged.
 func (s *Subscription) Err() <-chan error {
 	return s.err
 }
@@ -52,6 +78,7 @@ type notifierKey struct{}
 // Server callbacks use the notifier to send notifications.
 type Notifier struct {
 	codec    ServerCodec
+	limit    int // Maximum number of notifications queued by subscription
 	subMu    sync.Mutex
 	active   map[ID]*Subscription
 	inactive map[ID]*Subscription
@@ -59,10 +86,15 @@ type Notifier struct {
 }
 
 // newNotifier creates a new notifier that can be used to send subscription
-// notifications to the client.
-func newNotifier(codec ServerCodec) *Notifier {
+// notifications to the client, queueing up to limit notifications for every
+// subscription before dropping it as lagged.
+func newNotifier(codec ServerCodec, limit int) *Notifier {
+	if limit <= 0 {
+		limit = defaultSubscriptionBuffer
+	}
 	return &Notifier{
 		codec:    codec,
+		limit:    limit,
 		active:   make(map[ID]*Subscription),
 		inactive: make(map[ID]*Subscription),
 		buffer:   make(map[ID][]interface{}),
@@ -80,27 +112,151 @@ func NotifierFromContext(ctx context.Context) (*Notifier, bool) {
 // are dropped until the subscription is marked as active. This is done
 // by the RPC server after the subscription ID is send to the client.
 func (n *Notifier) CreateSubscription() *Subscription {
-	s := &Subscription{ID: NewID(), err: make(chan error)}
+	s := &Subscription{
+		ID:      NewID(),
+		err:     make(chan error),
+		wake:    make(chan struct{}, 1),
+		drained: make(chan struct{}),
+	}
 	n.subMu.Lock()
 	n.inactive[s.ID] = s
 	n.subMu.Unlock()
 	return s
 }
 
-// Notify sends a notification to the client with the given data as payload.
-// If an error occurs the RPC connection is closed and the error is returned.
+// Notify queues a notification to the client with the given data as payload,
+// without waiting for it to be written. If the client doesn't keep up and the
+// queue of the subscription is full, the subscription is dropped and the client
+// notified with a lagged error, returning ErrSubscriptionLagged.
 func (n *Notifier) Notify(id ID, data interface{}) error {
 	n.subMu.Lock()
 	defer n.subMu.Unlock()
 
 	if sub, active := n.active[id]; active {
-		n.send(sub, data)
-	} else {
-		n.buffer[id] = append(n.buffer[id], data)
+		return n.enqueue(sub, data)
+	}
+	sub, inactive := n.inactive[id]
+	if !inactive {
+		return ErrSubscriptionNotFound
+	}
+	if sub.lagged != nil {
+		return ErrSubscriptionLagged
+	}
+	n.buffer[id] = append(n.buffer[id], data)
+	return nil
+}
+
+// Limit returns the number of notifications queued for a subscription before
+// it is dropped as lagged. Callbacks collecting notifications to send later
+// should hold at most as many.
+func (n *Notifier) Limit() int {
+	return n.limit
+}
+
+// Lag drops a subscription whose notifications the callback couldn't keep up
+// with, notifying the client with a lagged error as if its queue overflowed.
+// It returns ErrSubscriptionLagged, or ErrSubscriptionNotFound for unknown
+// subscriptions.
+func (n *Notifier) Lag(id ID) error {
+	n.subMu.Lock()
+	defer n.subMu.Unlock()
+
+	if sub, active := n.active[id]; active {
+		n.lag(sub)
+		return ErrSubscriptionLagged
+	}
+	// Inactive subscriptions notify the client once it learned their ID.
+	if sub, inactive := n.inactive[id]; inactive && sub.lagged == nil {
+		subscriptionLagMeter.Mark(1)
+		close(sub.err)
+		sub.lagged = &subscriptionLaggedError{fmt.Sprintf("subscription lagged with %d pending notifications", len(n.buffer[id]))}
+		delete(n.buffer, id)
+		return ErrSubscriptionLagged
+	}
+	return ErrSubscriptionNotFound
+}
+
+// Backlog returns the number of notifications of a subscription waiting to be
+// written to the client, and a channel closed once they were. Callbacks sending
+// many notifications at once, e.g. replaying past events, use it to pace them
+// instead of lagging. The channel is nil for unknown subscriptions.
+func (n *Notifier) Backlog(id ID) (int, <-chan struct{}) {
+	n.subMu.Lock()
+	defer n.subMu.Unlock()
+
+	if sub, active := n.active[id]; active {
+		return len(sub.queue), sub.drained
+	}
+	if sub, inactive := n.inactive[id]; inactive {
+		return len(n.buffer[id]), sub.drained
+	}
+	return 0, nil
+}
+
+// enqueue appends a notification to the queue of an active subscription, or
+// drops the subscription if the queue is full. The caller must hold subMu.
+func (n *Notifier) enqueue(sub *Subscription, data interface{}) error {
+	if len(sub.queue) >= n.limit {
+		n.lag(sub)
+		return ErrSubscriptionLagged
+	}
+	sub.queue = append(sub.queue, data)
+	select {
+	case sub.wake <- struct{}{}:
+	default:
 	}
 	return nil
 }
 
+// lag drops a subscription whose client didn't keep up, notifying the client.
+// The caller must hold subMu.
+func (n *Notifier) lag(sub *Subscription) {
+	subscriptionLagMeter.Mark(1)
+	delete(n.active, sub.ID)
+	close(sub.err)
+
+	sub.lagged = &subscriptionLaggedError{fmt.Sprintf("subscription lagged with %d pending notifications", len(sub.queue))}
+	sub.queue = nil
+}
+
+// sendLoop writes the notifications queued for a subscription to the client
+// until it is unsubscribed, lagged or the connection is closed. The client of
+// a lagged subscription is sent an error notification after the notifications
+// written so far.
+func (n *Notifier) sendLoop(sub *Subscription) {
+	for {
+		select {
+		case <-sub.wake:
+		case <-sub.err:
+			n.subMu.Lock()
+			lagged := sub.lagged
+			n.subMu.Unlock()
+			if lagged != nil {
+				n.codec.Write(n.codec.CreateErrorNotification(string(sub.ID), sub.namespace, lagged))
+			}
+			return
+		case <-n.codec.Closed():
+			return
+		}
+		n.subMu.Lock()
+		queue, drained := sub.queue, sub.drained
+		sub.queue = nil
+		n.subMu.Unlock()
+
+		for _, data := range queue {
+			if err := n.send(sub, data); err != nil {
+				return
+			}
+		}
+		n.subMu.Lock()
+		if sub.drained == drained {
+			sub.drained = make(chan struct{})
+		}
+		n.subMu.Unlock()
+		close(drained)
+	}
+}
+
 func (n *Notifier) send(sub *Subscription, data interface{}) error {
 	notification := n.codec.CreateNotification(string(sub.ID), sub.namespace, data)
 	err := n.codec.Write(notification)
@@ -129,7 +285,7 @@ func (n *Notifier) unsubscribe(id ID) error {
 }
 
 // activate enables a subscription. Until a subscription is enabled all
-// notifications are dropped. This method is called by the RPC server after
+// notifications are buffered. This method is called by the RPC server after
 // the subscription ID was sent to client. This prevents notifications being
 // send to the client before the subscription ID is send to the client.
 func (n *Notifier) activate(id ID, namespace string) {
@@ -138,11 +294,20 @@ func (n *Notifier) activate(id ID, namespace string) {
 
 	if sub, found := n.inactive[id]; found {
 		sub.namespace = namespace
-		n.active[id] = sub
 		delete(n.inactive, id)
-		// Send buffered notifications.
+		if sub.lagged != nil {
+			// Dropped before activation, the send loop only notifies the error.
+			go n.sendLoop(sub)
+			return
+		}
+		n.active[id] = sub
+		go n.sendLoop(sub)
+
+		// Queue buffered notifications.
 		for _, data := range n.buffer[id] {
-			n.send(sub, data)
+			if n.enqueue(sub, data) != nil {
+				break
+			}
 		}
 		delete(n.buffer, id)
 	}
@@ -203,7 +203,7 @@ func waitForMessages(t *testing.T, in *json.Decoder, successes chan<- jsonSucces
 				notifications <- jsonNotification{
 					Version: msg["jsonrpc"].(string),
 					Method:  msg["method"].(string),
-					Params:  jsonSubscription{params["subscription"].(string), params["result"]},
+					Params:  jsonSubscription{Subscription: params["subscription"].(string), Result: params["result"]},
 				}
 				continue
 			}
@@ -311,3 +311,53 @@ func TestSubscriptionMultipleNamespaces(t *testing.T) {
 		}
 	}
 }
+
+func TestSubscriptionLagged(t *testing.T) {
+	server := NewServer()
+	server.SetLimits(Limits{SubscriptionBuffer: 5})
+	if err := server.RegisterName("eth", new(NotificationTestService)); err != nil {
+		t.Fatalf("unable to register test service %v", err)
+	}
+	clientConn, serverConn := net.Pipe()
+	defer clientConn.Close()
+	go server.ServeCodec(NewJSONCodec(serverConn), OptionMethodInvocation|OptionSubscriptions)
+
+	out := json.NewEncoder(clientConn)
+	in := json.NewDecoder(clientConn)
+
+	n := 100
+	request := map[string]interface{}{
+		"id":      1,
+		"method":  "eth_subscribe",
+		"version": "2.0",
+		"params":  []interface{}{"someSubscription", n, 0},
+	}
+	if err := out.Encode(request); err != nil {
+		t.Fatal(err)
+	}
+	var response jsonSuccessResponse
+	if err := in.Decode(&response); err != nil {
+		t.Fatal(err)
+	}
+	// Not reading lets notifications pile up until the subscription lags.
+	time.Sleep(100 * time.Millisecond)
+
+	for i := 0; ; i++ {
+		var notification jsonNotification
+		if err := in.Decode(&notification); err != nil {
+			t.Fatal(err)
+		}
+		if notification.Params.Error != nil {
+			if notification.Params.Error.Code != -32006 {
+				t.Fatalf("error code mismatch: have %d, want -32006", notification.Params.Error.Code)
+			}
+			if i >= n {
+				t.Fatalf("subscription lagged after all %d notifications", i)
+			}
+			return
+		}
+		if int(notification.Params.Result.(float64)) != i {
+			t.Fatalf("expected %d, got %v", i, notification.Params.Result)
+		}
+	}
+}
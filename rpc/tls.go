@@ -0,0 +1,95 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"sync/atomic"
+)
+
+// TLSConfig represents the certificate files the HTTP and WebSocket endpoints
+// are served with over TLS.
+type TLSConfig struct {
+	CertFile string `toml:",omitempty"` // PEM encoded certificate chain of the endpoints
+	KeyFile  string `toml:",omitempty"` // PEM encoded private key of the certificate
+	CAFile   string `toml:",omitempty"` // PEM encoded CA certificates client certificates must be signed by (empty = no client certificates)
+}
+
+// Enabled reports whether the endpoints are to be served over TLS.
+func (c TLSConfig) Enabled() bool {
+	return c.CertFile != "" || c.KeyFile != ""
+}
+
+// TLSCredentials are the certificates loaded from a TLSConfig. They can be
+// reloaded while serving, e.g. after renewing the certificate, without dropping
+// established connections.
+type TLSCredentials struct {
+	config  TLSConfig
+	current atomic.Value // *tls.Config of the most recently loaded certificates
+}
+
+// NewTLSCredentials loads the certificates of the given configuration.
+func NewTLSCredentials(config TLSConfig) (*TLSCredentials, error) {
+	if config.CertFile == "" || config.KeyFile == "" {
+		return nil, errors.New("TLS requires both a certificate and a key file")
+	}
+	creds := &TLSCredentials{config: config}
+	if err := creds.Reload(); err != nil {
+		return nil, err
+	}
+	return creds, nil
+}
+
+// Reload loads the certificates again, keeping the current ones if that fails.
+func (c *TLSCredentials) Reload() error {
+	cert, err := tls.LoadX509KeyPair(c.config.CertFile, c.config.KeyFile)
+	if err != nil {
+		return err
+	}
+	config := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+	if c.config.CAFile != "" {
+		blob, err := ioutil.ReadFile(c.config.CAFile)
+		if err != nil {
+			return err
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(blob) {
+			return fmt.Errorf("no CA certificates in %s", c.config.CAFile)
+		}
+		config.ClientCAs = pool
+		config.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	c.current.Store(config)
+	return nil
+}
+
+// ServerConfig returns a TLS configuration serving the most recently loaded
+// certificates.
+func (c *TLSCredentials) ServerConfig() *tls.Config {
+	return &tls.Config{
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			return c.current.Load().(*tls.Config), nil
+		},
+	}
+}
@@ -0,0 +1,153 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeTestCertificate writes a self-signed certificate for 127.0.0.1 and its
+// key into the given files, returning the certificate.
+func writeTestCertificate(t *testing.T, certFile, keyFile string, serial int64) *x509.Certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(serial),
+		Subject:               pkix.Name{CommonName: "rpc test"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		IPAddresses:           []net.IP{net.IPv4(127, 0, 0, 1)},
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert
+}
+
+// callTLS calls rpc_modules over HTTPS, trusting the given server certificate
+// and presenting the given client certificate, if any.
+func callTLS(url string, server *x509.Certificate, client *tls.Certificate) error {
+	roots := x509.NewCertPool()
+	roots.AddCert(server)
+	config := &tls.Config{RootCAs: roots}
+	if client != nil {
+		config.Certificates = []tls.Certificate{*client}
+	}
+	c, err := DialHTTPWithClient(url, &http.Client{Transport: &http.Transport{TLSClientConfig: config}})
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	var modules map[string]string
+	return c.Call(&modules, "rpc_modules")
+}
+
+func TestHTTPEndpointTLS(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rpc-tls")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	config := TLSConfig{
+		CertFile: filepath.Join(dir, "server.crt"),
+		KeyFile:  filepath.Join(dir, "server.key"),
+	}
+	cert := writeTestCertificate(t, config.CertFile, config.KeyFile, 1)
+
+	creds, err := NewTLSCredentials(config)
+	if err != nil {
+		t.Fatalf("failed to load credentials: %v", err)
+	}
+	listener, handler, err := StartHTTPEndpoint("127.0.0.1:0", nil, nil, nil, []string{"*"}, DefaultHTTPTimeouts, EndpointOptions{TLSConfig: creds.ServerConfig()})
+	if err != nil {
+		t.Fatalf("failed to start endpoint: %v", err)
+	}
+	defer listener.Close()
+	defer handler.Stop()
+
+	url := "https://" + listener.Addr().String()
+	if err := callTLS(url, cert, nil); err != nil {
+		t.Fatalf("call over TLS failed: %v", err)
+	}
+	// Renewed certificates are served once reloaded
+	renewed := writeTestCertificate(t, config.CertFile, config.KeyFile, 2)
+	if err := callTLS(url, renewed, nil); err == nil {
+		t.Fatalf("renewed certificate served before reload")
+	}
+	if err := creds.Reload(); err != nil {
+		t.Fatalf("failed to reload credentials: %v", err)
+	}
+	if err := callTLS(url, renewed, nil); err != nil {
+		t.Fatalf("call with renewed certificate failed: %v", err)
+	}
+	// Client certificates are required once a CA is configured
+	config.CAFile = filepath.Join(dir, "ca.crt")
+	clientKey := filepath.Join(dir, "client.key")
+	writeTestCertificate(t, config.CAFile, clientKey, 3)
+
+	creds.config = config
+	if err := creds.Reload(); err != nil {
+		t.Fatalf("failed to reload credentials: %v", err)
+	}
+	if err := callTLS(url, renewed, nil); err == nil {
+		t.Fatalf("call without client certificate succeeded")
+	}
+	client, err := tls.LoadX509KeyPair(config.CAFile, clientKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := callTLS(url, renewed, &client); err != nil {
+		t.Fatalf("call with client certificate failed: %v", err)
+	}
+}
@@ -25,6 +25,7 @@ import (
 
 	mapset "github.com/deckarep/golang-set"
 	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/log"
 )
 
 // API describes the set of methods offered over the RPC interface
@@ -43,6 +44,7 @@ type callback struct {
 	hasCtx      bool           // method's first argument is a context (not included in argTypes)
 	errPos      int            // err return idx, of -1 when method cannot return error
 	isSubscribe bool           // indication if the callback is a subscription
+	metrics     *methodMetrics // call metrics of the method
 }
 
 // service represents a registered object
@@ -56,6 +58,7 @@ type service struct {
 // serverRequest is an incoming request
 type serverRequest struct {
 	id            interface{}
+	method        string // requested method, for logging
 	svcname       string
 	callb         *callback
 	args          []reflect.Value
@@ -74,6 +77,9 @@ type Server struct {
 	run      int32
 	codecsMu sync.Mutex
 	codecs   mapset.Set
+
+	limiter   *limiter   // Limits imposed on client requests (nil = unlimited)
+	accessLog log.Logger // Logger of served requests (nil = no access log)
 }
 
 // rpcRequest represents a raw incoming RPC request
@@ -108,6 +114,8 @@ type ServerCodec interface {
 	CreateErrorResponseWithInfo(id interface{}, err Error, info interface{}) interface{}
 	// Create notification response
 	CreateNotification(id, namespace string, event interface{}) interface{}
+	// Create notification ending a subscription with the given error
+	CreateErrorNotification(id, namespace string, err Error) interface{}
 	// Write msg to client.
 	Write(msg interface{}) error
 	// Close underlying data stream
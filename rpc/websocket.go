@@ -69,7 +69,13 @@ func (srv *Server) WebsocketHandler(allowedOrigins []string) http.Handler {
 			decoder := func(v interface{}) error {
 				return websocketJSONCodec.Receive(conn, v)
 			}
-			srv.ServeCodec(NewCodec(conn, encoder, decoder), OptionMethodInvocation|OptionSubscriptions)
+			ctx := context.WithValue(context.Background(), "remote", conn.Request().RemoteAddr)
+			if r := conn.Request(); r.TLS != nil && len(r.TLS.PeerCertificates) > 0 {
+				ctx = context.WithValue(ctx, "identity", r.TLS.PeerCertificates[0].Subject.String())
+			}
+			codec := NewCodec(conn, encoder, decoder)
+			defer codec.Close()
+			srv.serveRequest(ctx, codec, false, OptionMethodInvocation|OptionSubscriptions)
 		},
 	}
 }
@@ -49,6 +49,8 @@ type ExternalAPI interface {
 	SignTransaction(ctx context.Context, args SendTxArgs, methodSelector *string) (*ethapi.SignTransactionResult, error)
 	// Sign - request to sign the given data (plus prefix)
 	Sign(ctx context.Context, addr common.MixedcaseAddress, data hexutil.Bytes) (hexutil.Bytes, error)
+	// SignHash - request to sign the given 32-byte hash as is, without a prefix
+	SignHash(ctx context.Context, addr common.MixedcaseAddress, hash hexutil.Bytes) (hexutil.Bytes, error)
 	// Export - request to export an account
 	Export(ctx context.Context, addr common.Address) (json.RawMessage, error)
 	// Import - request to import an account
@@ -63,6 +65,8 @@ type SignerUI interface {
 	ApproveTx(request *SignTxRequest) (SignTxResponse, error)
 	// ApproveSignData prompt the user for confirmation to request to sign data
 	ApproveSignData(request *SignDataRequest) (SignDataResponse, error)
+	// ApproveSignHash prompt the user for confirmation to request to sign a raw hash
+	ApproveSignHash(request *SignDataRequest) (SignDataResponse, error)
 	// ApproveExport prompt the user for confirmation to export encrypted Account json
 	ApproveExport(request *ExportRequest) (ExportResponse, error)
 	// ApproveImport prompt the user for confirmation to import Account json
@@ -548,6 +552,43 @@ func (api *SignerAPI) Sign(ctx context.Context, addr common.MixedcaseAddress, da
 	return signature, nil
 }
 
+// SignHash calculates an ECDSA signature over the given 32-byte hash, without
+// prefixing it first. Since such a hash may just as well be a transaction or
+// consensus message digest, the request is only ever approved by an explicit
+// ApproveSignHash rule; the interactive UIs always deny it.
+//
+// Note, the produced signature conforms to the secp256k1 curve R, S and V values,
+// where the V value will be 27 or 28 for legacy reasons.
+func (api *SignerAPI) SignHash(ctx context.Context, addr common.MixedcaseAddress, hash hexutil.Bytes) (hexutil.Bytes, error) {
+	if len(hash) != common.HashLength {
+		return nil, fmt.Errorf("hash must be %d bytes, got %d", common.HashLength, len(hash))
+	}
+	// We make the request prior to looking up if we actually have the account, to prevent
+	// account-enumeration via the API
+	req := &SignDataRequest{Address: addr, Rawdata: hash, Hash: hash, Meta: MetadataFromContext(ctx)}
+	res, err := api.UI.ApproveSignHash(req)
+
+	if err != nil {
+		return nil, err
+	}
+	if !res.Approved {
+		return nil, ErrRequestDenied
+	}
+	// Look up the wallet containing the requested signer
+	account := accounts.Account{Address: addr.Address()}
+	wallet, err := api.am.Find(account)
+	if err != nil {
+		return nil, err
+	}
+	signature, err := wallet.SignHashWithPassphrase(account, res.Password, hash)
+	if err != nil {
+		api.UI.ShowError(err.Error())
+		return nil, err
+	}
+	signature[64] += 27 // Transform V from 0/1 to 27/28 according to the yellow paper
+	return signature, nil
+}
+
 // SignHash is a helper function that calculates a hash for the given message that can be
 // safely used to calculate a signature from.
 //
@@ -33,6 +33,7 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/internal/ethapi"
 	"github.com/ethereum/go-ethereum/rlp"
 )
@@ -75,6 +76,13 @@ func (ui *HeadlessUI) ApproveSignData(request *SignDataRequest) (SignDataRespons
 	return SignDataResponse{false, ""}, nil
 }
 
+func (ui *HeadlessUI) ApproveSignHash(request *SignDataRequest) (SignDataResponse, error) {
+	if "Y" == <-ui.controller {
+		return SignDataResponse{true, <-ui.controller}, nil
+	}
+	return SignDataResponse{false, ""}, nil
+}
+
 func (ui *HeadlessUI) ApproveExport(request *ExportRequest) (ExportResponse, error) {
 	return ExportResponse{<-ui.controller == "Y"}, nil
 
@@ -284,6 +292,44 @@ func TestSignData(t *testing.T) {
 		t.Errorf("Expected 65 byte signature (got %d bytes)", len(h))
 	}
 }
+
+func TestSignHash(t *testing.T) {
+	api, control := setup(t)
+	createAccount(control, api, t)
+	control <- "A"
+	list, err := api.List(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := common.NewMixedcaseAddress(list[0])
+
+	if _, err := api.SignHash(context.Background(), a, []byte("EHLO world")); err == nil {
+		t.Errorf("Expected error for a hash of the wrong length")
+	}
+	hash := crypto.Keccak256([]byte("EHLO world"))
+	control <- "No way"
+	if _, err := api.SignHash(context.Background(), a, hash); err != ErrRequestDenied {
+		t.Errorf("Expected ErrRequestDenied! %v", err)
+	}
+	control <- "Y"
+	control <- "a_long_password"
+	sig, err := api.SignHash(context.Background(), a, hash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sig) != 65 {
+		t.Fatalf("Expected 65 byte signature (got %d bytes)", len(sig))
+	}
+	sig[64] -= 27
+	pub, err := crypto.SigToPub(hash, sig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if crypto.PubkeyToAddress(*pub) != list[0] {
+		t.Errorf("Signature recovers to %x, want %x", crypto.PubkeyToAddress(*pub), list[0])
+	}
+}
+
 func mkTestTx(from common.MixedcaseAddress) SendTxArgs {
 	to := common.NewMixedcaseAddress(common.HexToAddress("0x1337"))
 	gas := hexutil.Uint64(21000)
@@ -71,6 +71,14 @@ func (l *AuditLogger) Sign(ctx context.Context, addr common.MixedcaseAddress, da
 	return b, e
 }
 
+func (l *AuditLogger) SignHash(ctx context.Context, addr common.MixedcaseAddress, hash hexutil.Bytes) (hexutil.Bytes, error) {
+	l.log.Info("SignHash", "type", "request", "metadata", MetadataFromContext(ctx).String(),
+		"addr", addr.String(), "hash", common.Bytes2Hex(hash))
+	b, e := l.api.SignHash(ctx, addr, hash)
+	l.log.Info("SignHash", "type", "response", "data", common.Bytes2Hex(b), "error", e)
+	return b, e
+}
+
 func (l *AuditLogger) Export(ctx context.Context, addr common.Address) (json.RawMessage, error) {
 	l.log.Info("Export", "type", "request", "metadata", MetadataFromContext(ctx).String(),
 		"addr", addr.Hex())
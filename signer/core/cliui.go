@@ -176,6 +176,21 @@ func (ui *CommandlineUI) ApproveSignData(request *SignDataRequest) (SignDataResp
 	return SignDataResponse{true, ui.readPassword()}, nil
 }
 
+// ApproveSignHash denies any request to sign a raw hash; those can only be
+// approved by an explicit ruleset.
+func (ui *CommandlineUI) ApproveSignHash(request *SignDataRequest) (SignDataResponse, error) {
+	ui.mu.Lock()
+	defer ui.mu.Unlock()
+
+	fmt.Printf("-------- Sign hash request denied -------\n")
+	fmt.Printf("Account:  %s\n", request.Address.String())
+	fmt.Printf("hash:     %v\n", request.Hash)
+	fmt.Printf("Raw hashes can only be signed through an ApproveSignHash rule\n")
+	fmt.Printf("-------------------------------------------\n")
+	showMetadata(request.Meta)
+	return SignDataResponse{false, ""}, nil
+}
+
 // ApproveExport prompt the user for confirmation to export encrypted Account json
 func (ui *CommandlineUI) ApproveExport(request *ExportRequest) (ExportResponse, error) {
 	ui.mu.Lock()
@@ -61,6 +61,12 @@ func (ui *StdIOUI) ApproveSignData(request *SignDataRequest) (SignDataResponse,
 	return result, err
 }
 
+// ApproveSignHash denies any request to sign a raw hash; those can only be
+// approved by an explicit ruleset.
+func (ui *StdIOUI) ApproveSignHash(request *SignDataRequest) (SignDataResponse, error) {
+	return SignDataResponse{Approved: false}, nil
+}
+
 func (ui *StdIOUI) ApproveExport(request *ExportRequest) (ExportResponse, error) {
 	var result ExportResponse
 	err := ui.dispatch("ApproveExport", request, &result)
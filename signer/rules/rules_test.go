@@ -93,6 +93,10 @@ func (alwaysDenyUI) ApproveSignData(request *core.SignDataRequest) (core.SignDat
 	return core.SignDataResponse{Approved: false, Password: ""}, nil
 }
 
+func (alwaysDenyUI) ApproveSignHash(request *core.SignDataRequest) (core.SignDataResponse, error) {
+	return core.SignDataResponse{Approved: false, Password: ""}, nil
+}
+
 func (alwaysDenyUI) ApproveExport(request *core.ExportRequest) (core.ExportResponse, error) {
 	return core.ExportResponse{Approved: false}, nil
 }
@@ -223,6 +227,11 @@ func (d *dummyUI) ApproveSignData(request *core.SignDataRequest) (core.SignDataR
 	return core.SignDataResponse{}, core.ErrRequestDenied
 }
 
+func (d *dummyUI) ApproveSignHash(request *core.SignDataRequest) (core.SignDataResponse, error) {
+	d.calls = append(d.calls, "ApproveSignHash")
+	return core.SignDataResponse{}, core.ErrRequestDenied
+}
+
 func (d *dummyUI) ApproveExport(request *core.ExportRequest) (core.ExportResponse, error) {
 	d.calls = append(d.calls, "ApproveExport")
 	return core.ExportResponse{}, core.ErrRequestDenied
@@ -277,6 +286,7 @@ func TestForwarding(t *testing.T) {
 		t.Fatalf("Failed to load bootstrap js: %v", err)
 	}
 	r.ApproveSignData(nil)
+	r.ApproveSignHash(nil)
 	r.ApproveTx(nil)
 	r.ApproveImport(nil)
 	r.ApproveNewAccount(nil)
@@ -288,7 +298,7 @@ func TestForwarding(t *testing.T) {
 	//This one is not forwarded
 	r.OnApprovedTx(ethapi.SignTransactionResult{})
 
-	expCalls := 8
+	expCalls := 9
 	if len(ui.calls) != expCalls {
 
 		t.Errorf("Expected %d forwarded calls, got %d: %s", expCalls, len(ui.calls), strings.Join(ui.calls, ","))
@@ -549,6 +559,11 @@ func (d *dontCallMe) ApproveSignData(request *core.SignDataRequest) (core.SignDa
 	return core.SignDataResponse{}, core.ErrRequestDenied
 }
 
+func (d *dontCallMe) ApproveSignHash(request *core.SignDataRequest) (core.SignDataResponse, error) {
+	d.t.Fatalf("Did not expect next-handler to be called")
+	return core.SignDataResponse{}, core.ErrRequestDenied
+}
+
 func (d *dontCallMe) ApproveExport(request *core.ExportRequest) (core.ExportResponse, error) {
 	d.t.Fatalf("Did not expect next-handler to be called")
 	return core.ExportResponse{}, core.ErrRequestDenied
@@ -656,3 +671,37 @@ function ApproveSignData(r){
 		t.Fatalf("Expected approved")
 	}
 }
+
+func TestSignHash(t *testing.T) {
+
+	js := `function ApproveSignHash(r){
+    if( r.address.toLowerCase() == "0x694267f14675d7e1b9494fd8d72fefe1755710fa")
+    {
+        return "Approve"
+    }
+    return "Reject"
+}`
+	r, err := initRuleEngine(js)
+	if err != nil {
+		t.Errorf("Couldn't create evaluator %v", err)
+		return
+	}
+	hash := hexutil.Bytes(common.HexToHash("0x1337").Bytes())
+	addr, _ := mixAddr("0x694267f14675d7e1b9494fd8d72fefe1755710fa")
+	other, _ := mixAddr("0x000000000000000000000000000000000000dead")
+
+	resp, err := r.ApproveSignHash(&core.SignDataRequest{Address: *addr, Hash: hash, Rawdata: hash})
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	if !resp.Approved {
+		t.Fatalf("Expected approved")
+	}
+	resp, err = r.ApproveSignHash(&core.SignDataRequest{Address: *other, Hash: hash, Rawdata: hash})
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	if resp.Approved {
+		t.Fatalf("Expected rejected")
+	}
+}